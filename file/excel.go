@@ -0,0 +1,68 @@
+package file
+
+import (
+	"fmt"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/shakinm/xlsReader/xls"
+)
+
+// ExcelSheetRows_XLSX 读取名为sheet的工作表，sheet为空时读取第一个，返回实际的工作表名。
+// 与ExcelRowsData_XLSX不同，打开失败以及工作表不存在时返回错误
+func ExcelSheetRows_XLSX(file_path string, sheet string) (string, [][]string, error) {
+	if !FileIsExist(file_path) {
+		return "", nil, fileNotExist(file_path)
+	}
+	xlsx, err := excelize.OpenFile(file_path)
+	if err != nil {
+		return "", nil, err
+	}
+	if sheet == "" {
+		sheet = xlsx.GetSheetName(1)
+	} else if xlsx.GetSheetIndex(sheet) == 0 {
+		return "", nil, fmt.Errorf("sheet %s not found in %s", sheet, file_path)
+	}
+	return sheet, xlsx.GetRows(sheet), nil
+}
+
+// ExcelSheetRows_XLS 与ExcelSheetRows_XLSX相同，空行保留为空的切片，行号与Excel中一致
+func ExcelSheetRows_XLS(file_path string, sheet string) (string, [][]string, error) {
+	if !FileIsExist(file_path) {
+		return "", nil, fileNotExist(file_path)
+	}
+	d, err := xls.OpenFile(file_path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var s *xls.Sheet
+	if sheet == "" {
+		if s, err = d.GetSheet(0); err != nil {
+			return "", nil, err
+		}
+	} else {
+		for i := 0; i < d.GetNumberSheets(); i++ {
+			if ws, err := d.GetSheet(i); err == nil && ws.GetName() == sheet {
+				s = ws
+				break
+			}
+		}
+		if s == nil {
+			return "", nil, fmt.Errorf("sheet %s not found in %s", sheet, file_path)
+		}
+	}
+
+	var data [][]string
+	for _, row := range s.GetRows() {
+		rowData := []string{}
+		for _, ele := range row.GetCols() {
+			if ele == nil {
+				rowData = append(rowData, "")
+			} else {
+				rowData = append(rowData, ele.GetString())
+			}
+		}
+		data = append(data, rowData)
+	}
+	return s.GetName(), data, nil
+}
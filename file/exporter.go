@@ -0,0 +1,351 @@
+package file
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"tools/network"
+	"tools/utils"
+	"unicode/utf8"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+)
+
+// 将struct或者map写入xlsx，比如:
+//
+//	ex := NewExporter()
+//	ex.WriteStructs("routes", routes)
+//	ex.NetworkGroup("sources", ng)
+//	err := ex.SaveAs("report.xlsx")
+//
+// struct的表头使用excel tag中的第一个别名，其次是mapstructure的名字、字段名，与Importer一致，
+// 导出的文件可以直接导入。每个工作表的第一行是表头，列宽按照utils.RealLength计算
+
+// DefaultHeaderStyle 表头的默认样式，格式见excelize.NewStyle
+const DefaultHeaderStyle = `{"font":{"bold":true},"fill":{"type":"pattern","color":["#D9E1F2"],"pattern":1},` +
+	`"border":[{"type":"bottom","color":"#000000","style":1}],"alignment":{"horizontal":"center"}}`
+
+const (
+	maxSheetName = 31
+	maxColWidth  = 80
+)
+
+type Exporter struct {
+	// HeaderStyle 为空时表头不设置样式
+	HeaderStyle string
+
+	xlsx        *excelize.File
+	sheets      []string
+	headerStyle int
+}
+
+func NewExporter() *Exporter {
+	return &Exporter{
+		HeaderStyle: DefaultHeaderStyle,
+		xlsx:        excelize.NewFile(),
+	}
+}
+
+// File 返回底层的excelize.File，可以继续设置
+func (ex *Exporter) File() *excelize.File {
+	return ex.xlsx
+}
+
+// Sheets 按照写入的顺序返回工作表
+func (ex *Exporter) Sheets() []string {
+	return ex.sheets
+}
+
+func (ex *Exporter) SaveAs(file_path string) error {
+	if len(ex.sheets) == 0 {
+		return fmt.Errorf("no sheet to export")
+	}
+	return ex.xlsx.SaveAs(file_path)
+}
+
+func (ex *Exporter) Write(w io.Writer) error {
+	if len(ex.sheets) == 0 {
+		return fmt.Errorf("no sheet to export")
+	}
+	return ex.xlsx.Write(w)
+}
+
+// newSheet 第一个工作表复用NewFile创建的Sheet1
+func (ex *Exporter) newSheet(sheet string) error {
+	if sheet == "" || utf8.RuneCountInString(sheet) > maxSheetName || strings.ContainsAny(sheet, `[]:*?/\`) {
+		return fmt.Errorf("invalid sheet name: %q", sheet)
+	}
+	for _, s := range ex.sheets {
+		if strings.EqualFold(s, sheet) {
+			return fmt.Errorf("sheet %s already exists", sheet)
+		}
+	}
+	if len(ex.sheets) == 0 {
+		ex.xlsx.SetSheetName(ex.xlsx.GetSheetName(1), sheet)
+	} else {
+		ex.xlsx.NewSheet(sheet)
+	}
+	ex.sheets = append(ex.sheets, sheet)
+	return nil
+}
+
+func (ex *Exporter) style() (int, error) {
+	if ex.HeaderStyle == "" {
+		return 0, nil
+	}
+	if ex.headerStyle == 0 {
+		id, err := ex.xlsx.NewStyle(ex.HeaderStyle)
+		if err != nil {
+			return 0, err
+		}
+		ex.headerStyle = id
+	}
+	return ex.headerStyle, nil
+}
+
+// WriteRows 新建工作表sheet，第一行为headers，之后每行的值按照cellValue转换
+func (ex *Exporter) WriteRows(sheet string, headers []string, rows [][]interface{}) error {
+	style, err := ex.style()
+	if err != nil {
+		return err
+	}
+	if err := ex.newSheet(sheet); err != nil {
+		return err
+	}
+
+	widths := make([]int, len(headers))
+	set := func(c, r int, value interface{}) {
+		for c >= len(widths) {
+			widths = append(widths, 0)
+		}
+		v := cellValue(reflect.ValueOf(value))
+		for _, line := range strings.Split(fmt.Sprint(v), "\n") {
+			if w := utils.RealLength(line); w > widths[c] {
+				widths[c] = w
+			}
+		}
+		ex.xlsx.SetCellValue(sheet, fmt.Sprintf("%s%d", excelize.ToAlphaString(c), r), v)
+	}
+
+	for c, h := range headers {
+		set(c, 1, h)
+	}
+	for r, row := range rows {
+		for c, value := range row {
+			set(c, r+2, value)
+		}
+	}
+
+	if style != 0 && len(headers) > 0 {
+		ex.xlsx.SetCellStyle(sheet, "A1", excelize.ToAlphaString(len(headers)-1)+"1", style)
+	}
+	for c, w := range widths {
+		// 留出筛选按钮以及边距的宽度
+		w += 2
+		if w > maxColWidth {
+			w = maxColWidth
+		}
+		col := excelize.ToAlphaString(c)
+		ex.xlsx.SetColWidth(sheet, col, col, float64(w))
+	}
+	return nil
+}
+
+// WriteMaps 按照headers的顺序写入每个map中对应的值，缺少的值为空
+func (ex *Exporter) WriteMaps(sheet string, headers []string, rows []map[string]interface{}) error {
+	data := make([][]interface{}, 0, len(rows))
+	for _, m := range rows {
+		row := make([]interface{}, len(headers))
+		for i, h := range headers {
+			row[i] = m[h]
+		}
+		data = append(data, row)
+	}
+	return ex.WriteRows(sheet, headers, data)
+}
+
+// exportField 导出的字段，mapstructure或者excel tag为"-"的字段被忽略
+type exportField struct {
+	index  int
+	header string
+}
+
+func exportFields(st reflect.Type) []exportField {
+	fields := []exportField{}
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		key := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+		alias := strings.TrimSpace(strings.Split(f.Tag.Get("excel"), ",")[0])
+		if key == "-" || alias == "-" {
+			continue
+		}
+		header := alias
+		if header == "" {
+			header = key
+		}
+		if header == "" {
+			header = f.Name
+		}
+		fields = append(fields, exportField{index: i, header: header})
+	}
+	return fields
+}
+
+// WriteStructs rows为struct或者struct指针的切片，nil指针导出为空行
+func (ex *Exporter) WriteStructs(sheet string, rows interface{}) error {
+	sv := reflect.ValueOf(rows)
+	if sv.Kind() != reflect.Slice {
+		return fmt.Errorf("rows must be a slice of struct, got %T", rows)
+	}
+	st := sv.Type().Elem()
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return fmt.Errorf("rows must be a slice of struct, got %T", rows)
+	}
+
+	fields := exportFields(st)
+	headers := make([]string, 0, len(fields))
+	for _, f := range fields {
+		headers = append(headers, f.header)
+	}
+
+	data := make([][]interface{}, 0, sv.Len())
+	for i := 0; i < sv.Len(); i++ {
+		v := reflect.Indirect(sv.Index(i))
+		row := make([]interface{}, len(fields))
+		if v.IsValid() {
+			for j, f := range fields {
+				row[j] = v.Field(f.index).Interface()
+			}
+		}
+		data = append(data, row)
+	}
+	return ex.WriteRows(sheet, headers, data)
+}
+
+// cellValue 将值转换为SetCellValue可以直接写入的类型，nil为空，
+// fmt.Stringer使用String()，包括指针上的String方法，比如network.IPNet，切片使用","连接
+func cellValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	if s, ok := p.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		l := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			l = append(l, fmt.Sprint(cellValue(v.Index(i))))
+		}
+		return strings.Join(l, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// RouteRow AddressTable导出的一行，每个下一跳一行，字段与FlattenEntryList一致
+type RouteRow struct {
+	Net       network.IPNet `mapstructure:"net" json:"net"`
+	Interface string        `mapstructure:"interface" json:"interface"`
+	Ip        string        `mapstructure:"ip" json:"ip"`
+	Connected bool          `mapstructure:"connected" json:"connected"`
+	DefaultGw bool          `mapstructure:"default_gw" json:"default_gw"`
+}
+
+// RouteRows 按照AddressTable.Iterator的顺序展开路由表，默认路由在最后
+func RouteRows(at *network.AddressTable) []RouteRow {
+	rows := []RouteRow{}
+	for it := at.Iterator(); it.HasNext(); {
+		net, nh := it.Next()
+		for hit := nh.Iterator(); hit.HasNext(); {
+			_, h := hit.Next()
+			hop, ok := h.(*network.Hop)
+			if !ok {
+				continue
+			}
+			rows = append(rows, RouteRow{
+				Net:       *net,
+				Interface: hop.Interface,
+				Ip:        hop.Ip,
+				Connected: hop.Connected,
+				DefaultGw: hop.DefaultGw,
+			})
+		}
+	}
+	return rows
+}
+
+// AddressTable 将路由表写入工作表sheet
+func (ex *Exporter) AddressTable(sheet string, at *network.AddressTable) error {
+	return ex.WriteStructs(sheet, RouteRows(at))
+}
+
+// NetworkGroup 将ng中的每个网络写入一行，IPv4在前
+func (ex *Exporter) NetworkGroup(sheet string, ng *network.NetworkGroup) error {
+	headers := []string{"family", "network", "first", "last", "count"}
+	rows := [][]interface{}{}
+	if ng != nil {
+		for _, nl := range []*network.NetworkList{ng.IPv4(), ng.IPv6()} {
+			for _, n := range nl.List() {
+				rows = append(rows, []interface{}{n.Type(), n.String(), n.First(), n.Last(), n.Count()})
+			}
+		}
+	}
+	return ex.WriteRows(sheet, headers, rows)
+}
+
+// ImportErrors 将导入或者校验的错误写入工作表sheet，作为报告
+func (ex *Exporter) ImportErrors(sheet string, err *ImportError) error {
+	headers := []string{"sheet", "cell", "header", "field", "code", "message"}
+	rows := [][]interface{}{}
+	if err != nil {
+		for _, e := range err.Errors {
+			cell := fmt.Sprintf("%d", e.Row)
+			if e.Column != "" {
+				cell = fmt.Sprintf("%s%d", e.Column, e.Row)
+			}
+			rows = append(rows, []interface{}{e.Sheet, cell, e.Header, e.Field, e.Code, e.Msg})
+		}
+	}
+	return ex.WriteRows(sheet, headers, rows)
+}
+
+// WriteExcel_XLSX 将rows写入只有一个工作表的文件，rows为struct的切片
+func WriteExcel_XLSX(file_path string, sheet string, rows interface{}) error {
+	ex := NewExporter()
+	if err := ex.WriteStructs(sheet, rows); err != nil {
+		return err
+	}
+	return ex.SaveAs(file_path)
+}
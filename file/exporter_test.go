@@ -0,0 +1,126 @@
+package file
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"tools/network"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+)
+
+func TestExporter(t *testing.T) {
+	ng, _ := network.NewNetworkGroupFromString("1.1.1.0/24,2001:db8::/32")
+	prefix, _ := network.ParseIPNet("10.0.0.0/8")
+	routes := []*testRoute{
+		{Name: "r1", Prefix: *prefix, Gateway: "1.1.1.1", Vlan: 100, Source: ng, Enabled: true},
+		{Name: "r2", Prefix: *prefix},
+	}
+
+	at := network.NewAddressTable(network.IPv4)
+	for _, r := range [][]string{{"10.0.0.0/8", "eth0", "1.1.1.1"}, {"0.0.0.0/0", "eth1", "2.2.2.2"}} {
+		net, _ := network.ParseIPNet(r[0])
+		nh := network.NewNextHop()
+		nh.AddHop(r[1], r[2], false, false, nil)
+		if err := at.PushRoute(net, nh); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var ie *ImportError
+	errors.As((&Importer{}).ImportRows("Sheet1", testRows, &[]testRoute{}), &ie)
+
+	ex := NewExporter()
+	for _, err := range []error{
+		ex.WriteStructs("路由", routes),
+		ex.WriteMaps("maps", []string{"a", "b"}, []map[string]interface{}{{"a": 1, "b": []string{"x", "y"}}, {"b": prefix}}),
+		ex.NetworkGroup("group", ng),
+		ex.AddressTable("table", at),
+		ex.ImportErrors("report", ie),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, sheet := range []string{"", "路由", "MAPS", "a/b"} {
+		if err := ex.WriteRows(sheet, []string{"a"}, nil); err == nil {
+			t.Errorf("WriteRows(%q), err = nil", sheet)
+		}
+	}
+	if err := ex.WriteStructs("bad", []string{"a"}); err == nil {
+		t.Errorf("WriteStructs([]string), err = nil")
+	}
+
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	if err := ex.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	xlsx, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sheets := []string{}
+	for i := 1; i <= xlsx.SheetCount; i++ {
+		sheets = append(sheets, xlsx.GetSheetName(i))
+	}
+	if !reflect.DeepEqual(sheets, ex.Sheets()) {
+		t.Errorf("sheets = %v, want = %v", sheets, ex.Sheets())
+	}
+
+	cases := []map[string]interface{}{
+		{"sheet": "路由", "rows": [][]string{
+			{"名称", "网段", "下一跳", "vlan", "源地址", "启用"},
+			{"r1", "10.0.0.0/8", "1.1.1.1", "100", ng.String(), "1"},
+			{"r2", "10.0.0.0/8", "", "0", "", "0"},
+		}},
+		{"sheet": "maps", "rows": [][]string{{"a", "b"}, {"1", "x,y"}, {"", "10.0.0.0/8"}}},
+		{"sheet": "group", "rows": [][]string{
+			{"family", "network", "first", "last", "count"},
+			{"IPv4", "1.1.1.0/24", "1.1.1.0", "1.1.1.255", "256"},
+			{"IPv6", "2001:db8::/32", "2001:db8::", "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", "79228162514264337593543950336"},
+		}},
+		{"sheet": "table", "rows": [][]string{
+			{"net", "interface", "ip", "connected", "default_gw"},
+			{"10.0.0.0/8", "eth0", "1.1.1.1", "0", "0"},
+			{"0.0.0.0/0", "eth1", "2.2.2.2", "0", "1"},
+		}},
+		{"sheet": "report", "rows": [][]string{
+			{"sheet", "cell", "header", "field", "code", "message"},
+			{"Sheet1", "A5", "名称", "name", "required", ie.Errors[0].Msg},
+			{"Sheet1", "B5", "目的网段", "prefix", "excel_convert", ie.Errors[1].Msg},
+			{"Sheet1", "C5", "下一跳", "gateway", "ip", ie.Errors[2].Msg},
+			{"Sheet1", "D5", "VLAN", "vlan", "range", ie.Errors[3].Msg},
+			{"Sheet1", "E5", "源地址", "source", "excel_convert", ie.Errors[4].Msg},
+			{"Sheet1", "C6", "下一跳", "gateway", "ip", ie.Errors[5].Msg},
+			{"Sheet1", "D6", "VLAN", "vlan", "excel_convert", ie.Errors[6].Msg},
+		}},
+	}
+	for _, c := range cases {
+		sheet := c["sheet"].(string)
+		if rows := xlsx.GetRows(sheet); !reflect.DeepEqual(rows, c["rows"]) {
+			t.Errorf("sheet %s rows = %q, want = %q", sheet, rows, c["rows"])
+		}
+	}
+
+	// 中文按照2个字符计算宽度
+	if w := xlsx.GetColWidth("路由", "A"); w != 6 {
+		t.Errorf("width(A) = %v, want = 6", w)
+	}
+	if w := xlsx.GetColWidth("路由", "E"); w != 15 {
+		t.Errorf("width(E) = %v, want = 15", w)
+	}
+	if w := xlsx.GetColWidth("group", "D"); w != 40 {
+		t.Errorf("width(D) = %v, want = 40", w)
+	}
+	if xlsx.GetCellStyle("table", "E1") == 0 || xlsx.GetCellStyle("table", "A2") != 0 {
+		t.Errorf("header style is not set")
+	}
+
+	// 导出的路由表可以直接导入
+	var rows []RouteRow
+	if err := (&Importer{Sheet: "table"}).ImportXLSX(path, &rows); err != nil || !reflect.DeepEqual(rows, RouteRows(at)) {
+		t.Errorf("ImportXLSX(table) = %+v, err = %v", rows, err)
+	}
+}
@@ -1,54 +1,57 @@
 package file
 
 import (
-	"fmt"
 	"io"
 	"io/fs"
 	"io/ioutil"
 	"os"
+	"tools/i18n"
 
 	"github.com/360EntSecGroup-Skylar/excelize"
 	"github.com/shakinm/xlsReader/xls"
 )
 
+// 文件相关的错误为*i18n.Error，消息跟随i18n的默认语言，默认为英文，
+// 需要原来的中文消息时调用i18n.SetLanguage(i18n.LANG_ZH)
+
 func CopyFile(copyFileName, toFileName string, perm fs.FileMode) error {
 	if copyFileName == "" || toFileName == "" {
-		return fmt.Errorf("文件路径不能为空")
+		return i18n.NewError("file_path_empty", nil)
 	}
 	if !FileIsExist(copyFileName) {
-		return fmt.Errorf("文件: %s ,不存在", copyFileName)
+		return i18n.NewError("file_not_exist", map[string]interface{}{"path": copyFileName})
 	}
 	fileContent, err := ioutil.ReadFile(copyFileName)
 	if err != nil {
-		return fmt.Errorf("读取文件（%s）失败：%#v", copyFileName, err)
+		return i18n.NewError("file_read", map[string]interface{}{"path": copyFileName, "err": err})
 	}
 	if err = ioutil.WriteFile(toFileName, fileContent, perm); err != nil {
-		return fmt.Errorf("创建文件（%s）失败：%#v", toFileName, err)
+		return i18n.NewError("file_create", map[string]interface{}{"path": toFileName, "err": err})
 	}
 	return nil
 }
 
 func CopyFile2(srcFile, destFile string, perm fs.FileMode) error {
 	if srcFile == "" || destFile == "" {
-		return fmt.Errorf("文件路径不能为空")
+		return i18n.NewError("file_path_empty", nil)
 	}
 	if !FileIsExist(srcFile) {
-		return fmt.Errorf("文件: %s ,不存在", srcFile)
+		return i18n.NewError("file_not_exist", map[string]interface{}{"path": srcFile})
 	}
 	srcF, err := os.Open(srcFile)
 	if err != nil {
-		return fmt.Errorf("文件（%s）读取失败: %v ", srcFile, err)
+		return i18n.NewError("file_open", map[string]interface{}{"path": srcFile, "err": err})
 	}
 	destF, err := os.OpenFile(destFile, os.O_RDWR|os.O_CREATE, os.ModePerm)
 	if err != nil {
-		return fmt.Errorf("文件（%s）读取失败: %v ", destFile, err)
+		return i18n.NewError("file_open", map[string]interface{}{"path": destFile, "err": err})
 	}
 	defer srcF.Close()
 	defer destF.Close()
 
 	_, err = io.Copy(destF, srcF)
 	if err != nil {
-		return fmt.Errorf("文件拷贝失败: %v ", err)
+		return i18n.NewError("file_copy", map[string]interface{}{"err": err})
 	}
 	return nil
 }
@@ -83,7 +86,7 @@ func ExcelRowsData_XLSX(file_path string) ([][]string, error) {
 		dataList := xlsx.GetRows(sheetName)
 		return dataList, nil
 	} else {
-		return [][]string{}, fmt.Errorf("FileIsExist 不存在 file_path:  %s", file_path)
+		return [][]string{}, fileNotExist(file_path)
 	}
 }
 
@@ -109,6 +112,10 @@ func ExcelRowsData_XLS(file_path string) ([][]string, error) {
 		}
 		return data, nil
 	} else {
-		return [][]string{}, fmt.Errorf("FileIsExist 不存在 file_path:  %s", file_path)
+		return [][]string{}, fileNotExist(file_path)
 	}
 }
+
+func fileNotExist(path string) error {
+	return i18n.NewError("file_not_exist", map[string]interface{}{"path": path})
+}
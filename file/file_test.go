@@ -0,0 +1,27 @@
+package file
+
+import (
+	"errors"
+	"testing"
+	"tools/i18n"
+)
+
+func TestFileErrorLanguage(t *testing.T) {
+	err := CopyFile("", "b.txt", 0644)
+	var e *i18n.Error
+	if !errors.As(err, &e) || e.Code != "file_path_empty" {
+		t.Fatalf("CopyFile, err = %v, want code file_path_empty", err)
+	}
+	if got := err.Error(); got != "file path is empty" {
+		t.Errorf("en, got = %s", got)
+	}
+
+	i18n.SetLanguage(i18n.LANG_ZH)
+	defer i18n.SetLanguage(i18n.LANG_EN)
+	if got := err.Error(); got != "文件路径不能为空" {
+		t.Errorf("zh, got = %s", got)
+	}
+	if got := CopyFile2("not_exist.txt", "b.txt", 0644).Error(); got != "文件: not_exist.txt ,不存在" {
+		t.Errorf("zh, got = %s", got)
+	}
+}
@@ -0,0 +1,348 @@
+package file
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"tools/i18n"
+	"tools/network"
+	"tools/validator"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"github.com/mitchellh/mapstructure"
+)
+
+// 将Excel中的每一行导入为struct，比如:
+//
+//	type Route struct {
+//		Prefix network.IPNet `mapstructure:"prefix" excel:"网段,目的网段" validate:"required"`
+//		Vlan   int           `mapstructure:"vlan" excel:"VLAN" validate:"range=1-4094"`
+//	}
+//
+//	var routes []Route
+//	err := (&Importer{}).ImportXLSX("routes.xlsx", &routes)
+//
+// 表头与mapstructure的名字、字段名或者excel tag中的别名匹配（不区分大小写），
+// 单元格按照Converter转换后由mapstructure写入字段，之后使用validate tag以及Chain校验
+
+// Converter 将单元格转换为对应类型的值，返回值可以是该类型或者它的指针
+type Converter func(s string) (interface{}, error)
+
+var (
+	convertersMu sync.RWMutex
+	converters   = map[reflect.Type]Converter{}
+)
+
+// RegisterConverter 注册t的Converter，t为非指针类型，*t的字段也会使用，可以与导入并发调用
+func RegisterConverter(t reflect.Type, f Converter) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[t] = f
+}
+
+func init() {
+	RegisterConverter(reflect.TypeOf(network.IP{}), func(s string) (interface{}, error) {
+		return network.ParseIP(s)
+	})
+	RegisterConverter(reflect.TypeOf(network.IPNet{}), func(s string) (interface{}, error) {
+		return network.ParseIPNet(s)
+	})
+	RegisterConverter(reflect.TypeOf(network.IPRange{}), func(s string) (interface{}, error) {
+		return network.NewIPRange(s)
+	})
+	RegisterConverter(reflect.TypeOf(network.Network{}), func(s string) (interface{}, error) {
+		return network.NewNetworkFromString(s)
+	})
+	RegisterConverter(reflect.TypeOf(network.NetworkGroup{}), func(s string) (interface{}, error) {
+		return network.NewNetworkGroupFromString(s)
+	})
+}
+
+// CellError 导入中的一个错误，Column为空时与具体的单元格无关
+type CellError struct {
+	Sheet  string
+	Row    int    // Excel中的行号，从1开始
+	Column string // 列号，比如"B"
+	Header string
+	Field  string // 校验失败的字段路径
+	Code   string
+	Msg    string
+}
+
+func (e *CellError) Error() string {
+	params := map[string]interface{}{"sheet": e.Sheet, "row": e.Row, "header": e.Header, "msg": e.Msg}
+	if e.Column == "" {
+		return i18n.T("excel_row", params)
+	}
+	params["cell"] = fmt.Sprintf("%s%d", e.Column, e.Row)
+	return i18n.T("excel_cell", params)
+}
+
+// ImportError 所有行的错误，按照行、列的顺序排列
+type ImportError struct {
+	Errors []*CellError
+}
+
+func (e *ImportError) Error() string {
+	m := make([]string, 0, len(e.Errors))
+	for _, ce := range e.Errors {
+		m = append(m, ce.Error())
+	}
+	return strings.Join(m, "\n")
+}
+
+// Importer 零值可以直接使用
+type Importer struct {
+	// Sheet 为空时使用第一个工作表
+	Sheet string
+	// HeaderRow 表头所在的行，从1开始，默认为1，之后的每一行都是数据
+	HeaderRow int
+	// Aliases 额外的表头别名，表头到mapstructure名字的映射
+	Aliases map[string]string
+	// Chain 为nil时只按照validate tag校验
+	Chain *validator.ValidateChain
+	// Converters 优先于RegisterConverter注册的Converter
+	Converters map[reflect.Type]Converter
+}
+
+func (im *Importer) ImportXLSX(file_path string, out interface{}) error {
+	sheet, rows, err := ExcelSheetRows_XLSX(file_path, im.Sheet)
+	if err != nil {
+		return err
+	}
+	return im.ImportRows(sheet, rows, out)
+}
+
+func (im *Importer) ImportXLS(file_path string, out interface{}) error {
+	sheet, rows, err := ExcelSheetRows_XLS(file_path, im.Sheet)
+	if err != nil {
+		return err
+	}
+	return im.ImportRows(sheet, rows, out)
+}
+
+// column 表头对应的字段
+type column struct {
+	index  int
+	header string
+	key    string // mapstructure的名字
+	path   string // 校验结果中的字段路径
+	typ    reflect.Type
+}
+
+// ImportRows 将rows中表头以后的每一行追加到out，out为struct或者struct指针的切片的指针。
+// 空行被跳过，有错误的行不会被追加，所有的错误以*ImportError返回。
+// 某个单元格转换失败时，同一行的其他字段仍然按照validate tag以及Chain校验
+func (im *Importer) ImportRows(sheet string, rows [][]string, out interface{}) error {
+	sv := reflect.ValueOf(out)
+	if sv.Kind() != reflect.Ptr || sv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("out must be a pointer to slice, got %T", out)
+	}
+	sv = sv.Elem()
+	et := sv.Type().Elem()
+	st := et
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return fmt.Errorf("out must be a slice of struct, got %T", out)
+	}
+
+	headerRow := im.HeaderRow
+	if headerRow < 1 {
+		headerRow = 1
+	}
+	if len(rows) < headerRow {
+		return nil
+	}
+	columns := im.columns(st, rows[headerRow-1])
+
+	errs := []*CellError{}
+	for r := headerRow; r < len(rows); r++ {
+		row := rows[r]
+		if isBlank(row) {
+			continue
+		}
+		v, rowErrs := im.importRow(st, columns, row)
+		for _, e := range rowErrs {
+			e.Sheet, e.Row = sheet, r+1
+		}
+		if len(rowErrs) > 0 {
+			errs = append(errs, rowErrs...)
+			continue
+		}
+		if et.Kind() == reflect.Ptr {
+			sv.Set(reflect.Append(sv, v))
+		} else {
+			sv.Set(reflect.Append(sv, v.Elem()))
+		}
+	}
+	if len(errs) > 0 {
+		return &ImportError{Errors: errs}
+	}
+	return nil
+}
+
+func isBlank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(h))
+}
+
+// columns 按照表头匹配字段，匹配不到的列被忽略
+func (im *Importer) columns(st reflect.Type, header []string) []column {
+	type field struct {
+		key  string
+		path string
+		typ  reflect.Type
+	}
+	names := map[string]field{}
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		key := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+		path := strings.Split(f.Tag.Get("json"), ",")[0]
+		if path == "" || path == "-" {
+			path = f.Name
+		}
+		fd := field{key: key, path: path, typ: f.Type}
+		names[normalizeHeader(key)] = fd
+		names[normalizeHeader(f.Name)] = fd
+		for _, alias := range strings.Split(f.Tag.Get("excel"), ",") {
+			if alias = normalizeHeader(alias); alias != "" {
+				names[alias] = fd
+			}
+		}
+	}
+	for alias, key := range im.Aliases {
+		if fd, ok := names[normalizeHeader(key)]; ok {
+			names[normalizeHeader(alias)] = fd
+		}
+	}
+
+	columns := []column{}
+	for i, h := range header {
+		if fd, ok := names[normalizeHeader(h)]; ok {
+			columns = append(columns, column{index: i, header: strings.TrimSpace(h), key: fd.key, path: fd.path, typ: fd.typ})
+		}
+	}
+	return columns
+}
+
+func (im *Importer) converter(t reflect.Type) (Converter, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if f, ok := im.Converters[t]; ok {
+		return f, true
+	}
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	f, ok := converters[t]
+	return f, ok
+}
+
+// importRow 返回struct的指针，空单元格以及转换失败的单元格保持零值。
+// 转换失败时其余字段仍然校验，转换失败的字段本身的校验错误被忽略。
+// 错误按照列的顺序排列，与具体的列无关的错误在最后
+func (im *Importer) importRow(st reflect.Type, columns []column, row []string) (reflect.Value, []*CellError) {
+	v := reflect.New(st)
+	type indexed struct {
+		index int
+		err   *CellError
+	}
+	errs := []indexed{}
+	failed := map[string]bool{}
+	for _, c := range columns {
+		if c.index >= len(row) || strings.TrimSpace(row[c.index]) == "" {
+			continue
+		}
+		cell := strings.TrimSpace(row[c.index])
+
+		var value interface{} = cell
+		if f, ok := im.converter(c.typ); ok {
+			cv, err := f(cell)
+			if err != nil {
+				errs = append(errs, indexed{c.index, im.convertError(c, cell, err)})
+				failed[c.path] = true
+				continue
+			}
+			value = cv
+		}
+		if err := decodeCell(v.Interface(), c.key, value); err != nil {
+			errs = append(errs, indexed{c.index, im.convertError(c, cell, err)})
+			failed[c.path] = true
+		}
+	}
+
+	var result validator.Result
+	if im.Chain != nil {
+		result = im.Chain.ValidateStruct(v.Interface())
+	} else {
+		result = validator.ValidateStruct(v.Interface())
+	}
+	for _, f := range validator.Failures(result) {
+		ce := &CellError{Field: f.Field(), Code: f.Code(), Msg: f.Msg()}
+		index := len(row) + len(columns)
+		skip := false
+		for _, c := range columns {
+			if f.Field() == c.path || strings.HasPrefix(f.Field(), c.path+".") || strings.HasPrefix(f.Field(), c.path+"[") {
+				ce.Column, ce.Header = excelize.ToAlphaString(c.index), c.header
+				index, skip = c.index, failed[c.path]
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+		errs = append(errs, indexed{index, ce})
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].index < errs[j].index })
+	list := make([]*CellError, 0, len(errs))
+	for _, e := range errs {
+		list = append(list, e.err)
+	}
+	return v, list
+}
+
+func (im *Importer) convertError(c column, cell string, err error) *CellError {
+	return im.cellError(c, "excel_convert", i18n.T("excel_convert", map[string]interface{}{"value": cell, "err": err}))
+}
+
+func (im *Importer) cellError(c column, code string, msg string) *CellError {
+	return &CellError{Column: excelize.ToAlphaString(c.index), Header: c.header, Field: c.path, Code: code, Msg: msg}
+}
+
+// decodeCell 使用mapstructure将value写入out中key对应的字段，字符串按照弱类型转换
+func decodeCell(out interface{}, key string, value interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           out,
+	})
+	if err != nil {
+		return err
+	}
+	err = decoder.Decode(map[string]interface{}{key: value})
+	if me, ok := err.(*mapstructure.Error); ok {
+		return fmt.Errorf("%s", strings.Join(me.Errors, "; "))
+	}
+	return err
+}
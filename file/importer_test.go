@@ -0,0 +1,171 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"tools/i18n"
+	"tools/network"
+	"tools/validator"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+)
+
+type testRoute struct {
+	Name    string                `mapstructure:"name" json:"name" excel:"名称" validate:"required"`
+	Prefix  network.IPNet         `mapstructure:"prefix" json:"prefix" excel:"网段,目的网段"`
+	Gateway string                `mapstructure:"gateway" json:"gateway" excel:"下一跳" validate:"omitempty,ip"`
+	Vlan    int                   `mapstructure:"vlan" json:"vlan" validate:"omitempty,range=1-4094"`
+	Source  *network.NetworkGroup `mapstructure:"source" json:"source" excel:"源地址"`
+	Enabled bool                  `mapstructure:"enabled" json:"enabled" excel:"启用"`
+}
+
+var testRows = [][]string{
+	{"名称", "目的网段", " 下一跳 ", "VLAN", "源地址", "启用", "备注"},
+	{"r1", "10.0.0.0/8", "1.1.1.1", "100", "1.1.1.0/24,2001:db8::/32", "1", "ignored"},
+	{},
+	{"r2", "192.168.1.1", "", "", "", "false"},
+	{"", "10.0.0.0/33", "1.1.1", "5000", "x", "1"},
+	{"r4", "10.0.0.0/8", "1.1.1", "abc"},
+}
+
+func TestImportRows(t *testing.T) {
+	var routes []testRoute
+	err := (&Importer{}).ImportRows("Sheet1", testRows, &routes)
+
+	if len(routes) != 2 || routes[0].Name != "r1" || routes[1].Name != "r2" {
+		t.Fatalf("routes = %+v", routes)
+	}
+	r := routes[0]
+	if r.Prefix.String() != "10.0.0.0/8" || r.Gateway != "1.1.1.1" || r.Vlan != 100 || !r.Enabled ||
+		r.Source == nil || len(r.Source.StringList()) != 2 {
+		t.Errorf("routes[0] = %+v", r)
+	}
+	if routes[1].Prefix.String() != "192.168.1.1/32" || routes[1].Source != nil || routes[1].Enabled {
+		t.Errorf("routes[1] = %+v", routes[1])
+	}
+
+	var ie *ImportError
+	if !errors.As(err, &ie) {
+		t.Fatalf("err = %v", err)
+	}
+	got := []string{}
+	for _, e := range ie.Errors {
+		got = append(got, fmt.Sprintf("%s%d:%s", e.Column, e.Row, e.Code))
+	}
+	// 转换失败的单元格不再校验，同一行的其他字段仍然校验，错误按照列的顺序排列
+	want := "A5:required,B5:excel_convert,C5:ip,D5:range,E5:excel_convert,C6:ip,D6:excel_convert"
+	if strings.Join(got, ",") != want {
+		t.Errorf("errors = %v, want = %s\n%v", got, want, err)
+	}
+	if !strings.Contains(ie.Errors[1].Error(), "sheet Sheet1, cell B5 (目的网段): cannot convert '10.0.0.0/33'") {
+		t.Errorf("err = %s", ie.Errors[1])
+	}
+
+	i18n.SetLanguage(i18n.LANG_ZH)
+	defer i18n.SetLanguage(i18n.LANG_EN)
+	routes = nil
+	err = (&Importer{}).ImportRows("Sheet1", testRows, &routes)
+	if !errors.As(err, &ie) || !strings.HasPrefix(ie.Errors[1].Msg, "无法转换'10.0.0.0/33'") {
+		t.Errorf("zh, err = %v", err)
+	}
+}
+
+func TestImportValidate(t *testing.T) {
+	vc := validator.NewValidateChain()
+	vc.Add(validator.When(validator.FieldEquals("vlan", 4094), validator.ValidatorFunc(func(data map[string]interface{}) validator.Result {
+		return validator.NewValidateResult(false, "vlan 4094 is reserved")
+	})))
+	im := &Importer{Chain: vc, HeaderRow: 2, Aliases: map[string]string{"Next Hop": "gateway"}}
+
+	rows := [][]string{
+		{"routes"},
+		{"name", "prefix", "Next Hop", "vlan"},
+		{"", "10.0.0.0/8", "1.1.1", "5000"},
+		{"r2", "10.0.0.0/8", "1.1.1.1", "4094"},
+		{"r3", "10.0.0.0/8", "1.1.1.1", "4093"},
+	}
+	var routes []*testRoute
+	err := im.ImportRows("s", rows, &routes)
+	if len(routes) != 1 || routes[0].Name != "r3" {
+		t.Errorf("routes = %+v", routes)
+	}
+
+	var ie *ImportError
+	if !errors.As(err, &ie) {
+		t.Fatalf("err = %v", err)
+	}
+	got := []string{}
+	for _, e := range ie.Errors {
+		got = append(got, fmt.Sprintf("%s%d:%s:%s", e.Column, e.Row, e.Field, e.Code))
+	}
+	want := "A3:name:required,C3:gateway:ip,D3:vlan:range,4::"
+	if strings.Join(got, ",") != want {
+		t.Errorf("errors = %v, want = %s\n%v", got, want, err)
+	}
+	if !strings.Contains(ie.Errors[3].Error(), "sheet s, row 4: vlan 4094 is reserved") {
+		t.Errorf("err = %s", ie.Errors[3])
+	}
+
+	if err := im.ImportRows("s", rows, routes); err == nil {
+		t.Errorf("ImportRows(non pointer), err = nil")
+	}
+}
+
+func TestImportXLSX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.xlsx")
+	xlsx := excelize.NewFile()
+	for r, row := range testRows[:2] {
+		for c, cell := range row {
+			xlsx.SetCellValue("Sheet1", fmt.Sprintf("%s%d", excelize.ToAlphaString(c), r+1), cell)
+		}
+	}
+	if err := xlsx.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var routes []testRoute
+	if err := (&Importer{}).ImportXLSX(path, &routes); err != nil || len(routes) != 1 || routes[0].Name != "r1" {
+		t.Errorf("routes = %+v, err = %v", routes, err)
+	}
+	if err := (&Importer{Sheet: "missing"}).ImportXLSX(path, &routes); err == nil {
+		t.Errorf("ImportXLSX(missing sheet), err = nil")
+	}
+}
+
+// 与导入并发注册Converter，需要配合-race运行
+func TestRegisterConverterConcurrent(t *testing.T) {
+	type vlan int
+	type row struct {
+		Vlan vlan `mapstructure:"vlan"`
+	}
+	rows := [][]string{{"vlan"}, {"10"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterConverter(reflect.TypeOf(vlan(0)), func(s string) (interface{}, error) {
+				i, err := strconv.Atoi(s)
+				return vlan(i), err
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			var out []row
+			(&Importer{}).ImportRows("s", rows, &out)
+		}()
+	}
+	wg.Wait()
+
+	var out []row
+	if err := (&Importer{}).ImportRows("s", rows, &out); err != nil || len(out) != 1 || out[0].Vlan != 10 {
+		t.Errorf("ImportRows = %+v, err = %v", out, err)
+	}
+}
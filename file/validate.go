@@ -0,0 +1,56 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+	"tools/validator"
+)
+
+// ExcelRecords 第一行作为表头，返回其余每一行中表头到单元格的映射，缺少的单元格为空字符串
+func ExcelRecords(rows [][]string) []map[string]interface{} {
+	if len(rows) == 0 {
+		return []map[string]interface{}{}
+	}
+	header := rows[0]
+	records := make([]map[string]interface{}, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		m := make(map[string]interface{}, len(header))
+		for i, h := range header {
+			h = strings.TrimSpace(h)
+			if h == "" {
+				continue
+			}
+			if i < len(row) {
+				m[h] = row[i]
+			} else {
+				m[h] = ""
+			}
+		}
+		records = append(records, m)
+	}
+	return records
+}
+
+// ValidateRows 使用vc校验rows中除表头以外的每一行，错误的字段路径为row[行号].表头，行号与Excel中一致
+func ValidateRows(rows [][]string, vc *validator.ValidateChain) validator.Result {
+	result := validator.NewValidateResult(true, "")
+	for i, record := range ExcelRecords(rows) {
+		if r := vc.Validate(record); !r.Status() {
+			result.AddError(validator.WithPath(r, fmt.Sprintf("row[%d]", i+2)))
+		}
+	}
+	return result
+}
+
+// ValidateExcel_XLSX 读取file_path，使用rule_path中的规则校验，规则文件的格式见validator.RuleSet
+func ValidateExcel_XLSX(file_path string, rule_path string) (validator.Result, error) {
+	vc, err := validator.LoadValidateChain(rule_path)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := ExcelRowsData_XLSX(file_path)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateRows(rows, vc), nil
+}
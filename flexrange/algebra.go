@@ -0,0 +1,272 @@
+package flexrange
+
+import (
+	"container/heap"
+	"math/big"
+	"tools/utils"
+)
+
+// 集合运算，均基于有序区间列表的线性扫描
+
+func compatible(a DataRangeInf, b DataRangeInf) bool {
+	return a != nil && b != nil && a.Size() == b.Size() && a.Base().Cmp(b.Base()) == 0
+}
+
+// coalesce 合并el中相邻的区间，MERGE_KEEP_FIRST时总是合并，否则只合并数据相同的区间
+func coalesce(el []*Entry, policy MergePolicy) []*Entry {
+	var result []*Entry
+	for _, e := range el {
+		if n := len(result); n > 0 && utils.AddInt(result[n-1].High(), 1).Cmp(e.Low()) == 0 &&
+			(policy == MERGE_KEEP_FIRST || DataEqual(result[n-1].Data(), e.Data())) {
+			result[n-1].high = e.High()
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// newLike 创建与like相同实现、相同Size/Base以及MergePolicy的DataRangeInf，并依次加入有序的el
+func newLike(like DataRangeInf, el []*Entry) DataRangeInf {
+	if t, ok := like.(*TreeDataRange); ok {
+		result := NewTreeDataRange(t.Size(), t.Base())
+		result.policy = t.policy
+		for _, e := range coalesce(el, t.policy) {
+			result.insert(e)
+		}
+		return result
+	}
+
+	result := NewDataRange(like.Size(), like.Base())
+	if d, ok := like.(*DataRange); ok {
+		result.policy = d.policy
+	}
+	for _, e := range coalesce(el, result.policy) {
+		result.L = append(result.L, e)
+	}
+	return result
+}
+
+func newEntry(low *big.Int, high *big.Int, data *ExtendData) *Entry {
+	return &Entry{low: utils.CopyInt(low), high: utils.CopyInt(high), data: data, strFunc: nil}
+}
+
+// difference 返回a中不在b中的部分，数据来自a
+func difference(a []EntryInt, b []EntryInt) []*Entry {
+	var result []*Entry
+	j := 0
+	for _, e := range a {
+		cur := e.Low()
+		for j < len(b) && b[j].High().Cmp(cur) < 0 {
+			j++
+		}
+		done := false
+		for k := j; k < len(b) && b[k].Low().Cmp(e.High()) <= 0; k++ {
+			if b[k].Low().Cmp(cur) > 0 {
+				result = append(result, newEntry(cur, utils.AddInt(b[k].Low(), -1), e.Data()))
+			}
+			if b[k].High().Cmp(e.High()) >= 0 {
+				done = true
+				break
+			}
+			cur = utils.AddInt(b[k].High(), 1)
+		}
+		if !done {
+			result = append(result, newEntry(cur, e.High(), e.Data()))
+		}
+	}
+	return result
+}
+
+// intersect 返回a与b共同的部分，数据来自a
+func intersect(a []EntryInt, b []EntryInt) []*Entry {
+	var result []*Entry
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		low := maxInt(a[i].Low(), b[j].Low())
+		high := minInt(a[i].High(), b[j].High())
+		if low.Cmp(high) <= 0 {
+			result = append(result, newEntry(low, high, a[i].Data()))
+		}
+		if a[i].High().Cmp(b[j].High()) < 0 {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// mergeSorted 合并两个有序并且互不重叠的列表
+func mergeSorted(a []*Entry, b []*Entry) []*Entry {
+	result := make([]*Entry, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i].Low().Cmp(b[j].Low()) < 0 {
+			result = append(result, a[i])
+			i++
+		} else {
+			result = append(result, b[j])
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+	return append(result, b[j:]...)
+}
+
+// Intersect 返回a与b的交集，数据来自a，Size或者Base不同时返回nil
+func Intersect(a DataRangeInf, b DataRangeInf) DataRangeInf {
+	if !compatible(a, b) {
+		return nil
+	}
+	return newLike(a, intersect(a.List(), b.List()))
+}
+
+// Difference 返回a中不在b中的部分，a本身不变
+func Difference(a DataRangeInf, b DataRangeInf) DataRangeInf {
+	if !compatible(a, b) {
+		return nil
+	}
+	return newLike(a, difference(a.List(), b.List()))
+}
+
+// SymmetricDiff 返回只在a中或者只在b中的部分
+func SymmetricDiff(a DataRangeInf, b DataRangeInf) DataRangeInf {
+	if !compatible(a, b) {
+		return nil
+	}
+	return newLike(a, mergeSorted(difference(a.List(), b.List()), difference(b.List(), a.List())))
+}
+
+// Complement 返回[Base, MaxValue]中不在dr中的部分
+func Complement(dr DataRangeInf) DataRangeInf {
+	all := []EntryInt{newEntry(dr.Base(), dr.MaxValue(), nil)}
+	return newLike(dr, difference(all, dr.List()))
+}
+
+// IsDisjoint a与b没有共同的部分
+func IsDisjoint(a DataRangeInf, b DataRangeInf) bool {
+	if !compatible(a, b) {
+		return false
+	}
+	al, bl := a.List(), b.List()
+	for i, j := 0, 0; i < len(al) && j < len(bl); {
+		if maxInt(al[i].Low(), bl[j].Low()).Cmp(minInt(al[i].High(), bl[j].High())) <= 0 {
+			return false
+		}
+		if al[i].High().Cmp(bl[j].High()) < 0 {
+			i++
+		} else {
+			j++
+		}
+	}
+	return true
+}
+
+// IsSubsetOf a中的每一个值都在b中
+func IsSubsetOf(a DataRangeInf, b DataRangeInf) bool {
+	if !compatible(a, b) {
+		return false
+	}
+	return len(difference(a.List(), b.List())) == 0
+}
+
+type unionItem struct {
+	entry EntryInt
+	src   int
+	index int
+}
+
+type unionHeap []unionItem
+
+func (h unionHeap) Len() int { return len(h) }
+func (h unionHeap) Less(i, j int) bool {
+	if c := h[i].entry.Low().Cmp(h[j].entry.Low()); c != 0 {
+		return c < 0
+	}
+	return h[i].src < h[j].src
+}
+func (h unionHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *unionHeap) Push(x interface{}) { *h = append(*h, x.(unionItem)) }
+func (h *unionHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Union 多路归并求并集，重叠或者相邻的区间合并并保留最左侧区间的数据，
+// low相同时保留排在前面的ranges中的数据，Size或者Base不一致时返回nil
+func Union(ranges ...DataRangeInf) DataRangeInf {
+	if len(ranges) == 0 {
+		return nil
+	}
+	h := unionHeap{}
+	for i, dr := range ranges {
+		if !compatible(ranges[0], dr) {
+			return nil
+		}
+		if l := dr.List(); len(l) > 0 {
+			h = append(h, unionItem{l[0], i, 0})
+		}
+	}
+	heap.Init(&h)
+
+	var result []*Entry
+	for h.Len() > 0 {
+		item := heap.Pop(&h).(unionItem)
+		e := item.entry
+		if n := len(result); n > 0 && utils.AddInt(result[n-1].High(), 1).Cmp(e.Low()) >= 0 {
+			if e.High().Cmp(result[n-1].High()) > 0 {
+				result[n-1].high = utils.CopyInt(e.High())
+			}
+		} else {
+			result = append(result, newEntry(e.Low(), e.High(), e.Data()))
+		}
+
+		if l := ranges[item.src].List(); item.index+1 < len(l) {
+			heap.Push(&h, unionItem{l[item.index+1], item.src, item.index + 1})
+		}
+	}
+
+	return newLike(ranges[0], result)
+}
+
+func (d *DataRange) Intersect(other DataRangeInf) DataRangeInf {
+	return Intersect(d, other)
+}
+
+func (d *DataRange) SymmetricDiff(other DataRangeInf) DataRangeInf {
+	return SymmetricDiff(d, other)
+}
+
+func (d *DataRange) Complement() DataRangeInf {
+	return Complement(d)
+}
+
+func (d *DataRange) IsDisjoint(other DataRangeInf) bool {
+	return IsDisjoint(d, other)
+}
+
+func (d *DataRange) IsSubsetOf(other DataRangeInf) bool {
+	return IsSubsetOf(d, other)
+}
+
+func (t *TreeDataRange) Intersect(other DataRangeInf) DataRangeInf {
+	return Intersect(t, other)
+}
+
+func (t *TreeDataRange) SymmetricDiff(other DataRangeInf) DataRangeInf {
+	return SymmetricDiff(t, other)
+}
+
+func (t *TreeDataRange) Complement() DataRangeInf {
+	return Complement(t)
+}
+
+func (t *TreeDataRange) IsDisjoint(other DataRangeInf) bool {
+	return IsDisjoint(t, other)
+}
+
+func (t *TreeDataRange) IsSubsetOf(other DataRangeInf) bool {
+	return IsSubsetOf(t, other)
+}
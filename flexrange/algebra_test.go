@@ -0,0 +1,86 @@
+package flexrange
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"tools/utils"
+)
+
+func randDataRange(r *rand.Rand, backend Backend) (DataRangeInf, [256]bool) {
+	var set [256]bool
+	dr := NewDataRangeWithBackend(8, big.NewInt(0), backend)
+	for i := r.Intn(6); i > 0; i-- {
+		low := r.Intn(240)
+		high := low + r.Intn(16)
+		dr.Push(big.NewInt(int64(low)), big.NewInt(int64(high)), nil)
+		for v := low; v <= high; v++ {
+			set[v] = true
+		}
+	}
+	return dr, set
+}
+
+func checkSet(t *testing.T, name string, dr DataRangeInf, want func(v int) bool) {
+	var got [256]bool
+	for _, e := range dr.List() {
+		for v := e.Low().Int64(); v <= e.High().Int64(); v++ {
+			got[v] = true
+		}
+	}
+	for v := 0; v < 256; v++ {
+		if got[v] != want(v) {
+			t.Fatalf("%s, value %d, got = %v, result = %s", name, v, got[v], dump(dr))
+		}
+	}
+	l := dr.List()
+	for i := 1; i < len(l); i++ {
+		if utils.AddInt(l[i-1].High(), 1).Cmp(l[i].Low()) >= 0 {
+			t.Fatalf("%s, not normalized: %s", name, dump(dr))
+		}
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, backend := range backends {
+		for round := 0; round < 300; round++ {
+			a, as := randDataRange(r, backend)
+			b, bs := randDataRange(r, backend)
+			c, cs := randDataRange(r, backend)
+
+			checkSet(t, "Intersect", a.Intersect(b), func(v int) bool { return as[v] && bs[v] })
+			checkSet(t, "SymmetricDiff", a.SymmetricDiff(b), func(v int) bool { return as[v] != bs[v] })
+			checkSet(t, "Complement", a.Complement(), func(v int) bool { return !as[v] })
+			checkSet(t, "Difference", Difference(a, b), func(v int) bool { return as[v] && !bs[v] })
+			checkSet(t, "Union", Union(a, b, c), func(v int) bool { return as[v] || bs[v] || cs[v] })
+
+			disjoint, subset := true, true
+			for v := 0; v < 256; v++ {
+				if as[v] && bs[v] {
+					disjoint = false
+				}
+				if as[v] && !bs[v] {
+					subset = false
+				}
+			}
+			if a.IsDisjoint(b) != disjoint {
+				t.Fatalf("IsDisjoint %s, %s, want %v", dump(a), dump(b), disjoint)
+			}
+			if a.IsSubsetOf(b) != subset {
+				t.Fatalf("IsSubsetOf %s, %s, want %v", dump(a), dump(b), subset)
+			}
+		}
+	}
+}
+
+func TestSetAlgebraIncompatible(t *testing.T) {
+	a := NewDataRange(8, big.NewInt(0))
+	b := NewDataRange(16, big.NewInt(0))
+	if a.Intersect(b) != nil || Union(a, b) != nil || a.IsSubsetOf(b) {
+		t.Errorf("different size, want nil")
+	}
+	if Union() != nil {
+		t.Errorf("Union(), want nil")
+	}
+}
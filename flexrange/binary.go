@@ -0,0 +1,251 @@
+package flexrange
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"tools/utils"
+)
+
+// 二进制编码，格式见utils.BinaryWriter
+//
+// Entry:     Low(BigInt) High-Low(BigUint) Data
+// EntryList: Size Base(BigInt) 数量 Entry...
+// DataRange: Size Base(BigInt) MergePolicy 数量，每个区间为 Low-上一个High-1(BigUint) High-Low(BigUint) Data，
+//            第一个区间的Low相对于Base
+// Data:      0表示nil；否则为1 Type，之后是PAYLOAD_BINARY或者PAYLOAD_JSON以及对应的内容
+
+const (
+	BINARY_ENTRY byte = iota + 1
+	BINARY_ENTRYLIST
+	BINARY_DATARANGE
+)
+
+const (
+	PAYLOAD_BINARY byte = iota + 1
+	PAYLOAD_JSON
+)
+
+func encodeData(w *utils.BinaryWriter, ext *ExtendData) error {
+	if ext == nil {
+		w.Byte(0)
+		return nil
+	}
+	w.Byte(1)
+	w.String(ext.Type)
+
+	// ExtendData.Data实现了encoding.BinaryMarshaler时使用二进制，否则使用JSON
+	if m, ok := ext.Data.(encoding.BinaryMarshaler); ok {
+		b, err := m.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		w.Byte(PAYLOAD_BINARY)
+		w.Bytes(b)
+		return nil
+	}
+
+	raw := []byte(ext.Property)
+	if ext.Data != nil {
+		b, err := json.Marshal(ext.Data)
+		if err != nil {
+			return err
+		}
+		raw = b
+	}
+	w.Byte(PAYLOAD_JSON)
+	w.Bytes(raw)
+	return nil
+}
+
+func decodeData(r *utils.BinaryReader) (*ExtendData, error) {
+	if r.Byte() == 0 {
+		return nil, r.Err()
+	}
+	typ := r.String()
+	payload := r.Byte()
+	b := r.Bytes()
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+
+	data, ok := MAPPER[typ]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownDataType, typ)
+	}
+	instance := reflect.New(reflect.TypeOf(data).Elem()).Interface().(ExtendDataInt)
+
+	ext := &ExtendData{Type: typ, Data: instance}
+	switch payload {
+	case PAYLOAD_BINARY:
+		u, ok := instance.(encoding.BinaryUnmarshaler)
+		if !ok {
+			return nil, fmt.Errorf("data type '%s' does not implement encoding.BinaryUnmarshaler", typ)
+		}
+		if err := u.UnmarshalBinary(b); err != nil {
+			return nil, err
+		}
+		raw, err := json.Marshal(instance)
+		if err != nil {
+			return nil, err
+		}
+		ext.Property = raw
+	case PAYLOAD_JSON:
+		if err := json.Unmarshal(b, instance); err != nil {
+			return nil, err
+		}
+		ext.Property = b
+	default:
+		return nil, fmt.Errorf("unknown payload: %d", payload)
+	}
+	return ext, nil
+}
+
+func (e Entry) encode(w *utils.BinaryWriter) error {
+	w.BigInt(e.Low())
+	if err := w.BigUint(new(big.Int).Sub(e.High(), e.Low())); err != nil {
+		return err
+	}
+	return encodeData(w, e.Data())
+}
+
+func decodeEntry(r *utils.BinaryReader) (*Entry, error) {
+	low := r.BigInt()
+	high := r.BigUint()
+	high.Add(high, low)
+	data, err := decodeData(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{low: low, high: high, data: data, strFunc: nil}, nil
+}
+
+func (e Entry) MarshalBinary() ([]byte, error) {
+	w := utils.NewBinaryWriter(BINARY_ENTRY)
+	if err := e.encode(w); err != nil {
+		return nil, err
+	}
+	return w.Result(), nil
+}
+
+func (e *Entry) UnmarshalBinary(b []byte) error {
+	r := utils.NewBinaryReader(b, BINARY_ENTRY)
+	d, err := decodeEntry(r)
+	if err != nil {
+		return err
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	*e = *d
+	return nil
+}
+
+func (el EntryList) MarshalBinary() ([]byte, error) {
+	w := utils.NewBinaryWriter(BINARY_ENTRYLIST)
+	w.Uvarint(uint64(el.Size()))
+	w.BigInt(el.Base())
+	w.Uvarint(uint64(len(el.list)))
+	for _, e := range el.list {
+		entry, ok := e.(*Entry)
+		if !ok {
+			return nil, fmt.Errorf("unsupported entry type: %T", e)
+		}
+		if err := entry.encode(w); err != nil {
+			return nil, err
+		}
+	}
+	return w.Result(), nil
+}
+
+func (el *EntryList) UnmarshalBinary(b []byte) error {
+	r := utils.NewBinaryReader(b, BINARY_ENTRYLIST)
+	size := r.Uvarint()
+	base := r.BigInt()
+	n := r.Count()
+	list := make([]EntryInt, 0, n)
+	for i := 0; i < n; i++ {
+		e, err := decodeEntry(r)
+		if err != nil {
+			return err
+		}
+		list = append(list, e)
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	if size > 128 {
+		return fmt.Errorf("size: %d", size)
+	}
+
+	el.list = list
+	el.size = uint32(size)
+	el.base = base
+	return nil
+}
+
+func (dr DataRange) MarshalBinary() ([]byte, error) {
+	w := utils.NewBinaryWriter(BINARY_DATARANGE)
+	w.Uvarint(uint64(dr.Size()))
+	w.BigInt(dr.Base())
+	w.Byte(byte(dr.policy))
+	w.Uvarint(uint64(len(dr.L)))
+
+	next := dr.Base()
+	for _, e := range dr.L {
+		if err := w.BigUint(new(big.Int).Sub(e.Low(), next)); err != nil {
+			return nil, fmt.Errorf("entry is not sorted: %s", e)
+		}
+		if err := w.BigUint(new(big.Int).Sub(e.High(), e.Low())); err != nil {
+			return nil, err
+		}
+		if err := encodeData(w, e.Data()); err != nil {
+			return nil, err
+		}
+		next = utils.AddInt(e.High(), 1)
+	}
+	return w.Result(), nil
+}
+
+func (dr *DataRange) UnmarshalBinary(b []byte) error {
+	r := utils.NewBinaryReader(b, BINARY_DATARANGE)
+	size := r.Uvarint()
+	base := r.BigInt()
+	policy := MergePolicy(r.Byte())
+	n := r.Count()
+
+	list := make([]EntryInt, 0, n)
+	next := base
+	for i := 0; i < n; i++ {
+		low := r.BigUint()
+		low.Add(low, next)
+		high := r.BigUint()
+		high.Add(high, low)
+		data, err := decodeData(r)
+		if err != nil {
+			return err
+		}
+		list = append(list, &Entry{low: low, high: high, data: data, strFunc: nil})
+		next = utils.AddInt(high, 1)
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	if size > 128 {
+		return fmt.Errorf("size: %d", size)
+	}
+	if policy > MERGE_LAST_WRITER {
+		return fmt.Errorf("unknown merge policy: %d", policy)
+	}
+	if n > 0 && list[n-1].High().Cmp(NewDataRange(uint32(size), base).MaxValue()) > 0 {
+		return fmt.Errorf("high out of size: %d", list[n-1].High())
+	}
+
+	dr.L = list
+	dr.size = uint32(size)
+	dr.base = base
+	dr.policy = policy
+	return nil
+}
@@ -0,0 +1,174 @@
+package flexrange
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"tools/utils"
+)
+
+// binData 实现了encoding.BinaryMarshaler，使用PAYLOAD_BINARY
+type binData struct {
+	N uint32
+}
+
+func (d *binData) Copy() utils.CopyAble {
+	return &binData{d.N}
+}
+
+func (d *binData) String() string {
+	return fmt.Sprintf("#%d", d.N)
+}
+
+func (d *binData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct{ N uint32 }{d.N})
+}
+
+func (d *binData) UnmarshalJSON(b []byte) error {
+	v := struct{ N uint32 }{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	d.N = v.N
+	return nil
+}
+
+func (d *binData) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, d.N)
+	return b, nil
+}
+
+func (d *binData) UnmarshalBinary(b []byte) error {
+	if len(b) != 4 {
+		return fmt.Errorf("len(b) = %d", len(b))
+	}
+	d.N = binary.BigEndian.Uint32(b)
+	return nil
+}
+
+// jsonData 没有实现encoding.BinaryMarshaler，使用PAYLOAD_JSON
+type jsonData struct {
+	Name string
+}
+
+func (d *jsonData) Copy() utils.CopyAble {
+	return &jsonData{d.Name}
+}
+
+func (d *jsonData) String() string {
+	return d.Name
+}
+
+func (d *jsonData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct{ Name string }{d.Name})
+}
+
+func (d *jsonData) UnmarshalJSON(b []byte) error {
+	v := struct{ Name string }{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	d.Name = v.Name
+	return nil
+}
+
+func init() {
+	MAPPER["bin"] = &binData{}
+	MAPPER["json"] = &jsonData{}
+}
+
+func TestDataRangeBinary(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"size": 32, "base": 0, "entries": [][]interface{}{}},
+		{"size": 32, "base": 0, "entries": [][]interface{}{{0, 0, nil}, {10, 20, nil}, {4294967295, 4294967295, nil}}},
+		{"size": 16, "base": -100, "entries": [][]interface{}{{-100, -50, &binData{7}}, {1, 2, &jsonData{"a"}}}},
+		{"size": 128, "base": 0, "entries": [][]interface{}{{1, 1 << 40, &jsonData{"b"}}, {1 << 41, 1 << 42, &binData{1 << 30}}}},
+	}
+
+	for _, tc := range testCases {
+		dr := NewDataRange(uint32(tc["size"].(int)), big.NewInt(int64(tc["base"].(int))))
+		for _, e := range tc["entries"].([][]interface{}) {
+			var data *ExtendData
+			switch d := e[2].(type) {
+			case *binData:
+				data = &ExtendData{Type: "bin", Data: d}
+			case *jsonData:
+				data = &ExtendData{Type: "json", Data: d}
+			}
+			if _, err := dr.Push(big.NewInt(int64(e[0].(int))), big.NewInt(int64(e[1].(int))), data); err != nil {
+				t.Fatalf("Push %v, err = %v", e, err)
+			}
+		}
+
+		b, err := dr.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary, err = %v", err)
+		}
+
+		got := &DataRange{}
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Fatalf("UnmarshalBinary, err = %v", err)
+		}
+		if dump(got) != dump(dr) || got.Size() != dr.Size() || got.Base().Cmp(dr.Base()) != 0 {
+			t.Errorf("round trip, got = %s, want = %s", dump(got), dump(dr))
+		}
+
+		js, _ := json.Marshal(dr)
+		if len(b) > len(js) {
+			t.Errorf("len(binary) = %d, len(json) = %d", len(b), len(js))
+		}
+
+		// 截断的数据都应该返回错误
+		for i := 0; i < len(b); i++ {
+			if err := (&DataRange{}).UnmarshalBinary(b[:i]); err == nil {
+				t.Errorf("UnmarshalBinary(b[:%d]), want error", i)
+			}
+		}
+	}
+}
+
+func TestEntryListBinary(t *testing.T) {
+	el := NewEntryList(32, big.NewInt(0))
+	el.Push(big.NewInt(5), big.NewInt(1), &ExtendData{Type: "bin", Data: &binData{3}})
+	el.Push(big.NewInt(1), big.NewInt(8), nil)
+
+	b, err := el.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary, err = %v", err)
+	}
+	got := &EntryList{}
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatalf("UnmarshalBinary, err = %v", err)
+	}
+	if got.String() != el.String() || got.Size() != el.Size() {
+		t.Errorf("round trip, got = %s, want = %s", got, el)
+	}
+
+	e, _ := NewEntryFromString("-3", "7", &ExtendData{Type: "json", Data: &jsonData{"x"}})
+	b, _ = e.MarshalBinary()
+	ge := &Entry{}
+	if err := ge.UnmarshalBinary(b); err != nil || ge.Low().Cmp(e.Low()) != 0 || ge.High().Cmp(e.High()) != 0 || ge.Data().Data.String() != "x" {
+		t.Errorf("Entry round trip, got = %s, want = %s, err = %v", ge, e, err)
+	}
+
+	// 类型不匹配、版本错误、多余数据以及未注册的数据类型
+	if err := got.UnmarshalBinary(b); err == nil {
+		t.Errorf("UnmarshalBinary Entry as EntryList, want error")
+	}
+	bad := append([]byte{}, b...)
+	bad[0] = 0xFF
+	if err := ge.UnmarshalBinary(bad); err == nil {
+		t.Errorf("UnmarshalBinary with wrong version, want error")
+	}
+	if err := ge.UnmarshalBinary(append(b, 0)); err == nil {
+		t.Errorf("UnmarshalBinary with trailing bytes, want error")
+	}
+	e, _ = NewEntryFromString("1", "2", &ExtendData{Type: "unknown", Data: &jsonData{"x"}})
+	b, _ = e.MarshalBinary()
+	if err := ge.UnmarshalBinary(b); err == nil {
+		t.Errorf("UnmarshalBinary with unknown data type, want error")
+	}
+}
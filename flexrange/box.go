@@ -0,0 +1,435 @@
+package flexrange
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"tools/utils"
+)
+
+// Box 多维区间，每一维是一个DataRangeInf，表示各维的笛卡尔积，
+// 比如策略分析中的(源地址, 目的地址, 协议, 源端口, 目的端口, 区域)。
+// 集合运算只关心区间本身，不处理ExtendData
+
+type Box struct {
+	dims []DataRangeInf
+}
+
+func NewBox(dims ...DataRangeInf) (*Box, error) {
+	if len(dims) == 0 {
+		return nil, fmt.Errorf("box need at least one dimension")
+	}
+	b := &Box{}
+	for i, d := range dims {
+		if d == nil {
+			return nil, fmt.Errorf("dimension %d is nil", i)
+		}
+		b.dims = append(b.dims, d.Copy().(DataRangeInf))
+	}
+	return b, nil
+}
+
+// Box 将DataRangePair转换为二维的Box
+func (p DataRangePair) Box() *Box {
+	b, _ := NewBox(p.one, p.two)
+	return b
+}
+
+func (b *Box) Dims() int {
+	return len(b.dims)
+}
+
+func (b *Box) Dim(i int) DataRangeInf {
+	return b.dims[i]
+}
+
+func (b *Box) Empty() bool {
+	for _, d := range b.dims {
+		if d.Empty() {
+			return true
+		}
+	}
+	return false
+}
+
+// Count 各维数量的乘积
+func (b *Box) Count() *big.Int {
+	z := big.NewInt(1)
+	for _, d := range b.dims {
+		z.Mul(z, d.Count())
+	}
+	return z
+}
+
+func (b *Box) Copy() utils.CopyAble {
+	c, _ := NewBox(b.dims...)
+	return c
+}
+
+func (b *Box) String() string {
+	var ls []string
+	for _, d := range b.dims {
+		var el []string
+		for _, e := range d.List() {
+			if e.Low().Cmp(e.High()) == 0 {
+				el = append(el, e.Low().String())
+			} else {
+				el = append(el, fmt.Sprintf("%d-%d", e.Low(), e.High()))
+			}
+		}
+		ls = append(ls, "{"+strings.Join(el, ",")+"}")
+	}
+	return strings.Join(ls, " x ")
+}
+
+func (b *Box) check(other *Box) error {
+	if b.Dims() != other.Dims() {
+		return fmt.Errorf("b.Dims() = %d, other.Dims() = %d", b.Dims(), other.Dims())
+	}
+	for i := range b.dims {
+		if !compatible(b.dims[i], other.dims[i]) {
+			return fmt.Errorf("dimension %d, size or base is different", i)
+		}
+	}
+	return nil
+}
+
+// Intersect 返回b与other的交集，交集为空时返回nil
+func (b *Box) Intersect(other *Box) (*Box, error) {
+	if err := b.check(other); err != nil {
+		return nil, err
+	}
+	result := &Box{}
+	for i := range b.dims {
+		d := Intersect(b.dims[i], other.dims[i])
+		if d.Empty() {
+			return nil, nil
+		}
+		result.dims = append(result.dims, d)
+	}
+	return result, nil
+}
+
+// Contains other是否完整位于b中
+func (b *Box) Contains(other *Box) bool {
+	if b.check(other) != nil {
+		return false
+	}
+	if other.Empty() {
+		return true
+	}
+	for i := range b.dims {
+		if !IsSubsetOf(other.dims[i], b.dims[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Box) Same(other *Box) bool {
+	return b.Contains(other) && other.Contains(b)
+}
+
+// Sub 返回b减去other之后剩余的部分，结果最多Dims()个互不相交的Box：
+// 第i个Box的前i维为两者的交集，第i维为b中不在other中的部分，其余维与b相同
+func (b *Box) Sub(other *Box) ([]*Box, error) {
+	if err := b.check(other); err != nil {
+		return nil, err
+	}
+	if b.Empty() {
+		return nil, nil
+	}
+
+	var result []*Box
+	prefix := []DataRangeInf{}
+	for i := range b.dims {
+		diff := Difference(b.dims[i], other.dims[i])
+		if !diff.Empty() {
+			piece := &Box{}
+			for _, d := range prefix {
+				piece.dims = append(piece.dims, d.Copy().(DataRangeInf))
+			}
+			piece.dims = append(piece.dims, diff)
+			for _, d := range b.dims[i+1:] {
+				piece.dims = append(piece.dims, d.Copy().(DataRangeInf))
+			}
+			result = append(result, piece)
+		}
+
+		inter := Intersect(b.dims[i], other.dims[i])
+		if inter.Empty() {
+			// 没有交集时b剩余的部分已经全部加入了结果
+			break
+		}
+		prefix = append(prefix, inter)
+	}
+	return result, nil
+}
+
+// BoxList 互不相交的Box列表，表示多个Box的并集
+type BoxList struct {
+	boxes []*Box
+}
+
+func NewBoxList() *BoxList {
+	return &BoxList{}
+}
+
+func (bl *BoxList) Boxes() []*Box {
+	return bl.boxes
+}
+
+func (bl *BoxList) Len() int {
+	return len(bl.boxes)
+}
+
+func (bl *BoxList) Empty() bool {
+	return len(bl.boxes) == 0
+}
+
+func (bl *BoxList) Count() *big.Int {
+	z := new(big.Int)
+	for _, b := range bl.boxes {
+		z.Add(z, b.Count())
+	}
+	return z
+}
+
+func (bl *BoxList) Copy() utils.CopyAble {
+	result := NewBoxList()
+	for _, b := range bl.boxes {
+		result.boxes = append(result.boxes, b.Copy().(*Box))
+	}
+	return result
+}
+
+func (bl *BoxList) String() string {
+	var ls []string
+	for _, b := range bl.boxes {
+		ls = append(ls, b.String())
+	}
+	return strings.Join(ls, "\n")
+}
+
+func (bl *BoxList) check(b *Box) error {
+	if len(bl.boxes) == 0 {
+		return nil
+	}
+	return bl.boxes[0].check(b)
+}
+
+// Add 加入b中尚未被覆盖的部分，保持列表中的Box互不相交
+func (bl *BoxList) Add(b *Box) error {
+	if err := bl.check(b); err != nil {
+		return err
+	}
+	if b.Empty() {
+		return nil
+	}
+
+	remain := []*Box{b.Copy().(*Box)}
+	for _, exist := range bl.boxes {
+		var next []*Box
+		for _, r := range remain {
+			pieces, _ := r.Sub(exist)
+			next = append(next, pieces...)
+		}
+		remain = next
+		if len(remain) == 0 {
+			return nil
+		}
+	}
+	bl.boxes = append(bl.boxes, remain...)
+	return nil
+}
+
+func (bl *BoxList) AddList(other *BoxList) error {
+	for _, b := range other.boxes {
+		if err := bl.Add(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sub 从列表中减去b
+func (bl *BoxList) Sub(b *Box) error {
+	if err := bl.check(b); err != nil {
+		return err
+	}
+	var result []*Box
+	for _, exist := range bl.boxes {
+		pieces, _ := exist.Sub(b)
+		result = append(result, pieces...)
+	}
+	bl.boxes = result
+	return nil
+}
+
+// Intersect 返回列表与b的交集
+func (bl *BoxList) Intersect(b *Box) (*BoxList, error) {
+	if err := bl.check(b); err != nil {
+		return nil, err
+	}
+	result := NewBoxList()
+	for _, exist := range bl.boxes {
+		if inter, _ := exist.Intersect(b); inter != nil {
+			result.boxes = append(result.boxes, inter)
+		}
+	}
+	return result, nil
+}
+
+// Contains b是否被列表完整覆盖，b可能跨越多个Box
+func (bl *BoxList) Contains(b *Box) bool {
+	if bl.check(b) != nil {
+		return false
+	}
+	remain := NewBoxList()
+	remain.boxes = []*Box{b}
+	for _, exist := range bl.boxes {
+		remain.Sub(exist)
+		if remain.Empty() {
+			return true
+		}
+	}
+	return b.Empty()
+}
+
+// Same 两个列表覆盖的范围相同
+func (bl *BoxList) Same(other *BoxList) bool {
+	return bl.Canonical().String() == other.Canonical().String()
+}
+
+// Canonical 返回规范化的表示：按照第一维的基本区间扫描，剩余维度递归规范化，
+// 剩余维度相同的基本区间合并为第一维的同一个DataRange。
+// 覆盖范围相同的列表Canonical以后的结果完全相同
+func (bl *BoxList) Canonical() *BoxList {
+	result := NewBoxList()
+	result.boxes = canonical(bl.boxes)
+	return result
+}
+
+// Minimize 在Canonical的基础上，反复合并只有一维不同的两个Box，
+// 由于输入是规范化的，结果同样只由覆盖范围决定
+func (bl *BoxList) Minimize() *BoxList {
+	boxes := canonical(bl.boxes)
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(boxes) && !merged; i++ {
+			for j := i + 1; j < len(boxes) && !merged; j++ {
+				if d := diffDim(boxes[i], boxes[j]); d > -1 {
+					dims := append([]DataRangeInf{}, boxes[i].dims...)
+					dims[d] = Union(boxes[i].dims[d], boxes[j].dims[d])
+					boxes[i] = &Box{dims: dims}
+					boxes = append(boxes[:j], boxes[j+1:]...)
+					merged = true
+				}
+			}
+		}
+	}
+	sort.Slice(boxes, func(i, j int) bool {
+		return boxes[i].String() < boxes[j].String()
+	})
+
+	result := NewBoxList()
+	result.boxes = boxes
+	return result
+}
+
+// diffDim 两个Box只有一维不同时返回该维的下标，否则返回-1
+func diffDim(a *Box, b *Box) int {
+	d := -1
+	for i := range a.dims {
+		if !a.dims[i].Same(b.dims[i]) {
+			if d > -1 {
+				return -1
+			}
+			d = i
+		}
+	}
+	return d
+}
+
+func containsValue(dr DataRangeInf, v *big.Int) bool {
+	_, ok := dr.Lookup(v)
+	return ok
+}
+
+func canonical(boxes []*Box) []*Box {
+	var live []*Box
+	for _, b := range boxes {
+		if !b.Empty() {
+			live = append(live, b)
+		}
+	}
+	if len(live) == 0 {
+		return nil
+	}
+
+	first := live[0].dims[0]
+	if live[0].Dims() == 1 {
+		var drs []DataRangeInf
+		for _, b := range live {
+			drs = append(drs, b.dims[0])
+		}
+		return []*Box{{dims: []DataRangeInf{Union(drs...)}}}
+	}
+
+	// 第一维的所有边界点
+	var points []*big.Int
+	for _, b := range live {
+		for _, e := range b.dims[0].List() {
+			points = append(points, e.Low(), utils.AddInt(e.High(), 1))
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Cmp(points[j]) < 0
+	})
+
+	type group struct {
+		dim0 DataRangeInf
+		tail []*Box
+	}
+	groups := map[string]*group{}
+	var keys []string
+	for i := 0; i+1 < len(points); i++ {
+		if points[i].Cmp(points[i+1]) == 0 {
+			continue
+		}
+		var tails []*Box
+		for _, b := range live {
+			if containsValue(b.dims[0], points[i]) {
+				tails = append(tails, &Box{dims: b.dims[1:]})
+			}
+		}
+		tails = canonical(tails)
+		if len(tails) == 0 {
+			continue
+		}
+
+		list := &BoxList{boxes: tails}
+		key := list.String()
+		g, ok := groups[key]
+		if !ok {
+			g = &group{dim0: NewDataRange(first.Size(), first.Base()), tail: tails}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.dim0.Push(points[i], utils.AddInt(points[i+1], -1), nil)
+	}
+
+	var result []*Box
+	for _, key := range keys {
+		g := groups[key]
+		for _, t := range g.tail {
+			dims := append([]DataRangeInf{g.dim0}, t.dims...)
+			result = append(result, &Box{dims: dims})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
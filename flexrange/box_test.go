@@ -0,0 +1,154 @@
+package flexrange
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// 每一维取值范围为[0, 7]
+const boxDims = 3
+
+type point [boxDims]int
+
+func randBox(r *rand.Rand) *Box {
+	var dims []DataRangeInf
+	for i := 0; i < boxDims; i++ {
+		dr := NewDataRange(3, big.NewInt(0))
+		for n := 1 + r.Intn(2); n > 0; n-- {
+			low := r.Intn(8)
+			high := low + r.Intn(8-low)
+			dr.Push(big.NewInt(int64(low)), big.NewInt(int64(high)), nil)
+		}
+		dims = append(dims, dr)
+	}
+	b, _ := NewBox(dims...)
+	return b
+}
+
+func boxHas(b *Box, p point) bool {
+	for i, v := range p {
+		if !containsValue(b.Dim(i), big.NewInt(int64(v))) {
+			return false
+		}
+	}
+	return true
+}
+
+func listHas(bl *BoxList, p point) (n int) {
+	for _, b := range bl.Boxes() {
+		if boxHas(b, p) {
+			n++
+		}
+	}
+	return
+}
+
+func eachPoint(f func(p point)) {
+	for i := 0; i < 512; i++ {
+		f(point{i & 7, (i >> 3) & 7, i >> 6})
+	}
+}
+
+func checkBoxList(t *testing.T, name string, bl *BoxList, want func(p point) bool) {
+	eachPoint(func(p point) {
+		n := listHas(bl, p)
+		if n > 1 {
+			t.Fatalf("%s, point %v covered %d times:\n%s", name, p, n, bl)
+		}
+		if (n == 1) != want(p) {
+			t.Fatalf("%s, point %v, got = %v:\n%s", name, p, n == 1, bl)
+		}
+	})
+}
+
+func TestBoxList(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		var boxes []*Box
+		union := NewBoxList()
+		for i := 0; i < 4; i++ {
+			b := randBox(r)
+			boxes = append(boxes, b)
+			if err := union.Add(b); err != nil {
+				t.Fatal(err)
+			}
+		}
+		inAny := func(p point) bool {
+			for _, b := range boxes {
+				if boxHas(b, p) {
+					return true
+				}
+			}
+			return false
+		}
+		checkBoxList(t, "Add", union, inAny)
+
+		min := union.Minimize()
+		checkBoxList(t, "Minimize", min, inAny)
+		if !min.Same(union) {
+			t.Fatalf("Minimize:\n%s\n--\n%s", union, min)
+		}
+		checkBoxList(t, "Canonical", union.Canonical(), inAny)
+
+		// 以不同的顺序加入，规范化以后结果相同
+		reverse := NewBoxList()
+		for i := len(boxes) - 1; i >= 0; i-- {
+			reverse.Add(boxes[i])
+		}
+		if reverse.Minimize().String() != min.String() {
+			t.Fatalf("Minimize is not canonical:\n%s\n--\n%s", reverse.Minimize(), min)
+		}
+
+		s := randBox(r)
+		inter, _ := union.Intersect(s)
+		checkBoxList(t, "Intersect", inter, func(p point) bool { return inAny(p) && boxHas(s, p) })
+
+		contains := true
+		eachPoint(func(p point) {
+			if boxHas(s, p) && !inAny(p) {
+				contains = false
+			}
+		})
+		if union.Contains(s) != contains {
+			t.Fatalf("Contains %s, want = %v:\n%s", s, contains, union)
+		}
+
+		sub := union.Copy().(*BoxList)
+		sub.Sub(s)
+		checkBoxList(t, "Sub", sub, func(p point) bool { return inAny(p) && !boxHas(s, p) })
+	}
+}
+
+func TestBoxListMinimize(t *testing.T) {
+	// 四个相邻的小方块合并为一个
+	bl := NewBoxList()
+	for _, c := range [][2]int64{{0, 0}, {0, 4}, {4, 0}, {4, 4}} {
+		x := NewDataRange(3, big.NewInt(0))
+		x.Push(big.NewInt(c[0]), big.NewInt(c[0]+3), nil)
+		y := NewDataRange(3, big.NewInt(0))
+		y.Push(big.NewInt(c[1]), big.NewInt(c[1]+3), nil)
+		b, _ := NewBox(x, y)
+		bl.Add(b)
+	}
+	if got := bl.Minimize().String(); got != "{0-7} x {0-7}" {
+		t.Errorf("got = %s", got)
+	}
+	if bl.Count().Int64() != 64 {
+		t.Errorf("Count = %d", bl.Count())
+	}
+}
+
+func TestBoxCheck(t *testing.T) {
+	a, _ := NewBox(NewDataRange(8, big.NewInt(0)))
+	b, _ := NewBox(NewDataRange(8, big.NewInt(0)), NewDataRange(8, big.NewInt(0)))
+	if _, err := a.Intersect(b); err == nil {
+		t.Errorf("different dims, want error")
+	}
+	if _, err := NewBox(); err == nil {
+		t.Errorf("no dimension, want error")
+	}
+	if a.Contains(b) {
+		t.Errorf("different dims, want false")
+	}
+}
@@ -0,0 +1,257 @@
+package flexrange
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"tools/utils"
+)
+
+// Builder 从按Low升序的输入一次性构建DataRange或者EntryList，
+// 与逐个Push相比不需要查找插入位置，也不会复制数据。
+// 相交或者相邻的区间与DataRange默认的MERGE_KEEP_FIRST一致：合并，并保留先出现的数据
+type Builder struct {
+	size uint32
+	base *big.Int
+	max  *big.Int
+	list []EntryInt
+	last *Entry
+}
+
+func NewBuilder(size uint32, base *big.Int) (*Builder, error) {
+	dr := NewDataRange(size, base)
+	if dr == nil {
+		return nil, fmt.Errorf("size: %d", size)
+	}
+	return &Builder{size: size, base: base, max: dr.MaxValue()}, nil
+}
+
+func (b *Builder) Len() int {
+	return len(b.list)
+}
+
+// Add 加入一个区间，low不能小于之前加入的区间的low。
+// Builder直接持有low、high以及data，调用方之后不应再修改
+func (b *Builder) Add(low *big.Int, high *big.Int, data *ExtendData) error {
+	if err := checkRange(low, high, b.base, b.max); err != nil {
+		return err
+	}
+
+	if b.last != nil {
+		if low.Cmp(b.last.low) < 0 {
+			return fmt.Errorf("input is not sorted, low: %d, previous low: %d", low, b.last.low)
+		}
+		if low.Cmp(utils.AddInt(b.last.high, 1)) <= 0 {
+			if high.Cmp(b.last.high) > 0 {
+				b.last.high = high
+			}
+			return nil
+		}
+	}
+
+	b.last = &Entry{low: low, high: high, data: data, strFunc: nil}
+	b.list = append(b.list, b.last)
+	return nil
+}
+
+func (b *Builder) AddEntry(e EntryInt) error {
+	return b.Add(e.Low(), e.High(), e.Data())
+}
+
+// Consume 读取src直到io.EOF
+func (b *Builder) Consume(src Source) error {
+	for {
+		e, err := src.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := b.AddEntry(e); err != nil {
+			return err
+		}
+	}
+}
+
+// DataRange 返回构建结果，Builder随之被清空
+func (b *Builder) DataRange() *DataRange {
+	dr := NewDataRange(b.size, b.base)
+	dr.L = b.list
+	b.list = nil
+	b.last = nil
+	return dr
+}
+
+// EntryList 返回构建结果，Builder随之被清空
+func (b *Builder) EntryList() *EntryList {
+	el := NewEntryList(b.size, b.base)
+	el.list = b.list
+	b.list = nil
+	b.last = nil
+	return el
+}
+
+// BuildDataRange 从src构建DataRange
+func BuildDataRange(size uint32, base *big.Int, src Source) (*DataRange, error) {
+	b, err := NewBuilder(size, base)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Consume(src); err != nil {
+		return nil, err
+	}
+	return b.DataRange(), nil
+}
+
+// Source 按Low升序依次提供区间，没有更多数据时返回io.EOF
+type Source interface {
+	Next() (EntryInt, error)
+}
+
+type chanSource <-chan EntryInt
+
+func (c chanSource) Next() (EntryInt, error) {
+	e, ok := <-c
+	if !ok {
+		return nil, io.EOF
+	}
+	return e, nil
+}
+
+// NewChanSource ch被关闭时结束
+func NewChanSource(ch <-chan EntryInt) Source {
+	return chanSource(ch)
+}
+
+type sliceSource struct {
+	list []EntryInt
+}
+
+func (s *sliceSource) Next() (EntryInt, error) {
+	if len(s.list) == 0 {
+		return nil, io.EOF
+	}
+	e := s.list[0]
+	s.list = s.list[1:]
+	return e, nil
+}
+
+func NewSliceSource(list []EntryInt) Source {
+	return &sliceSource{list}
+}
+
+// splitRange 将"low-high"或者"low"拆分为两部分，low可以是负数
+func splitRange(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if len(s) > 1 {
+		if i := strings.Index(s[1:], "-"); i > -1 {
+			return s[:i+1], s[i+2:]
+		}
+	}
+	return s, s
+}
+
+// LineSource 每行一个区间，格式为"low-high"或者"low"，空行以及#开头的行被忽略
+type LineSource struct {
+	scanner *bufio.Scanner
+	line    int
+	// Value 解析low、high，默认为ParseInt，比如可以替换为IP地址的解析
+	Value func(s string) (*big.Int, error)
+}
+
+func NewLineSource(r io.Reader) *LineSource {
+	return &LineSource{scanner: bufio.NewScanner(r), Value: ParseInt}
+}
+
+func (s *LineSource) Next() (EntryInt, error) {
+	for s.scanner.Scan() {
+		s.line++
+		text := strings.TrimSpace(s.scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		low, high := splitRange(text)
+		e, err := newSourceEntry(s.Value, low, high, nil)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", s.line, err)
+		}
+		return e, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// CSVSource 从CSV中读取区间，LowCol、HighCol为low、high所在的列，
+// HighCol小于0时每行是单个值；Data不为空时用于从整行生成区间的数据
+type CSVSource struct {
+	reader  *csv.Reader
+	header  bool
+	LowCol  int
+	HighCol int
+	Value   func(s string) (*big.Int, error)
+	Data    func(record []string) (*ExtendData, error)
+}
+
+// NewCSVSource header为true时跳过第一行
+func NewCSVSource(r io.Reader, header bool) *CSVSource {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	return &CSVSource{reader: reader, header: header, LowCol: 0, HighCol: 1, Value: ParseInt}
+}
+
+func (s *CSVSource) Next() (EntryInt, error) {
+	if s.header {
+		s.header = false
+		if _, err := s.reader.Read(); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := s.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := s.reader.FieldPos(0)
+
+	high := s.HighCol
+	if high < 0 {
+		high = s.LowCol
+	}
+	if s.LowCol >= len(record) || high >= len(record) {
+		return nil, fmt.Errorf("line %d: %d fields, need column %d and %d", line, len(record), s.LowCol, high)
+	}
+
+	var data *ExtendData
+	if s.Data != nil {
+		if data, err = s.Data(record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	e, err := newSourceEntry(s.Value, record[s.LowCol], record[high], data)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", line, err)
+	}
+	return e, nil
+}
+
+func newSourceEntry(value func(s string) (*big.Int, error), low string, high string, data *ExtendData) (*Entry, error) {
+	l, err := value(low)
+	if err != nil {
+		return nil, err
+	}
+	h, err := value(high)
+	if err != nil {
+		return nil, err
+	}
+	if l.Cmp(h) > 0 {
+		return nil, fmt.Errorf("%w, low: %d, high: %d", ErrInvertedRange, l, h)
+	}
+	return &Entry{low: l, high: h, data: data, strFunc: nil}, nil
+}
@@ -0,0 +1,103 @@
+package flexrange
+
+import (
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLineSource(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"input": "", "want": "", "err": false},
+		{"input": "1-5\n# comment\n\n6-9\n20", "want": "[1-9][20-20]", "err": false},
+		{"input": "1-10\n3-5\n8-12\n14-15", "want": "[1-12][14-15]", "err": false},
+		{"input": "-8--3\n-2", "want": "[-8--2]", "err": false},
+		{"input": "5-9\n1-3", "want": "", "err": true},
+		{"input": "1-3\nabc", "want": "", "err": true},
+		{"input": "9-3", "want": "", "err": true},
+		{"input": "1-65536", "want": "", "err": true},
+	}
+
+	for _, tc := range testCases {
+		dr, err := BuildDataRange(16, big.NewInt(-100), NewLineSource(strings.NewReader(tc["input"].(string))))
+		if (err != nil) != tc["err"].(bool) {
+			t.Errorf("input = %q, err = %v", tc["input"], err)
+			continue
+		}
+		if err == nil && dump(dr) != tc["want"] {
+			t.Errorf("input = %q, got = %s, want = %s", tc["input"], dump(dr), tc["want"])
+		}
+	}
+}
+
+func TestCSVSource(t *testing.T) {
+	input := "low,high,name\n1,5,a\n6,8,b\n10,10,c\n"
+	src := NewCSVSource(strings.NewReader(input), true)
+	src.Data = func(record []string) (*ExtendData, error) {
+		return tag(record[2]), nil
+	}
+	b, _ := NewBuilder(32, big.NewInt(0))
+	if err := b.Consume(src); err != nil {
+		t.Fatalf("Consume, err = %v", err)
+	}
+	dr, _ := b.EntryList().DataRange(MERGE_KEEP_FIRST)
+	if got := dump(dr); got != "[1-8a][10-10c]" {
+		t.Errorf("got = %s", got)
+	}
+
+	src = NewCSVSource(strings.NewReader("1,2\n3\n"), false)
+	if _, err := BuildDataRange(32, big.NewInt(0), src); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("missing column, err = %v", err)
+	}
+
+	src = NewCSVSource(strings.NewReader("x;7\ny;9\n"), false)
+	src.LowCol, src.HighCol = 1, -1
+	src.reader.Comma = ';'
+	if dr, err := BuildDataRange(32, big.NewInt(0), src); err != nil || dump(dr) != "[7-7][9-9]" {
+		t.Errorf("single column, got = %s, err = %v", dump(dr), err)
+	}
+}
+
+// 与逐个Push的结果对比
+func TestBuilderRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		var list []EntryInt
+		for i := 0; i < r.Intn(30); i++ {
+			low := int64(r.Intn(1000))
+			list = append(list, &Entry{low: big.NewInt(low), high: big.NewInt(low + int64(r.Intn(20)))})
+		}
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Low().Cmp(list[j].Low()) < 0
+		})
+
+		want := NewDataRange(32, big.NewInt(0))
+		for _, e := range list {
+			want.Push(e.Low(), e.High(), nil)
+		}
+
+		ch := make(chan EntryInt)
+		go func() {
+			for _, e := range list {
+				ch <- e
+			}
+			close(ch)
+		}()
+		got, err := BuildDataRange(32, big.NewInt(0), NewChanSource(ch))
+		if err != nil || dump(got) != dump(want) {
+			t.Fatalf("round %d, got = %s, want = %s, err = %v", round, dump(got), dump(want), err)
+		}
+	}
+}
+
+func BenchmarkBuilder(b *testing.B) {
+	var list []EntryInt
+	for i := 0; i < 1000; i++ {
+		list = append(list, &Entry{low: big.NewInt(int64(i * 10)), high: big.NewInt(int64(i*10 + 5))})
+	}
+	for i := 0; i < b.N; i++ {
+		BuildDataRange(32, big.NewInt(0), NewSliceSource(list))
+	}
+}
@@ -2,7 +2,6 @@ package flexrange
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -68,7 +67,7 @@ func (ext *ExtendData) UnmarshalJSON(b []byte) error {
 	//fmt.Printf("d.Property = %+v\n", d.Property)
 
 	if _, ok := MAPPER[d.Type]; !ok {
-		return fmt.Errorf("unknown data type: '%s'", d.Type)
+		return fmt.Errorf("%w: '%s'", ErrUnknownDataType, d.Type)
 	}
 
 	data := MAPPER[d.Type]
@@ -81,7 +80,9 @@ func (ext *ExtendData) UnmarshalJSON(b []byte) error {
 	dataType := reflect.TypeOf(data)
 
 	newInstancePtr := reflect.New(dataType.Elem()).Interface().(ExtendDataInt)
-	_ = json.Unmarshal(ext.Property, newInstancePtr)
+	if err := json.Unmarshal(ext.Property, newInstancePtr); err != nil {
+		return fmt.Errorf("%w, data type '%s': %s", ErrParse, d.Type, err)
+	}
 
 	ext.Data = newInstancePtr
 	return nil
@@ -114,7 +115,7 @@ type EntryInt interface {
 	//MarshalJSON() (b []byte, err error)
 	//UnmarshalJSON(b []byte) error
 	WithStrFunc(s func() string)
-	WarpperWithDataRange(size uint32, base *big.Int) DataRangeInf
+	WarpperWithDataRange(size uint32, base *big.Int) (DataRangeInf, error)
 }
 
 type EntryList struct {
@@ -331,10 +332,10 @@ func (el *EntryList) MatchResult(other DataRangeInf) (ok bool, err error, match
 }
 
 func (el *EntryList) PushString(low string, high string, addition interface{}) (bool, error) {
-	l := new(big.Int)
-	fmt.Sscan(low, l)
-	h := new(big.Int)
-	fmt.Sscan(high, h)
+	l, h, err := parseRange(low, high)
+	if err != nil {
+		return false, err
+	}
 
 	return el.Push(l, h, addition)
 }
@@ -345,14 +346,8 @@ func (el *EntryList) PushEntry(e EntryInt) (bool, error) {
 }
 
 func (el *EntryList) Push(low *big.Int, high *big.Int, addition interface{}) (bool, error) {
-	if low.Cmp(high) > 0 {
-		return false, fmt.Errorf("low: %d, high: %d", low, high)
-	}
-	if low.Cmp(el.Base()) < 0 {
-		return false, fmt.Errorf("low: %d, high: %d", low, high)
-	}
-	if high.Cmp(el.MaxValue()) > 0 {
-		return false, fmt.Errorf("low: %d, high: %d", low, high)
+	if err := checkRange(low, high, el.Base(), el.MaxValue()); err != nil {
+		return false, err
 	}
 	var tmp *Entry
 	if addition == nil {
@@ -375,7 +370,7 @@ type Entry struct {
 
 func NewEntry(low *big.Int, high *big.Int, addition *ExtendData) (*Entry, error) {
 	if high.Cmp(low) < 0 {
-		return nil, errors.New(fmt.Sprintf("high: %d, low: %d, addition: %T", high, low, addition))
+		return nil, fmt.Errorf("%w, high: %d, low: %d, addition: %T", ErrInvertedRange, high, low, addition)
 	}
 
 	//if addition == nil {
@@ -405,10 +400,10 @@ func NewEntry(low *big.Int, high *big.Int, addition *ExtendData) (*Entry, error)
 }
 
 func NewEntryFromString(low string, high string, addition *ExtendData) (*Entry, error) {
-	l := new(big.Int)
-	fmt.Sscan(low, l)
-	h := new(big.Int)
-	fmt.Sscan(high, h)
+	l, h, err := parseRange(low, high)
+	if err != nil {
+		return nil, err
+	}
 	return NewEntry(l, h, addition)
 }
 
@@ -480,16 +475,15 @@ func (e Entry) CopyData() *ExtendData {
 	}
 }
 
-func (e *Entry) WarpperWithDataRange(size uint32, base *big.Int) DataRangeInf {
+func (e *Entry) WarpperWithDataRange(size uint32, base *big.Int) (DataRangeInf, error) {
 	r := NewDataRange(size, base)
-	ok, err := r.PushEntry(e.Copy().(*Entry))
-	if err != nil {
-		panic(err)
+	if r == nil {
+		return nil, fmt.Errorf("%w, size: %d", ErrOutOfBounds, size)
 	}
-	if !ok {
-		panic("unknow error")
+	if _, err := r.PushEntry(e.Copy().(*Entry)); err != nil {
+		return nil, err
 	}
-	return r
+	return r, nil
 }
 
 func (e *Entry) SetData(d *ExtendData) {
@@ -802,7 +796,64 @@ func (e Entry) SubRemain(other EntryInt) (ms matchstate, left *[]EntryInt, sub *
 	return
 }
 
+func compare128(el, eh, ol, oh utils.Uint128) position {
+	if ol1, borrow := ol.SubUint64(1); !borrow && eh.Equal(ol1) {
+		return LeftConnectRight
+	}
+	if el1, borrow := el.SubUint64(1); !borrow && oh.Equal(el1) {
+		return RightConnectLeft
+	}
+	if el.Cmp(oh) > 0 {
+		return Right
+	}
+	if eh.Cmp(ol) < 0 {
+		return Left
+	}
+	if el.Equal(ol) && eh.Equal(oh) {
+		return Equal
+	}
+	if el.Cmp(ol) <= 0 && eh.Cmp(oh) >= 0 {
+		return LeftContainRight
+	}
+	if el.Cmp(ol) >= 0 && eh.Cmp(oh) <= 0 {
+		return RightContainLeft
+	}
+	if eh.Cmp(oh) < 0 {
+		return RightOverlapLeft
+	}
+	if oh.Cmp(eh) < 0 {
+		return LeftOverlapRight
+	}
+	return CompareError
+}
+
+// Bounds128 返回Entry边界的定长整数表示，边界为负数或者超过128位时ok为false
+func (e Entry) Bounds128() (low utils.Uint128, high utils.Uint128, ok bool) {
+	if low, ok = utils.Uint128FromBig(e.Low()); !ok {
+		return
+	}
+	high, ok = utils.Uint128FromBig(e.High())
+	return
+}
+
+func NewEntryFromUint128(low, high utils.Uint128, addition *ExtendData) (*Entry, error) {
+	return NewEntry(low.Big(), high.Big(), addition)
+}
+
+// Compare 边界都能用128位无符号整数表示时使用定长整数进行比较，
+// 否则(比如base为负数)回退到big.Int
 func (e Entry) Compare(other EntryInt) position {
+	if el, eh, ok := e.Bounds128(); ok {
+		ol, ok1 := utils.Uint128FromBig(other.Low())
+		oh, ok2 := utils.Uint128FromBig(other.High())
+		if ok1 && ok2 {
+			return compare128(el, eh, ol, oh)
+		}
+	}
+	return e.compareBig(other)
+}
+
+func (e Entry) compareBig(other EntryInt) position {
 	//if e.High == other.Low-1 {
 	olow := new(big.Int)
 	olow.Set(other.Low())
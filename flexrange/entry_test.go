@@ -0,0 +1,52 @@
+package flexrange
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func randEntry(r *rand.Rand) *Entry {
+	low := big.NewInt(r.Int63n(64))
+	high := new(big.Int).Add(low, big.NewInt(r.Int63n(8)))
+	e, _ := NewEntry(low, high, nil)
+	return e
+}
+
+func TestCompareUint128Backend(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		a := randEntry(r)
+		b := randEntry(r)
+
+		got := a.Compare(b)
+		want := a.compareBig(b)
+		if got != want {
+			t.Errorf("%s Compare %s, got = %s, want = %s", a, b, got, want)
+		}
+	}
+}
+
+func benchmarkCompare(b *testing.B, compare func(e *Entry, other *Entry) position) {
+	r := rand.New(rand.NewSource(1))
+	entries := make([]*Entry, 200)
+	for i := range entries {
+		low := new(big.Int).Lsh(big.NewInt(r.Int63()), 64)
+		entries[i], _ = NewEntry(low, new(big.Int).Add(low, big.NewInt(255)), nil)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 1; j < len(entries); j++ {
+			compare(entries[j-1], entries[j])
+		}
+	}
+}
+
+func BenchmarkCompareUint128(b *testing.B) {
+	benchmarkCompare(b, func(e *Entry, other *Entry) position { return e.Compare(other) })
+}
+
+func BenchmarkCompareBigInt(b *testing.B) {
+	benchmarkCompare(b, func(e *Entry, other *Entry) position { return e.compareBig(other) })
+}
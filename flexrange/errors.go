@@ -0,0 +1,51 @@
+package flexrange
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// flexrange中返回的错误都包装了以下错误之一，调用方可以通过errors.Is判断
+var (
+	// ErrOutOfBounds 区间超出了Base到MaxValue的范围
+	ErrOutOfBounds = errors.New("out of bounds")
+	// ErrInvertedRange low大于high
+	ErrInvertedRange = errors.New("inverted range")
+	// ErrUnknownDataType ExtendData.Type没有在MAPPER中注册
+	ErrUnknownDataType = errors.New("unknown data type")
+	// ErrParse 字符串无法解析为整数，或者数据无法反序列化
+	ErrParse = errors.New("parse error")
+)
+
+func checkRange(low *big.Int, high *big.Int, base *big.Int, max *big.Int) error {
+	if low.Cmp(high) > 0 {
+		return fmt.Errorf("%w, low: %d, high: %d", ErrInvertedRange, low, high)
+	}
+	if low.Cmp(base) < 0 || high.Cmp(max) > 0 {
+		return fmt.Errorf("%w, low: %d, high: %d, base: %d, max: %d", ErrOutOfBounds, low, high, base, max)
+	}
+	return nil
+}
+
+// ParseInt 解析十进制整数
+func ParseInt(s string) (*big.Int, error) {
+	i, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+	if !ok {
+		return nil, fmt.Errorf("%w, invalid integer: '%s'", ErrParse, s)
+	}
+	return i, nil
+}
+
+func parseRange(low string, high string) (*big.Int, *big.Int, error) {
+	l, err := ParseInt(low)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := ParseInt(high)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, h, nil
+}
@@ -0,0 +1,56 @@
+package flexrange
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	for _, backend := range backends {
+		dr := NewDataRangeWithBackend(8, big.NewInt(0), backend)
+		testCases := []map[string]interface{}{
+			{"low": "abc", "high": "5", "err": ErrParse},
+			{"low": "1", "high": "", "err": ErrParse},
+			{"low": "5", "high": "1", "err": ErrInvertedRange},
+			{"low": "-1", "high": "1", "err": ErrOutOfBounds},
+			{"low": "1", "high": "256", "err": ErrOutOfBounds},
+			{"low": " 1", "high": "255", "err": nil},
+		}
+
+		for _, tc := range testCases {
+			want, _ := tc["err"].(error)
+			if _, err := dr.PushString(tc["low"].(string), tc["high"].(string), nil); !errors.Is(err, want) {
+				t.Errorf("%s PushString(%s, %s), err = %v, want = %v", backend, tc["low"], tc["high"], err, want)
+			}
+			if _, err := dr.RemoveString(tc["low"].(string), tc["high"].(string)); !errors.Is(err, want) {
+				t.Errorf("%s RemoveString(%s, %s), err = %v, want = %v", backend, tc["low"], tc["high"], err, want)
+			}
+		}
+	}
+
+	el := NewEntryList(8, big.NewInt(0))
+	if _, err := el.PushString("1", "x", nil); !errors.Is(err, ErrParse) {
+		t.Errorf("EntryList.PushString, err = %v", err)
+	}
+	if _, err := NewEntryFromString("9", "1", nil); !errors.Is(err, ErrInvertedRange) {
+		t.Errorf("NewEntryFromString, err = %v", err)
+	}
+
+	e, _ := NewEntryFromString("1", "300", nil)
+	if _, err := e.WarpperWithDataRange(8, big.NewInt(0)); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("WarpperWithDataRange, err = %v", err)
+	}
+	if dr, err := e.WarpperWithDataRange(16, big.NewInt(0)); err != nil || len(dr.List()) != 1 {
+		t.Errorf("WarpperWithDataRange, dr = %v, err = %v", dr, err)
+	}
+
+	var ext ExtendData
+	if err := json.Unmarshal([]byte(`{"Type":"not-exist","Property":{}}`), &ext); !errors.Is(err, ErrUnknownDataType) {
+		t.Errorf("UnmarshalJSON unknown type, err = %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"Type":"bin","Property":{"N":"x"}}`), &ext); !errors.Is(err, ErrParse) {
+		t.Errorf("UnmarshalJSON bad property, err = %v", err)
+	}
+}
@@ -60,6 +60,13 @@ type DataRangeInf interface {
 	Remove(low *big.Int, high *big.Int) (DataRangeInf, error)
 	Add(other DataRangeInf) DataRangeInf
 	Sub(other DataRangeInf) (DataRangeInf, error)
+	Intersect(other DataRangeInf) DataRangeInf
+	SymmetricDiff(other DataRangeInf) DataRangeInf
+	Complement() DataRangeInf
+	IsDisjoint(other DataRangeInf) bool
+	IsSubsetOf(other DataRangeInf) bool
+	Lookup(v *big.Int) (EntryInt, bool)
+	Overlapping(low *big.Int, high *big.Int) []EntryInt
 	ElementStrFunc(func() string)
 	WithStrFunc(func() string)
 	//Sub(other DataRangeInf) (DataRangeInf, DataRangeInf, error)
@@ -72,6 +79,7 @@ type DataRange struct {
 	//list []*Entry
 	size    uint32
 	base    *big.Int
+	policy  MergePolicy
 	StrFunc func() string
 }
 
@@ -188,8 +196,15 @@ func (d *DataRange) Iterator() *Iterator {
 	}
 }
 
+// listRange Iterator所依赖的有序列表操作，DataRange与TreeDataRange都实现了该接口
+type listRange interface {
+	List() []EntryInt
+	Delete(index int) (bool, error)
+	Insert(index int, s EntryInt) (bool, error)
+}
+
 type Iterator struct {
-	dr    *DataRange
+	dr    listRange
 	index int
 }
 
@@ -360,6 +375,7 @@ func (d *DataRange) WithStrFunc(strFunc func() string) {
 
 func (d *DataRange) Copy() utils.CopyAble {
 	result := NewDataRange(d.Size(), d.Base())
+	result.policy = d.policy
 
 	for _, e := range d.List() {
 		result.L = append(result.List(), e.(EntryInt).Copy().(EntryInt))
@@ -403,10 +419,10 @@ func (r *DataRange) Print() {
 
 //func (d *DataRange) PushString(low string, high string, addition interface{}) (bool, error) {
 func (d *DataRange) PushString(low string, high string, addition *ExtendData) (bool, error) {
-	l := new(big.Int)
-	fmt.Sscan(low, l)
-	h := new(big.Int)
-	fmt.Sscan(high, h)
+	l, h, err := parseRange(low, high)
+	if err != nil {
+		return false, err
+	}
 	return d.Push(l, h, addition)
 }
 
@@ -418,14 +434,11 @@ func (d *DataRange) PushEntry(e EntryInt) (bool, error) {
 
 func (d *DataRange) Push(low *big.Int, high *big.Int, addition *ExtendData) (bool, error) {
 	//func (d *DataRange) Push(low *big.Int, high *big.Int, addition interface{}) (bool, error) {
-	if low.Cmp(high) > 0 {
-		return false, fmt.Errorf("low: %d, high: %d", low, high)
-	}
-	if low.Cmp(d.Base()) < 0 {
-		return false, fmt.Errorf("low: %d, high: %d", low, high)
+	if err := checkRange(low, high, d.Base(), d.MaxValue()); err != nil {
+		return false, err
 	}
-	if high.Cmp(d.MaxValue()) > 0 {
-		return false, fmt.Errorf("low: %d, high: %d", low, high)
+	if d.policy != MERGE_KEEP_FIRST {
+		return d.pushWithPolicy(low, high, addition)
 	}
 
 	if len(d.List()) == 0 {
@@ -466,6 +479,7 @@ func (d *DataRange) Push(low *big.Int, high *big.Int, addition *ExtendData) (boo
 		} else if pos == RightOverlapLeft {
 			//self.update(index, 'low', d['low'])
 			d.List()[index].(*Entry).Low().Set(tmp.Low())
+			break
 		} else if pos == LeftOverlapRight {
 			//result.push(low=d['low'], high=self.get(index)['high'],
 			//addition=self.get(index)['addition'])
@@ -490,6 +504,7 @@ func (d *DataRange) Push(low *big.Int, high *big.Int, addition *ExtendData) (boo
 		} else if pos == LeftConnectRight {
 			//self.update(index, 'low', d['low'])
 			d.List()[index].(*Entry).Low().Set(tmp.Low())
+			break
 		} else if pos == RightConnectLeft {
 			//if index == self.len() - 1:
 			//self.update(index, 'high', d['high'])
@@ -540,24 +555,18 @@ Loop:
 	return true, nil
 }
 func (dr *DataRange) RemoveString(low string, high string) (DataRangeInf, error) {
-	l := new(big.Int)
-	fmt.Sscan(low, l)
-	h := new(big.Int)
-	fmt.Sscan(high, h)
+	l, h, err := parseRange(low, high)
+	if err != nil {
+		return nil, err
+	}
 
 	return dr.Remove(l, h)
 
 }
 
 func (dr *DataRange) Remove(low *big.Int, high *big.Int) (DataRangeInf, error) {
-	if low.Cmp(high) > 0 {
-		return nil, fmt.Errorf("low: %d, high: %d", low, high)
-	}
-	if low.Cmp(dr.Base()) < 0 {
-		return nil, fmt.Errorf("low: %d, high: %d", low, high)
-	}
-	if high.Cmp(dr.MaxValue()) > 0 {
-		return nil, fmt.Errorf("low: %d, high: %d", low, high)
+	if err := checkRange(low, high, dr.Base(), dr.MaxValue()); err != nil {
+		return nil, err
 	}
 
 	if len(dr.List()) == 0 {
@@ -935,12 +944,12 @@ func (dr *DataRange) Sub(other DataRangeInf) (DataRangeInf, error) {
 func DataRangeCmp(this DataRangeInf, other DataRangeInf) (left DataRangeInf, mid DataRangeInf, right DataRangeInf) {
 
 	if this.Size() != other.Size() {
-		return this.Copy().(*DataRange), nil, other.Copy().(*DataRange)
+		return this.Copy().(DataRangeInf), nil, other.Copy().(DataRangeInf)
 	}
 
-	this_dr := this.Copy().(*DataRange)
-	other_dr := other.Copy().(*DataRange)
-	this_dr_copy := this_dr.Copy().(*DataRange)
+	this_dr := this.Copy().(DataRangeInf)
+	other_dr := other.Copy().(DataRangeInf)
+	this_dr_copy := this_dr.Copy().(DataRangeInf)
 	rm_this, _ := this_dr.Sub(other_dr)
 	_, _ = other_dr.Sub(this_dr_copy)
 
@@ -976,8 +985,8 @@ func NewDataRangePair(one DataRangeInf, two DataRangeInf) *DataRangePair {
 		return nil
 	}
 	return &DataRangePair{
-		one.Copy().(*DataRange),
-		two.Copy().(*DataRange),
+		one.Copy().(DataRangeInf),
+		two.Copy().(DataRangeInf),
 	}
 }
 
@@ -1006,7 +1015,7 @@ func DataRangePairCmp(this DataRangePair, other DataRangePair) (left *[]DataRang
 		right2 = NewDataRange(other.two.Size(), other.two.Base())
 	}
 
-	l1 := NewDataRangePair(left1, other.one.Copy().(*DataRange))
+	l1 := NewDataRangePair(left1, other.one.Copy().(DataRangeInf))
 	l2 := NewDataRangePair(mid1, left2)
 	if l1 == nil && l2 == nil {
 		left = nil
@@ -1025,7 +1034,7 @@ func DataRangePairCmp(this DataRangePair, other DataRangePair) (left *[]DataRang
 		}
 	}
 
-	r1 := NewDataRangePair(right1, other.two.Copy().(*DataRange))
+	r1 := NewDataRangePair(right1, other.two.Copy().(DataRangeInf))
 	r2 := NewDataRangePair(mid1, right2)
 
 	if r1 == nil && r2 == nil {
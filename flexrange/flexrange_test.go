@@ -0,0 +1,319 @@
+package flexrange
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+	"tools/utils"
+)
+
+var _ DataRangeInf = &DataRange{}
+var _ DataRangeInf = &TreeDataRange{}
+
+type tagData string
+
+func (t tagData) Copy() utils.CopyAble {
+	return t
+}
+
+func (t tagData) String() string {
+	return string(t)
+}
+
+func (t tagData) MarshalJSON() (b []byte, err error) {
+	return json.Marshal(string(t))
+}
+
+func (t tagData) UnmarshalJSON(b []byte) error {
+	return nil
+}
+
+func (t tagData) Merge(other ExtendDataInt) (ExtendDataInt, error) {
+	return t + "+" + other.(tagData), nil
+}
+
+func tag(s string) *ExtendData {
+	return &ExtendData{Type: "tag", Data: tagData(s)}
+}
+
+var backends = []Backend{BACKEND_SLICE, BACKEND_TREE}
+
+func dump(dr DataRangeInf) string {
+	if dr == nil {
+		return "<nil>"
+	}
+
+	var s string
+	for it := dr.Iterator(); it.HasNext(); {
+		_, e := it.Next()
+		d := ""
+		if e.Data() != nil {
+			d = e.Data().Data.String()
+		}
+		s += fmt.Sprintf("[%d-%d%s]", e.Low(), e.High(), d)
+	}
+	return s
+}
+
+func TestDataRangeBackend(t *testing.T) {
+	// op: p 为Push，r 为Remove
+	testCases := []map[string]interface{}{
+		{
+			"ops":  [][]interface{}{{"p", 1, 5, "a"}, {"p", 10, 20, "b"}},
+			"want": "[1-5a][10-20b]",
+		},
+		{
+			"ops":  [][]interface{}{{"p", 1, 5, "a"}, {"p", 6, 9, "b"}},
+			"want": "[1-9a]",
+		},
+		{
+			"ops":  [][]interface{}{{"p", 10, 20, "a"}, {"p", 1, 9, "b"}},
+			"want": "[1-20a]",
+		},
+		{
+			"ops":  [][]interface{}{{"p", 1, 2, "a"}, {"p", 5, 6, "b"}, {"p", 9, 10, "c"}, {"p", 0, 20, "d"}},
+			"want": "[0-20a]",
+		},
+		{
+			"ops":  [][]interface{}{{"p", 1, 20, "a"}, {"r", 5, 10, ""}},
+			"want": "[1-4a][11-20a]",
+		},
+		{
+			"ops":  [][]interface{}{{"p", 1, 5, "a"}, {"p", 8, 12, "b"}, {"p", 15, 20, "c"}, {"r", 3, 16, ""}},
+			"want": "[1-2a][17-20c]",
+		},
+		{
+			"ops":  [][]interface{}{{"p", 0, 255, "a"}, {"r", 0, 255, ""}},
+			"want": "",
+		},
+		// 与第一个区间左侧重叠或者相邻，后面还有区间时，切片实现曾经再插入一次[low, high]
+		{
+			"ops":  [][]interface{}{{"p", 10, 20, "a"}, {"p", 30, 40, "b"}, {"p", 5, 15, "c"}},
+			"want": "[5-20a][30-40b]",
+		},
+		{
+			"ops":  [][]interface{}{{"p", 10, 20, "a"}, {"p", 30, 40, "b"}, {"p", 5, 9, "c"}},
+			"want": "[5-20a][30-40b]",
+		},
+	}
+
+	for _, backend := range backends {
+		for _, tc := range testCases {
+			dr := NewDataRangeWithBackend(8, big.NewInt(0), backend)
+			for _, op := range tc["ops"].([][]interface{}) {
+				low := big.NewInt(int64(op[1].(int)))
+				high := big.NewInt(int64(op[2].(int)))
+				if op[0] == "p" {
+					dr.Push(low, high, tag(op[3].(string)))
+				} else {
+					dr.Remove(low, high)
+				}
+			}
+			if got := dump(dr); got != tc["want"] {
+				t.Errorf("%s %v, got = %s, want = %s", backend, tc["ops"], got, tc["want"])
+			}
+		}
+	}
+}
+
+func TestDataRangeBackendError(t *testing.T) {
+	for _, backend := range backends {
+		dr := NewDataRangeWithBackend(8, big.NewInt(0), backend)
+		if _, err := dr.Push(big.NewInt(5), big.NewInt(1), nil); err == nil {
+			t.Errorf("%s Push low > high, want error", backend)
+		}
+		if _, err := dr.Push(big.NewInt(0), big.NewInt(256), nil); err == nil {
+			t.Errorf("%s Push out of size, want error", backend)
+		}
+		if _, err := dr.Remove(big.NewInt(0), big.NewInt(256)); err == nil {
+			t.Errorf("%s Remove out of size, want error", backend)
+		}
+	}
+	if NewDataRangeWithBackend(129, big.NewInt(0), BACKEND_TREE) != nil {
+		t.Errorf("size 129, want nil")
+	}
+}
+
+// TestTreeDataRangeRandom 随机操作，与切片实现的结果逐一比较
+func TestTreeDataRangeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		slice := NewDataRangeWithBackend(8, big.NewInt(0), BACKEND_SLICE)
+		tree := NewDataRangeWithBackend(8, big.NewInt(0), BACKEND_TREE)
+
+		for i := 0; i < 30; i++ {
+			low := r.Int63n(240)
+			high := low + r.Int63n(16)
+			if r.Intn(3) == 0 {
+				sr, _ := slice.Remove(big.NewInt(low), big.NewInt(high))
+				tr, _ := tree.Remove(big.NewInt(low), big.NewInt(high))
+				if (sr == nil) != (tr == nil) || (sr != nil && dump(sr) != dump(tr)) {
+					t.Fatalf("Remove %d-%d, slice = %v, tree = %v", low, high, sr, tr)
+				}
+			} else {
+				d := tag(fmt.Sprint(i))
+				slice.Push(big.NewInt(low), big.NewInt(high), d)
+				tree.Push(big.NewInt(low), big.NewInt(high), d)
+			}
+
+			if dump(slice) != dump(tree) {
+				t.Fatalf("slice = %s, tree = %s", dump(slice), dump(tree))
+			}
+			if slice.Count().Cmp(tree.Count()) != 0 {
+				t.Fatalf("slice.Count = %d, tree.Count = %d", slice.Count(), tree.Count())
+			}
+		}
+
+		other := NewDataRange(8, big.NewInt(0))
+		for i := 0; i < 3; i++ {
+			low := r.Int63n(240)
+			other.Push(big.NewInt(low), big.NewInt(low+r.Int63n(16)), nil)
+		}
+		if slice.Match(other) != tree.Match(other) {
+			t.Fatalf("Match %s, slice = %s, tree = %s", other, dump(slice), dump(tree))
+		}
+		if !tree.Match(tree.Copy().(DataRangeInf)) || !tree.Same(slice) {
+			t.Fatalf("tree = %s, slice = %s, want same", dump(tree), dump(slice))
+		}
+
+		sl, sm, sr := DataRangeCmp(slice, other)
+		tl, tm, tr := DataRangeCmp(tree, other)
+		if dump(sl) != dump(tl) || dump(sm) != dump(tm) || dump(sr) != dump(tr) {
+			t.Fatalf("DataRangeCmp %s with %s", dump(slice), dump(other))
+		}
+	}
+}
+
+func TestMergePolicy(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{
+			"policy": MERGE_KEEP_FIRST,
+			"ops":    [][]interface{}{{1, 10, "a"}, {5, 15, "b"}},
+			"want":   "[1-15a]",
+		},
+		{
+			"policy": MERGE_REJECT,
+			"ops":    [][]interface{}{{1, 10, "a"}, {11, 15, "b"}, {16, 20, "b"}},
+			"want":   "[1-10a][11-20b]",
+		},
+		{
+			"policy": MERGE_REJECT,
+			"ops":    [][]interface{}{{1, 10, "a"}, {5, 15, "a"}},
+			"want":   "[1-15a]",
+		},
+		{
+			"policy": MERGE_REJECT,
+			"ops":    [][]interface{}{{1, 10, "a"}, {5, 15, "b"}},
+			"want":   "[1-10a]",
+			"err":    true,
+		},
+		{
+			"policy": MERGE_SPLIT,
+			"ops":    [][]interface{}{{5, 10, "a"}, {1, 15, "b"}},
+			"want":   "[1-4b][5-10a][11-15b]",
+		},
+		{
+			"policy": MERGE_SPLIT,
+			"ops":    [][]interface{}{{1, 3, "a"}, {6, 8, "a"}, {1, 10, "a"}},
+			"want":   "[1-10a]",
+		},
+		{
+			"policy": MERGE_UNION,
+			"ops":    [][]interface{}{{1, 10, "a"}, {5, 15, "b"}},
+			"want":   "[1-4a][5-10a+b][11-15b]",
+		},
+		{
+			"policy": MERGE_LAST_WRITER,
+			"ops":    [][]interface{}{{1, 10, "a"}, {12, 20, "c"}, {5, 15, "b"}},
+			"want":   "[1-4a][5-15b][16-20c]",
+		},
+	}
+
+	for _, backend := range backends {
+		for _, tc := range testCases {
+			dr := NewDataRangeWithBackend(8, big.NewInt(0), backend)
+			dr.(interface{ SetMergePolicy(MergePolicy) }).SetMergePolicy(tc["policy"].(MergePolicy))
+
+			var err error
+			for _, op := range tc["ops"].([][]interface{}) {
+				_, err = dr.Push(big.NewInt(int64(op[0].(int))), big.NewInt(int64(op[1].(int))), tag(op[2].(string)))
+			}
+			if (err != nil) != (tc["err"] != nil) {
+				t.Errorf("%s %s %v, err = %v", backend, tc["policy"], tc["ops"], err)
+			}
+			if got := dump(dr); got != tc["want"] {
+				t.Errorf("%s %s %v, got = %s, want = %s", backend, tc["policy"], tc["ops"], got, tc["want"])
+			}
+		}
+	}
+}
+
+func TestMergePolicyRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, policy := range []MergePolicy{MERGE_REJECT, MERGE_SPLIT, MERGE_UNION, MERGE_LAST_WRITER} {
+		for round := 0; round < 50; round++ {
+			slice := NewDataRange(8, big.NewInt(0))
+			tree := NewTreeDataRange(8, big.NewInt(0))
+			slice.SetMergePolicy(policy)
+			tree.SetMergePolicy(policy)
+
+			for i := 0; i < 20; i++ {
+				low := r.Int63n(240)
+				high := low + r.Int63n(16)
+				d := tag(fmt.Sprint(r.Intn(3)))
+				_, serr := slice.Push(big.NewInt(low), big.NewInt(high), d)
+				_, terr := tree.Push(big.NewInt(low), big.NewInt(high), d)
+				if (serr == nil) != (terr == nil) || dump(slice) != dump(tree) {
+					t.Fatalf("%s Push %d-%d, slice = %s, tree = %s", policy, low, high, dump(slice), dump(tree))
+				}
+			}
+
+			l := slice.List()
+			for i := 1; i < len(l); i++ {
+				if l[i-1].High().Cmp(l[i].Low()) >= 0 {
+					t.Fatalf("%s overlap: %s", policy, dump(slice))
+				}
+			}
+		}
+	}
+}
+
+func TestEntryListDataRange(t *testing.T) {
+	el := NewEntryList(8, big.NewInt(0))
+	el.Push(big.NewInt(1), big.NewInt(10), tag("a"))
+	el.Push(big.NewInt(5), big.NewInt(15), tag("b"))
+
+	dr, err := el.DataRange(MERGE_UNION)
+	if err != nil || dump(dr) != "[1-4a][5-10a+b][11-15b]" {
+		t.Errorf("got = %s, err = %v", dump(dr), err)
+	}
+	if _, err := el.DataRange(MERGE_REJECT); err == nil {
+		t.Errorf("MERGE_REJECT, want error")
+	}
+}
+
+func benchmarkBackendPush(b *testing.B, backend Backend, n int) {
+	r := rand.New(rand.NewSource(1))
+	lows := make([]*big.Int, n)
+	for i := range lows {
+		lows[i] = new(big.Int).Lsh(big.NewInt(r.Int63()), 64)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dr := NewDataRangeWithBackend(128, big.NewInt(0), backend)
+		for _, l := range lows {
+			dr.Push(l, new(big.Int).Add(l, big.NewInt(255)), nil)
+		}
+	}
+}
+
+func BenchmarkSlicePush(b *testing.B) {
+	benchmarkBackendPush(b, BACKEND_SLICE, 1000)
+}
+
+func BenchmarkTreePush(b *testing.B) {
+	benchmarkBackendPush(b, BACKEND_TREE, 1000)
+}
@@ -0,0 +1,463 @@
+package flexrange
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"tools/utils"
+)
+
+// 泛型区间映射，值的类型在编译期确定，不再依赖ExtendData/MAPPER和反射
+
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Key IntervalMap支持的key类型，包括内置整数以及128位的utils.Uint128
+type Key interface {
+	Integer | utils.Uint128
+}
+
+type keyOps[K any] interface {
+	cmp(a K, b K) int
+	// succ 返回k+1，溢出时ok为false
+	succ(k K) (K, bool)
+	// pred 返回k-1，溢出时ok为false
+	pred(k K) (K, bool)
+	big(k K) *big.Int
+}
+
+type intOps[K Integer] struct{}
+
+func (intOps[K]) signed() bool {
+	var zero K
+	return zero-1 < zero
+}
+
+func (intOps[K]) cmp(a K, b K) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func (intOps[K]) succ(k K) (K, bool) {
+	n := k + 1
+	return n, n > k
+}
+
+func (intOps[K]) pred(k K) (K, bool) {
+	n := k - 1
+	return n, n < k
+}
+
+func (o intOps[K]) big(k K) *big.Int {
+	if o.signed() {
+		return big.NewInt(int64(k))
+	}
+	return new(big.Int).SetUint64(uint64(k))
+}
+
+type uint128Ops struct{}
+
+func (uint128Ops) cmp(a utils.Uint128, b utils.Uint128) int {
+	return a.Cmp(b)
+}
+
+func (uint128Ops) succ(k utils.Uint128) (utils.Uint128, bool) {
+	n, carry := k.AddUint64(1)
+	return n, !carry
+}
+
+func (uint128Ops) pred(k utils.Uint128) (utils.Uint128, bool) {
+	n, borrow := k.SubUint64(1)
+	return n, !borrow
+}
+
+func (uint128Ops) big(k utils.Uint128) *big.Int {
+	return k.Big()
+}
+
+type Interval[K Key, V any] struct {
+	Low   K
+	High  K
+	Value V
+}
+
+// IntervalMap 有序且互不重叠的区间，每个区间带有类型为V的值，
+// 必须通过NewIntervalMap或者NewIntervalMap128创建
+type IntervalMap[K Key, V any] struct {
+	list   []Interval[K, V]
+	ops    keyOps[K]
+	policy MergePolicy
+	equal  func(a V, b V) bool
+	merge  func(a V, b V) (V, error)
+}
+
+func NewIntervalMap[K Integer, V any]() *IntervalMap[K, V] {
+	return &IntervalMap[K, V]{ops: intOps[K]{}}
+}
+
+func NewIntervalMap128[V any]() *IntervalMap[utils.Uint128, V] {
+	return &IntervalMap[utils.Uint128, V]{ops: uint128Ops{}}
+}
+
+func (m *IntervalMap[K, V]) SetMergePolicy(p MergePolicy) {
+	m.policy = p
+}
+
+func (m *IntervalMap[K, V]) MergePolicy() MergePolicy {
+	return m.policy
+}
+
+// SetEqualFunc 设置值的比较函数，默认使用reflect.DeepEqual
+func (m *IntervalMap[K, V]) SetEqualFunc(f func(a V, b V) bool) {
+	m.equal = f
+}
+
+// SetMergeFunc 设置MERGE_UNION时重叠部分值的合并函数
+func (m *IntervalMap[K, V]) SetMergeFunc(f func(a V, b V) (V, error)) {
+	m.merge = f
+}
+
+func (m *IntervalMap[K, V]) valueEqual(a V, b V) bool {
+	if m.equal != nil {
+		return m.equal(a, b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func (m *IntervalMap[K, V]) empty() *IntervalMap[K, V] {
+	return &IntervalMap[K, V]{
+		ops:    m.ops,
+		policy: m.policy,
+		equal:  m.equal,
+		merge:  m.merge,
+	}
+}
+
+func (m *IntervalMap[K, V]) Copy() *IntervalMap[K, V] {
+	result := m.empty()
+	result.list = append([]Interval[K, V]{}, m.list...)
+	return result
+}
+
+func (m *IntervalMap[K, V]) Len() int {
+	return len(m.list)
+}
+
+func (m *IntervalMap[K, V]) Empty() bool {
+	return len(m.list) == 0
+}
+
+// Intervals 按照Low从小到大返回所有区间
+func (m *IntervalMap[K, V]) Intervals() []Interval[K, V] {
+	return append([]Interval[K, V]{}, m.list...)
+}
+
+func (m *IntervalMap[K, V]) Count() *big.Int {
+	z := new(big.Int)
+	for _, e := range m.list {
+		z.Add(z, m.ops.big(e.High))
+		z.Sub(z, m.ops.big(e.Low))
+		z.Add(z, big.NewInt(1))
+	}
+	return z
+}
+
+// Get 返回包含k的区间的值
+func (m *IntervalMap[K, V]) Get(k K) (v V, ok bool) {
+	i := sort.Search(len(m.list), func(i int) bool {
+		return m.ops.cmp(m.list[i].High, k) >= 0
+	})
+	if i < len(m.list) && m.ops.cmp(m.list[i].Low, k) <= 0 {
+		return m.list[i].Value, true
+	}
+	return v, false
+}
+
+func (m *IntervalMap[K, V]) String() string {
+	s := ""
+	for i, e := range m.list {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("[%v-%v: %v]", e.Low, e.High, e.Value)
+	}
+	return s
+}
+
+func (m *IntervalMap[K, V]) max(a K, b K) K {
+	if m.ops.cmp(a, b) > 0 {
+		return a
+	}
+	return b
+}
+
+func (m *IntervalMap[K, V]) min(a K, b K) K {
+	if m.ops.cmp(a, b) < 0 {
+		return a
+	}
+	return b
+}
+
+// touch 返回与[low, high]重叠或者相邻的区间的下标范围[begin, end)
+func (m *IntervalMap[K, V]) touch(low K, high K) (begin int, end int) {
+	begin = sort.Search(len(m.list), func(i int) bool {
+		n, ok := m.ops.succ(m.list[i].High)
+		return !ok || m.ops.cmp(n, low) >= 0
+	})
+	end = sort.Search(len(m.list), func(i int) bool {
+		n, ok := m.ops.succ(high)
+		return ok && m.ops.cmp(m.list[i].Low, n) > 0
+	})
+	return
+}
+
+// overlap 返回与[low, high]重叠的区间的下标范围[begin, end)
+func (m *IntervalMap[K, V]) overlap(low K, high K) (begin int, end int) {
+	begin = sort.Search(len(m.list), func(i int) bool {
+		return m.ops.cmp(m.list[i].High, low) >= 0
+	})
+	end = sort.Search(len(m.list), func(i int) bool {
+		return m.ops.cmp(m.list[i].Low, high) > 0
+	})
+	return
+}
+
+func (m *IntervalMap[K, V]) replace(begin int, end int, pieces []Interval[K, V]) {
+	list := make([]Interval[K, V], 0, len(m.list)-(end-begin)+len(pieces))
+	list = append(list, m.list[:begin]...)
+	list = append(list, pieces...)
+	m.list = append(list, m.list[end:]...)
+}
+
+func (m *IntervalMap[K, V]) Push(low K, high K, v V) error {
+	if m.ops.cmp(low, high) > 0 {
+		return fmt.Errorf("%w, low: %v, high: %v", ErrInvertedRange, low, high)
+	}
+
+	begin, end := m.touch(low, high)
+	affected := m.list[begin:end]
+
+	if m.policy == MERGE_KEEP_FIRST {
+		e := Interval[K, V]{Low: low, High: high, Value: v}
+		if len(affected) > 0 {
+			e.Low = m.min(low, affected[0].Low)
+			e.High = m.max(high, affected[len(affected)-1].High)
+			e.Value = affected[0].Value
+		}
+		m.replace(begin, end, []Interval[K, V]{e})
+		return nil
+	}
+
+	var pieces []Interval[K, V]
+	add := func(l K, h K, value V) {
+		if n := len(pieces); n > 0 {
+			// 相邻并且值相同的区间合并
+			if next, ok := m.ops.succ(pieces[n-1].High); ok && m.ops.cmp(next, l) == 0 && m.valueEqual(pieces[n-1].Value, value) {
+				pieces[n-1].High = h
+				return
+			}
+		}
+		pieces = append(pieces, Interval[K, V]{Low: l, High: h, Value: value})
+	}
+
+	// cur为[low, high]中尚未加入的部分的起点，done表示已经全部加入
+	cur, done := low, false
+	for _, e := range affected {
+		if m.ops.cmp(e.High, low) < 0 || m.ops.cmp(e.Low, high) > 0 {
+			if m.ops.cmp(e.Low, high) > 0 && !done {
+				add(cur, high, v)
+				done = true
+			}
+			add(e.Low, e.High, e.Value)
+			continue
+		}
+
+		if m.ops.cmp(e.Low, low) < 0 {
+			p, _ := m.ops.pred(low)
+			add(e.Low, p, e.Value)
+		}
+		if !done && m.ops.cmp(cur, e.Low) < 0 {
+			p, _ := m.ops.pred(e.Low)
+			add(cur, p, v)
+		}
+
+		value := e.Value
+		switch m.policy {
+		case MERGE_REJECT:
+			if !m.valueEqual(e.Value, v) {
+				return fmt.Errorf("low: %v, high: %v, overlap with different value: [%v-%v: %v]", low, high, e.Low, e.High, e.Value)
+			}
+		case MERGE_UNION:
+			if !m.valueEqual(e.Value, v) {
+				if m.merge == nil {
+					return fmt.Errorf("merge func is not set")
+				}
+				merged, err := m.merge(e.Value, v)
+				if err != nil {
+					return err
+				}
+				value = merged
+			}
+		case MERGE_LAST_WRITER:
+			value = v
+		}
+		add(m.max(e.Low, low), m.min(e.High, high), value)
+
+		if m.ops.cmp(e.High, high) > 0 {
+			n, _ := m.ops.succ(high)
+			add(n, e.High, e.Value)
+		}
+		cur, done = m.ops.succ(m.min(e.High, high))
+		done = !done || m.ops.cmp(e.High, high) >= 0
+	}
+	if !done {
+		add(cur, high, v)
+	}
+
+	m.replace(begin, end, pieces)
+	return nil
+}
+
+// Remove 删除[low, high]，返回被删除的部分
+func (m *IntervalMap[K, V]) Remove(low K, high K) (*IntervalMap[K, V], error) {
+	if m.ops.cmp(low, high) > 0 {
+		return nil, fmt.Errorf("%w, low: %v, high: %v", ErrInvertedRange, low, high)
+	}
+
+	removed := m.empty()
+	begin, end := m.overlap(low, high)
+	var pieces []Interval[K, V]
+	for _, e := range m.list[begin:end] {
+		if m.ops.cmp(e.Low, low) < 0 {
+			p, _ := m.ops.pred(low)
+			pieces = append(pieces, Interval[K, V]{Low: e.Low, High: p, Value: e.Value})
+		}
+		removed.list = append(removed.list, Interval[K, V]{Low: m.max(e.Low, low), High: m.min(e.High, high), Value: e.Value})
+		if m.ops.cmp(e.High, high) > 0 {
+			n, _ := m.ops.succ(high)
+			pieces = append(pieces, Interval[K, V]{Low: n, High: e.High, Value: e.Value})
+		}
+	}
+	m.replace(begin, end, pieces)
+	return removed, nil
+}
+
+func (m *IntervalMap[K, V]) Add(other *IntervalMap[K, V]) error {
+	c := m.Copy()
+	for _, e := range other.list {
+		if err := c.Push(e.Low, e.High, e.Value); err != nil {
+			return err
+		}
+	}
+	m.list = c.list
+	return nil
+}
+
+// Sub m中保持减去other剩余的部分，返回共同减去的部分
+func (m *IntervalMap[K, V]) Sub(other *IntervalMap[K, V]) *IntervalMap[K, V] {
+	removed := m.empty()
+	for _, e := range other.list {
+		r, _ := m.Remove(e.Low, e.High)
+		removed.list = append(removed.list, r.list...)
+	}
+	sort.Slice(removed.list, func(i, j int) bool {
+		return m.ops.cmp(removed.list[i].Low, removed.list[j].Low) < 0
+	})
+	return removed
+}
+
+// Match other中的每一个区间是否都被m完整覆盖
+func (m *IntervalMap[K, V]) Match(other *IntervalMap[K, V]) bool {
+	for _, o := range other.list {
+		begin, end := m.overlap(o.Low, o.High)
+		if begin == end || m.ops.cmp(m.list[begin].Low, o.Low) > 0 || m.ops.cmp(m.list[end-1].High, o.High) < 0 {
+			return false
+		}
+		for i := begin + 1; i < end; i++ {
+			if n, _ := m.ops.succ(m.list[i-1].High); m.ops.cmp(n, m.list[i].Low) != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (m *IntervalMap[K, V]) Same(other *IntervalMap[K, V]) bool {
+	return m.Match(other) && other.Match(m)
+}
+
+// Cmp 返回只在m中的部分、共同的部分(值来自m)以及只在other中的部分
+func (m *IntervalMap[K, V]) Cmp(other *IntervalMap[K, V]) (left *IntervalMap[K, V], mid *IntervalMap[K, V], right *IntervalMap[K, V]) {
+	left = m.Copy()
+	mid = left.Sub(other)
+	right = other.Copy()
+	right.Sub(m)
+	return
+}
+
+// IntervalMapFromDataRange 将DataRange转换为IntervalMap，ExtendData.Data的类型必须为V
+func IntervalMapFromDataRange[V any](dr DataRangeInf) (*IntervalMap[utils.Uint128, V], error) {
+	m := NewIntervalMap128[V]()
+	if p, ok := dr.(interface{ MergePolicy() MergePolicy }); ok {
+		m.SetMergePolicy(p.MergePolicy())
+	}
+	for it := dr.Iterator(); it.HasNext(); {
+		_, e := it.Next()
+		low, ok1 := utils.Uint128FromBig(e.Low())
+		high, ok2 := utils.Uint128FromBig(e.High())
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("entry out of uint128: %s", e)
+		}
+
+		var v V
+		if e.Data() != nil && e.Data().Data != nil {
+			d, ok := e.Data().Data.(V)
+			if !ok {
+				return nil, fmt.Errorf("data type %T is not %T", e.Data().Data, v)
+			}
+			v = d
+		}
+		m.list = append(m.list, Interval[utils.Uint128, V]{Low: low, High: high, Value: v})
+	}
+	return m, nil
+}
+
+// DataRange 将IntervalMap转换为DataRange，wrap用于将值包装为ExtendData，为nil时不保存数据
+func (m *IntervalMap[K, V]) DataRange(size uint32, base *big.Int, wrap func(v V) (*ExtendData, error)) (*DataRange, error) {
+	dr := NewDataRange(size, base)
+	if dr == nil {
+		return nil, fmt.Errorf("size: %d", size)
+	}
+	dr.SetMergePolicy(m.policy)
+	for _, e := range m.list {
+		var data *ExtendData
+		if wrap != nil {
+			d, err := wrap(e.Value)
+			if err != nil {
+				return nil, err
+			}
+			data = d
+		}
+		if _, err := dr.Push(m.ops.big(e.Low), m.ops.big(e.High), data); err != nil {
+			return nil, err
+		}
+	}
+	return dr, nil
+}
+
+// DataAs 以类型T返回Entry中保存的数据，避免调用方直接进行类型断言
+func DataAs[T any](e EntryInt) (t T, ok bool) {
+	if e == nil || e.Data() == nil || e.Data().Data == nil {
+		return t, false
+	}
+	t, ok = e.Data().Data.(T)
+	return
+}
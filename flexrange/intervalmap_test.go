@@ -0,0 +1,143 @@
+package flexrange
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+	"tools/utils"
+)
+
+func TestIntervalMapPush(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{
+			"policy": MERGE_KEEP_FIRST,
+			"ops":    [][]interface{}{{1, 5, "a"}, {6, 9, "b"}, {20, 30, "c"}},
+			"want":   "[1-9: a], [20-30: c]",
+		},
+		{
+			"policy": MERGE_REJECT,
+			"ops":    [][]interface{}{{1, 5, "a"}, {6, 9, "b"}, {10, 12, "b"}},
+			"want":   "[1-5: a], [6-12: b]",
+		},
+		{
+			"policy": MERGE_SPLIT,
+			"ops":    [][]interface{}{{5, 10, "a"}, {1, 15, "b"}},
+			"want":   "[1-4: b], [5-10: a], [11-15: b]",
+		},
+		{
+			"policy": MERGE_UNION,
+			"ops":    [][]interface{}{{1, 10, "a"}, {5, 15, "b"}},
+			"want":   "[1-4: a], [5-10: a+b], [11-15: b]",
+		},
+		{
+			"policy": MERGE_LAST_WRITER,
+			"ops":    [][]interface{}{{1, 10, "a"}, {12, 20, "c"}, {5, 15, "b"}},
+			"want":   "[1-4: a], [5-15: b], [16-20: c]",
+		},
+		{
+			"policy": MERGE_LAST_WRITER,
+			"ops":    [][]interface{}{{0, math.MaxUint32, "a"}, {math.MaxUint32, math.MaxUint32, "b"}},
+			"want":   "[0-4294967294: a], [4294967295-4294967295: b]",
+		},
+	}
+
+	for _, tc := range testCases {
+		m := NewIntervalMap[uint32, string]()
+		m.SetMergePolicy(tc["policy"].(MergePolicy))
+		m.SetMergeFunc(func(a string, b string) (string, error) {
+			return a + "+" + b, nil
+		})
+		for _, op := range tc["ops"].([][]interface{}) {
+			if err := m.Push(uint32(op[0].(int)), uint32(op[1].(int)), op[2].(string)); err != nil {
+				t.Errorf("%s %v, err = %v", tc["policy"], op, err)
+			}
+		}
+		if got := m.String(); got != tc["want"] {
+			t.Errorf("%s %v, got = %s, want = %s", tc["policy"], tc["ops"], got, tc["want"])
+		}
+	}
+}
+
+func TestIntervalMapRemove(t *testing.T) {
+	m := NewIntervalMap[int, string]()
+	m.SetMergePolicy(MERGE_SPLIT)
+	m.Push(-10, -1, "a")
+	m.Push(0, 10, "b")
+
+	removed, err := m.Remove(-5, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.String(), "[-10--6: a], [6-10: b]"; got != want {
+		t.Errorf("got = %s, want = %s", got, want)
+	}
+	if got, want := removed.String(), "[-5--1: a], [0-5: b]"; got != want {
+		t.Errorf("removed = %s, want = %s", got, want)
+	}
+	if v, ok := m.Get(7); !ok || v != "b" {
+		t.Errorf("Get(7) = %s, %v", v, ok)
+	}
+	if _, ok := m.Get(0); ok {
+		t.Errorf("Get(0), want not found")
+	}
+	if _, err := m.Remove(5, 1); err == nil {
+		t.Errorf("Remove(5, 1), want error")
+	}
+}
+
+func TestIntervalMapCmp(t *testing.T) {
+	a := NewIntervalMap128[int]()
+	b := NewIntervalMap128[int]()
+	a.Push(utils.NewUint128(1), utils.NewUint128(10), 1)
+	b.Push(utils.NewUint128(5), utils.MaxUint128, 2)
+
+	left, mid, right := a.Cmp(b)
+	if got, want := fmt.Sprint(left, "|", mid, "|", right), "[1-4: 1]|[5-10: 1]|[11-"+utils.MaxUint128.String()+": 2]"; got != want {
+		t.Errorf("got = %s, want = %s", got, want)
+	}
+	if !b.Match(right) || a.Match(b) {
+		t.Errorf("Match")
+	}
+
+	c := a.Copy()
+	if err := c.Add(b); err != nil || c.Len() != 1 || c.Count().Cmp(utils.MaxUint128.Big()) != 0 {
+		t.Errorf("Add = %s, err = %v", c, err)
+	}
+	c.Sub(right)
+	if !c.Same(a) {
+		t.Errorf("Sub = %s, want = %s", c, a)
+	}
+}
+
+func TestIntervalMapDataRange(t *testing.T) {
+	dr := NewDataRange(32, big.NewInt(0))
+	dr.SetMergePolicy(MERGE_SPLIT)
+	dr.Push(big.NewInt(1), big.NewInt(10), tag("a"))
+	dr.Push(big.NewInt(11), big.NewInt(20), tag("b"))
+
+	m, err := IntervalMapFromDataRange[tagData](dr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.String(), "[1-10: a], [11-20: b]"; got != want {
+		t.Errorf("got = %s, want = %s", got, want)
+	}
+	if _, err := IntervalMapFromDataRange[int](dr); err == nil {
+		t.Errorf("IntervalMapFromDataRange[int], want error")
+	}
+
+	back, err := m.DataRange(32, big.NewInt(0), func(v tagData) (*ExtendData, error) {
+		return tag(string(v)), nil
+	})
+	if err != nil || dump(back) != dump(dr) {
+		t.Errorf("got = %s, want = %s, err = %v", dump(back), dump(dr), err)
+	}
+
+	if v, ok := DataAs[tagData](dr.List()[1]); !ok || v != "b" {
+		t.Errorf("DataAs = %s, %v", v, ok)
+	}
+	if _, ok := DataAs[*ExtendData](dr.List()[1]); ok {
+		t.Errorf("DataAs[*ExtendData], want not ok")
+	}
+}
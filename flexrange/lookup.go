@@ -0,0 +1,75 @@
+package flexrange
+
+import (
+	"math/big"
+	"sort"
+)
+
+// 按值查找所在的区间，返回的是DataRange中保存的Entry本身（包括ExtendData），调用方不应修改
+
+// searchHigh 返回第一个High >= v的下标，列表有序且互不相交
+func searchHigh(l []EntryInt, v *big.Int) int {
+	return sort.Search(len(l), func(i int) bool {
+		return l[i].High().Cmp(v) >= 0
+	})
+}
+
+// Lookup 返回包含v的区间
+func (d *DataRange) Lookup(v *big.Int) (EntryInt, bool) {
+	if i := searchHigh(d.L, v); i < len(d.L) && d.L[i].Low().Cmp(v) <= 0 {
+		return d.L[i], true
+	}
+	return nil, false
+}
+
+// Overlapping 返回与[low, high]相交的全部区间
+func (d *DataRange) Overlapping(low *big.Int, high *big.Int) []EntryInt {
+	if low.Cmp(high) > 0 {
+		return nil
+	}
+	var result []EntryInt
+	for i := searchHigh(d.L, low); i < len(d.L) && d.L[i].Low().Cmp(high) <= 0; i++ {
+		result = append(result, d.L[i])
+	}
+	return result
+}
+
+func (t *TreeDataRange) Lookup(v *big.Int) (EntryInt, bool) {
+	if e := t.floor(v); e != nil && e.High().Cmp(v) >= 0 {
+		return e, true
+	}
+	return nil, false
+}
+
+func (t *TreeDataRange) Overlapping(low *big.Int, high *big.Int) []EntryInt {
+	if low.Cmp(high) > 0 {
+		return nil
+	}
+	// floor(low)可能与low相交，其余相交的区间的Low都在(low, high]中
+	start := low
+	if e := t.floor(low); e != nil && e.High().Cmp(low) >= 0 {
+		start = e.Low()
+	}
+	var result []EntryInt
+	walkRange(t.root, start, high, func(n *treeNode) {
+		result = append(result, n.entry)
+	})
+	return result
+}
+
+// walkRange 按顺序访问low在[low, high]中的节点
+func walkRange(t *treeNode, low *big.Int, high *big.Int, f func(n *treeNode)) {
+	if t == nil {
+		return
+	}
+	c := t.entry.Low().Cmp(low)
+	if c > 0 {
+		walkRange(t.left, low, high, f)
+	}
+	if c >= 0 && t.entry.Low().Cmp(high) <= 0 {
+		f(t)
+	}
+	if t.entry.Low().Cmp(high) < 0 {
+		walkRange(t.right, low, high, f)
+	}
+}
@@ -0,0 +1,75 @@
+package flexrange
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, backend := range backends {
+		for round := 0; round < 100; round++ {
+			// 每个值所在区间的数据，""表示不在任何区间中
+			var model [256]string
+			dr := NewDataRangeWithBackend(8, big.NewInt(0), backend)
+			for i := 0; i < r.Intn(10); i++ {
+				low := r.Intn(256)
+				high := low + r.Intn(256-low)
+				if len(dr.Overlapping(big.NewInt(int64(low)), big.NewInt(int64(high)))) > 0 {
+					continue
+				}
+				name := fmt.Sprintf("e%d", i)
+				dr.Push(big.NewInt(int64(low)), big.NewInt(int64(high)), tag(name))
+				for v := low; v <= high; v++ {
+					model[v] = name
+				}
+			}
+
+			for v := 0; v < 256; v++ {
+				e, ok := dr.Lookup(big.NewInt(int64(v)))
+				got := ""
+				if ok {
+					got = e.Data().Data.String()
+				}
+				// 相邻的区间会被合并，只比较是否命中
+				if ok != (model[v] != "") || ok && e.Low().Int64() > int64(v) || ok && e.High().Int64() < int64(v) {
+					t.Fatalf("%s Lookup(%d) = %s, want = %s, dr = %s", backend, v, got, model[v], dump(dr))
+				}
+			}
+
+			low := r.Intn(256)
+			high := low + r.Intn(256-low)
+			var want []EntryInt
+			for _, e := range dr.List() {
+				if e.High().Int64() >= int64(low) && e.Low().Int64() <= int64(high) {
+					want = append(want, e)
+				}
+			}
+			got := dr.Overlapping(big.NewInt(int64(low)), big.NewInt(int64(high)))
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("%s Overlapping(%d, %d) = %v, want = %v", backend, low, high, got, want)
+			}
+		}
+	}
+}
+
+func TestLookupData(t *testing.T) {
+	dr := NewDataRange(32, big.NewInt(0))
+	dr.Push(big.NewInt(10), big.NewInt(19), tag("CN"))
+	dr.Push(big.NewInt(30), big.NewInt(39), tag("US"))
+
+	if e, ok := dr.Lookup(big.NewInt(35)); !ok || e.Data().Data.String() != "US" {
+		t.Errorf("Lookup(35) = %v, %v", e, ok)
+	}
+	if _, ok := dr.Lookup(big.NewInt(25)); ok {
+		t.Errorf("Lookup(25), want not found")
+	}
+	if l := dr.Overlapping(big.NewInt(15), big.NewInt(30)); len(l) != 2 {
+		t.Errorf("Overlapping(15, 30) = %v", l)
+	}
+	if l := dr.Overlapping(big.NewInt(30), big.NewInt(15)); l != nil {
+		t.Errorf("Overlapping(30, 15) = %v", l)
+	}
+}
@@ -0,0 +1,245 @@
+package flexrange
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"tools/utils"
+)
+
+// MergePolicy 决定Push时重叠或者相邻的区间如何处理数据
+type MergePolicy int
+
+const (
+	// MERGE_KEEP_FIRST 默认行为，区间直接合并，保留最左侧已有区间的数据
+	MERGE_KEEP_FIRST MergePolicy = iota
+	// MERGE_REJECT 数据不同的区间不允许重叠，重叠时返回错误，相邻时不合并
+	MERGE_REJECT
+	// MERGE_SPLIT 在边界处拆分，重叠部分保留已有数据，其余部分使用新数据
+	MERGE_SPLIT
+	// MERGE_UNION 重叠部分的数据通过MergeAble.Merge合并
+	MERGE_UNION
+	// MERGE_LAST_WRITER 重叠部分使用新数据覆盖
+	MERGE_LAST_WRITER
+)
+
+func (p MergePolicy) String() string {
+	return [...]string{"KeepFirst", "Reject", "Split", "Union", "LastWriter"}[p]
+}
+
+// MergeAble ExtendDataInt可以选择实现该接口，用于MERGE_UNION
+type MergeAble interface {
+	Merge(other ExtendDataInt) (ExtendDataInt, error)
+}
+
+// DataEqual 两个数据都为nil，或者类型相同并且内容相同
+func DataEqual(a *ExtendData, b *ExtendData) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.Type == b.Type && reflect.DeepEqual(a.Data, b.Data)
+}
+
+func unionData(a *ExtendData, b *ExtendData) (*ExtendData, error) {
+	if a == nil {
+		return b, nil
+	}
+	if b == nil || DataEqual(a, b) {
+		return a, nil
+	}
+	if a.Type != b.Type {
+		return nil, fmt.Errorf("can not merge data type '%s' with '%s'", a.Type, b.Type)
+	}
+	m, ok := a.Data.(MergeAble)
+	if !ok {
+		return nil, fmt.Errorf("data type '%s' does not implement MergeAble", a.Type)
+	}
+	data, err := m.Merge(b.Data)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &ExtendData{Type: a.Type, Property: raw, Data: data}, nil
+}
+
+func maxInt(a *big.Int, b *big.Int) *big.Int {
+	if a.Cmp(b) > 0 {
+		return a
+	}
+	return b
+}
+
+func minInt(a *big.Int, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return a
+	}
+	return b
+}
+
+// mergeEntries 将[low, high]按照policy合并到affected中，affected为有序的、
+// 与[low, high]重叠或者相邻的已有区间，返回替换affected的新区间
+func mergeEntries(affected []*Entry, low *big.Int, high *big.Int, addition *ExtendData, policy MergePolicy) ([]*Entry, error) {
+	var pieces []*Entry
+	add := func(l *big.Int, h *big.Int, data *ExtendData) {
+		pieces = append(pieces, &Entry{low: utils.CopyInt(l), high: utils.CopyInt(h), data: data, strFunc: nil})
+	}
+
+	cur := utils.CopyInt(low)
+	for _, e := range affected {
+		if e.High().Cmp(low) < 0 || e.Low().Cmp(high) > 0 {
+			// 相邻的区间
+			if e.Low().Cmp(high) > 0 && cur.Cmp(high) <= 0 {
+				add(cur, high, addition)
+				cur = utils.AddInt(high, 1)
+			}
+			add(e.Low(), e.High(), e.Data())
+			continue
+		}
+
+		if e.Low().Cmp(low) < 0 {
+			add(e.Low(), utils.AddInt(low, -1), e.Data())
+		}
+		if cur.Cmp(e.Low()) < 0 {
+			add(cur, utils.AddInt(e.Low(), -1), addition)
+		}
+
+		data := e.Data()
+		switch policy {
+		case MERGE_REJECT:
+			if !DataEqual(e.Data(), addition) {
+				return nil, fmt.Errorf("low: %d, high: %d, overlap with different data: %s", low, high, e)
+			}
+		case MERGE_UNION:
+			d, err := unionData(e.Data(), addition)
+			if err != nil {
+				return nil, err
+			}
+			data = d
+		case MERGE_LAST_WRITER:
+			data = addition
+		}
+		add(maxInt(e.Low(), low), minInt(e.High(), high), data)
+
+		if e.High().Cmp(high) > 0 {
+			add(utils.AddInt(high, 1), e.High(), e.Data())
+		}
+		cur = utils.AddInt(minInt(e.High(), high), 1)
+	}
+	if cur.Cmp(high) <= 0 {
+		add(cur, high, addition)
+	}
+
+	// 相邻并且数据相同的区间合并
+	result := []*Entry{}
+	for _, p := range pieces {
+		if n := len(result); n > 0 {
+			last := result[n-1]
+			if utils.AddInt(last.High(), 1).Cmp(p.Low()) == 0 && DataEqual(last.Data(), p.Data()) {
+				last.high = p.High()
+				continue
+			}
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (d *DataRange) SetMergePolicy(p MergePolicy) {
+	d.policy = p
+}
+
+func (d *DataRange) MergePolicy() MergePolicy {
+	return d.policy
+}
+
+func (d *DataRange) pushWithPolicy(low *big.Int, high *big.Int, addition *ExtendData) (bool, error) {
+	begin, end := len(d.L), len(d.L)
+	for i, e := range d.L {
+		if utils.AddInt(e.High(), 1).Cmp(low) >= 0 {
+			begin = i
+			break
+		}
+	}
+	affected := []*Entry{}
+	for end = begin; end < len(d.L); end++ {
+		if d.L[end].Low().Cmp(utils.AddInt(high, 1)) > 0 {
+			break
+		}
+		affected = append(affected, d.L[end].(*Entry))
+	}
+
+	pieces, err := mergeEntries(affected, low, high, addition, d.policy)
+	if err != nil {
+		return false, err
+	}
+
+	list := make([]EntryInt, 0, len(d.L)-len(affected)+len(pieces))
+	list = append(list, d.L[:begin]...)
+	for _, p := range pieces {
+		list = append(list, p)
+	}
+	d.L = append(list, d.L[end:]...)
+	return true, nil
+}
+
+func (t *TreeDataRange) SetMergePolicy(p MergePolicy) {
+	t.policy = p
+}
+
+func (t *TreeDataRange) MergePolicy() MergePolicy {
+	return t.policy
+}
+
+func (t *TreeDataRange) pushWithPolicy(low *big.Int, high *big.Int, addition *ExtendData) (bool, error) {
+	l, r := split(t.root, low)
+	l, prev := removeMax(l)
+	if prev != nil && utils.AddInt(prev.entry.High(), 1).Cmp(low) < 0 {
+		l = merge(l, prev)
+		prev = nil
+	}
+	m, r := split(r, utils.AddInt(high, 2))
+
+	if prev != nil {
+		m = merge(prev, m)
+	}
+	affected := []*Entry{}
+	walk(m, func(n *treeNode) {
+		affected = append(affected, n.entry)
+	})
+
+	pieces, err := mergeEntries(affected, low, high, addition, t.policy)
+	if err != nil {
+		t.root = merge(merge(l, m), r)
+		return false, err
+	}
+
+	for _, p := range pieces {
+		l = merge(l, newTreeNode(p))
+	}
+	t.root = merge(l, r)
+	t.count += len(pieces) - len(affected)
+	t.dirty()
+	return true, nil
+}
+
+// DataRange 按照policy将EntryList中的区间依次加入DataRange
+func (el *EntryList) DataRange(policy MergePolicy) (*DataRange, error) {
+	dr := NewDataRange(el.Size(), el.Base())
+	if dr == nil {
+		return nil, fmt.Errorf("size: %d", el.Size())
+	}
+	dr.SetMergePolicy(policy)
+	for _, e := range el.list {
+		if _, err := dr.Push(utils.CopyInt(e.Low()), utils.CopyInt(e.High()), e.Data()); err != nil {
+			return nil, err
+		}
+	}
+	return dr, nil
+}
@@ -0,0 +1,130 @@
+package flexrange
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"tools/utils"
+)
+
+// SharedDataRange 可以在多个goroutine之间共享的DataRange，比如代理中的允许/拒绝地址段。
+// 采用写时复制：修改在当前快照的副本上进行，完成后原子地替换快照；
+// 读取和迭代只访问不可变的快照，不需要加锁，也不会看到修改到一半的状态
+type SharedDataRange struct {
+	// mu 只用于串行化写入
+	mu      sync.Mutex
+	current atomic.Value
+}
+
+// snapshot 保证atomic.Value中保存的类型一致，与具体的backend无关
+type snapshot struct {
+	dr DataRangeInf
+}
+
+// store 替换快照之前先生成List的缓存（TreeDataRange的List会写入缓存），之后快照上的操作都是只读的
+func (s *SharedDataRange) store(dr DataRangeInf) {
+	dr.List()
+	s.current.Store(snapshot{dr})
+}
+
+// NewSharedDataRange 以dr的副本作为初始内容，之后对dr的修改不会影响SharedDataRange
+func NewSharedDataRange(dr DataRangeInf) *SharedDataRange {
+	s := &SharedDataRange{}
+	s.store(dr.Copy().(DataRangeInf))
+	return s
+}
+
+// Snapshot 返回当前的快照，快照在之后的修改中保持不变。
+// 快照与其他goroutine共享，只能读取，需要修改时先Copy
+func (s *SharedDataRange) Snapshot() DataRangeInf {
+	return s.current.Load().(snapshot).dr
+}
+
+// Iterator 遍历当前的快照，迭代过程中的Delete、Add只影响该迭代器自己的列表
+func (s *SharedDataRange) Iterator() *Iterator {
+	dr := s.Snapshot()
+	list := make([]EntryInt, len(dr.List()))
+	copy(list, dr.List())
+	return &Iterator{
+		dr:    &DataRange{L: list, size: dr.Size(), base: dr.Base()},
+		index: 0,
+	}
+}
+
+func (s *SharedDataRange) Size() uint32 {
+	return s.Snapshot().Size()
+}
+
+func (s *SharedDataRange) Base() *big.Int {
+	return s.Snapshot().Base()
+}
+
+func (s *SharedDataRange) Empty() bool {
+	return s.Snapshot().Empty()
+}
+
+func (s *SharedDataRange) Count() *big.Int {
+	return s.Snapshot().Count()
+}
+
+func (s *SharedDataRange) String() string {
+	return fmt.Sprint(s.Snapshot())
+}
+
+func (s *SharedDataRange) Lookup(v *big.Int) (EntryInt, bool) {
+	return s.Snapshot().Lookup(v)
+}
+
+func (s *SharedDataRange) Overlapping(low *big.Int, high *big.Int) []EntryInt {
+	return s.Snapshot().Overlapping(low, high)
+}
+
+func (s *SharedDataRange) Match(other DataRangeInf) bool {
+	return s.Snapshot().Match(other)
+}
+
+// Update 在当前快照的副本上执行f，f返回错误时放弃全部修改，否则原子地替换快照。
+// 多个修改放在同一个f中时，读取方要么看到全部修改，要么一个都看不到
+func (s *SharedDataRange) Update(f func(dr DataRangeInf) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dr := s.Snapshot().Copy().(DataRangeInf)
+	if err := f(dr); err != nil {
+		return err
+	}
+	s.store(dr)
+	return nil
+}
+
+// Replace 使用dr的副本替换全部内容
+func (s *SharedDataRange) Replace(dr DataRangeInf) {
+	c := dr.Copy().(DataRangeInf)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store(c)
+}
+
+// Push 加入[low, high]，DataRange.Push会保存甚至修改传入的值，所以先复制，
+// 避免快照与调用方共享同一个*big.Int
+func (s *SharedDataRange) Push(low *big.Int, high *big.Int, addition *ExtendData) (bool, error) {
+	low, high = utils.CopyInt(low), utils.CopyInt(high)
+	var ok bool
+	err := s.Update(func(dr DataRangeInf) (err error) {
+		ok, err = dr.Push(low, high, addition)
+		return
+	})
+	return ok, err
+}
+
+// Remove 删除[low, high]，返回被删除的部分
+func (s *SharedDataRange) Remove(low *big.Int, high *big.Int) (DataRangeInf, error) {
+	low, high = utils.CopyInt(low), utils.CopyInt(high)
+	var removed DataRangeInf
+	err := s.Update(func(dr DataRangeInf) (err error) {
+		removed, err = dr.Remove(low, high)
+		return
+	})
+	return removed, err
+}
@@ -0,0 +1,112 @@
+package flexrange
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestSharedDataRange(t *testing.T) {
+	for _, backend := range backends {
+		s := NewSharedDataRange(NewDataRangeWithBackend(16, big.NewInt(0), backend))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 每次Update加入两个不相邻的区间，读取方看到的区间个数总是偶数
+			for i := int64(0); i < 200; i += 2 {
+				s.Update(func(dr DataRangeInf) error {
+					dr.Push(big.NewInt(i*4), big.NewInt(i*4+1), nil)
+					dr.Push(big.NewInt(i*4+4), big.NewInt(i*4+5), nil)
+					return nil
+				})
+			}
+		}()
+
+		for r := 0; r < 4; r++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < 200; k++ {
+					n := 0
+					for it := s.Iterator(); it.HasNext(); {
+						index, _ := it.Next()
+						// 只影响迭代器自己的列表
+						it.Delete(index)
+						n++
+					}
+					if n%2 != 0 {
+						t.Errorf("%s, saw %d entries", backend, n)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got := len(s.Snapshot().List()); got != 200 {
+			t.Errorf("%s, len = %d, want = 200", backend, got)
+		}
+		if e, ok := s.Lookup(big.NewInt(9)); !ok || e.Low().Int64() != 8 {
+			t.Errorf("%s, Lookup(9) = %v, %v", backend, e, ok)
+		}
+
+		// 批量修改中途出错时全部放弃
+		before := s.String()
+		errStop := errors.New("stop")
+		err := s.Update(func(dr DataRangeInf) error {
+			dr.Remove(big.NewInt(0), big.NewInt(100))
+			return errStop
+		})
+		if err != errStop || s.String() != before {
+			t.Errorf("%s, Update with error changed the range, err = %v", backend, err)
+		}
+
+		snap := s.Snapshot()
+		if removed, err := s.Remove(big.NewInt(0), big.NewInt(3)); err != nil || removed.Count().Int64() != 2 {
+			t.Errorf("%s, Remove, removed = %v, err = %v", backend, removed, err)
+		}
+		if _, ok := snap.Lookup(big.NewInt(0)); !ok {
+			t.Errorf("%s, old snapshot changed after Remove", backend)
+		}
+		if _, ok := s.Lookup(big.NewInt(0)); ok {
+			t.Errorf("%s, Lookup(0) after Remove, want not found", backend)
+		}
+	}
+}
+
+func TestSharedDataRangeCopyArgs(t *testing.T) {
+	for _, backend := range backends {
+		s := NewSharedDataRange(NewDataRangeWithBackend(16, big.NewInt(0), backend))
+		s.Push(big.NewInt(5), big.NewInt(6), nil)
+		s.Push(big.NewInt(20), big.NewInt(30), nil)
+
+		// [0, 10]包含[5, 6]，slice backend会在合并的过程中修改low
+		low, high := big.NewInt(0), big.NewInt(10)
+		s.Push(low, high, nil)
+		if low.Int64() != 0 || high.Int64() != 10 {
+			t.Errorf("%s, Push changed the arguments, low = %s, high = %s", backend, low, high)
+		}
+
+		low, high = big.NewInt(40), big.NewInt(50)
+		s.Push(low, high, nil)
+		before := s.String()
+		// 之后修改参数不能影响快照
+		low.SetInt64(100)
+		high.SetInt64(200)
+		if got := s.String(); got != before {
+			t.Errorf("%s, snapshot changed after Push, got = %s, want = %s", backend, got, before)
+		}
+
+		low, high = big.NewInt(22), big.NewInt(24)
+		s.Remove(low, high)
+		before = s.String()
+		low.SetInt64(100)
+		high.SetInt64(200)
+		if got := s.String(); got != before {
+			t.Errorf("%s, snapshot changed after Remove, got = %s, want = %s", backend, got, before)
+		}
+	}
+}
@@ -0,0 +1,485 @@
+package flexrange
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"tools/utils"
+)
+
+type Backend int
+
+const (
+	// BACKEND_SLICE 基于有序切片的DataRange，适合少量数据
+	BACKEND_SLICE Backend = iota
+	// BACKEND_TREE 基于treap的TreeDataRange，Push/Remove/Match均为O(log n)
+	BACKEND_TREE
+)
+
+func (b Backend) String() string {
+	return [...]string{"Slice", "Tree"}[b]
+}
+
+// NewDataRangeWithBackend 根据backend创建DataRangeInf，两种实现的行为一致
+func NewDataRangeWithBackend(size uint32, base *big.Int, backend Backend) DataRangeInf {
+	if backend == BACKEND_TREE {
+		if t := NewTreeDataRange(size, base); t != nil {
+			return t
+		}
+		return nil
+	}
+
+	if d := NewDataRange(size, base); d != nil {
+		return d
+	}
+	return nil
+}
+
+type treeNode struct {
+	entry    *Entry
+	priority uint32
+	left     *treeNode
+	right    *treeNode
+}
+
+// split 按照low拆分，l中节点的low < key，r中节点的low >= key
+func split(t *treeNode, key *big.Int) (l *treeNode, r *treeNode) {
+	if t == nil {
+		return nil, nil
+	}
+	if t.entry.Low().Cmp(key) < 0 {
+		t.right, r = split(t.right, key)
+		return t, r
+	}
+	l, t.left = split(t.left, key)
+	return l, t
+}
+
+// merge 要求a中所有节点都位于b之前
+func merge(a *treeNode, b *treeNode) *treeNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		a.right = merge(a.right, b)
+		return a
+	}
+	b.left = merge(a, b.left)
+	return b
+}
+
+func removeMax(t *treeNode) (rest *treeNode, max *treeNode) {
+	if t == nil {
+		return nil, nil
+	}
+	if t.right == nil {
+		rest = t.left
+		t.left = nil
+		return rest, t
+	}
+	t.right, max = removeMax(t.right)
+	return t, max
+}
+
+func walk(t *treeNode, f func(n *treeNode)) {
+	if t == nil {
+		return
+	}
+	walk(t.left, f)
+	f(t)
+	walk(t.right, f)
+}
+
+func newTreeNode(e *Entry) *treeNode {
+	return &treeNode{
+		entry:    e,
+		priority: rand.Uint32(),
+	}
+}
+
+// TreeDataRange 使用treap保存互不重叠且不相邻的区间，与DataRange保持相同的合并语义：
+// 合并时保留最左侧已有区间的数据，只有没有与任何已有区间合并时才使用新加入的数据
+type TreeDataRange struct {
+	root    *treeNode
+	size    uint32
+	base    *big.Int
+	count   int
+	cache   []EntryInt
+	policy  MergePolicy
+	StrFunc func() string
+}
+
+func NewTreeDataRange(size uint32, base *big.Int) *TreeDataRange {
+	if size > 128 {
+		return nil
+	}
+	return &TreeDataRange{
+		size: size,
+		base: base,
+	}
+}
+
+func (t *TreeDataRange) dirty() {
+	t.cache = nil
+}
+
+func (t *TreeDataRange) floor(key *big.Int) *Entry {
+	var result *Entry
+	for n := t.root; n != nil; {
+		if n.entry.Low().Cmp(key) <= 0 {
+			result = n.entry
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return result
+}
+
+func (t *TreeDataRange) check(low *big.Int, high *big.Int) error {
+	return checkRange(low, high, t.Base(), t.MaxValue())
+}
+
+func (t *TreeDataRange) SetList(el []EntryInt) {
+	t.root = nil
+	t.count = 0
+	t.dirty()
+	for _, e := range el {
+		t.insert(&Entry{low: e.Low(), high: e.High(), data: e.Data(), strFunc: nil})
+	}
+}
+
+func (t *TreeDataRange) insert(e *Entry) {
+	l, r := split(t.root, e.Low())
+	t.root = merge(merge(l, newTreeNode(e)), r)
+	t.count++
+	t.dirty()
+}
+
+func (t *TreeDataRange) SetBase(b *big.Int) {
+	t.base = b
+}
+
+func (t *TreeDataRange) Base() *big.Int {
+	if t.base == nil {
+		return big.NewInt(0)
+	}
+	return t.base
+}
+
+func (t *TreeDataRange) SetSize(s uint32) {
+	t.size = s
+}
+
+func (t *TreeDataRange) Size() uint32 {
+	return t.size
+}
+
+func (t *TreeDataRange) MaxValue() *big.Int {
+	m := big.NewInt(1).Lsh(big.NewInt(1), uint(t.Size()))
+	m = m.Sub(m, big.NewInt(1))
+	return m
+}
+
+func (t *TreeDataRange) Len() int {
+	return t.count
+}
+
+func (t *TreeDataRange) Empty() bool {
+	return t.root == nil
+}
+
+// List 按照low从小到大返回所有区间，结果会被缓存直到下一次修改
+func (t *TreeDataRange) List() []EntryInt {
+	if t.cache == nil {
+		t.cache = make([]EntryInt, 0, t.count)
+		walk(t.root, func(n *treeNode) {
+			t.cache = append(t.cache, n.entry)
+		})
+	}
+	return t.cache
+}
+
+func (t *TreeDataRange) Iterator() *Iterator {
+	return &Iterator{
+		dr:    t,
+		index: 0,
+	}
+}
+
+func (t *TreeDataRange) Count() *big.Int {
+	var z big.Int
+	walk(t.root, func(n *treeNode) {
+		z.Add(&z, n.entry.Count())
+	})
+	return &z
+}
+
+func (t *TreeDataRange) Delete(index int) (bool, error) {
+	list := t.List()
+	if index < 0 || index >= len(list) {
+		return false, fmt.Errorf("index: %d, len(t.List): %d", index, len(list))
+	}
+
+	e := list[index]
+	l, r := split(t.root, e.Low())
+	_, r = split(r, utils.AddInt(e.Low(), 1))
+	t.root = merge(l, r)
+	t.count--
+	t.dirty()
+	return true, nil
+}
+
+// Insert 直接插入区间，不进行合并，index会被忽略，位置由区间的low决定
+func (t *TreeDataRange) Insert(index int, s EntryInt) (bool, error) {
+	if index < 0 || index > t.count {
+		return false, fmt.Errorf("index: %d, len(t.List): %d", index, t.count)
+	}
+	t.insert(&Entry{low: s.Low(), high: s.High(), data: s.Data(), strFunc: nil})
+	return true, nil
+}
+
+func (t *TreeDataRange) Copy() utils.CopyAble {
+	result := NewTreeDataRange(t.Size(), t.Base())
+	result.policy = t.policy
+	walk(t.root, func(n *treeNode) {
+		result.insert(n.entry.Copy().(*Entry))
+	})
+	return result
+}
+
+func (t *TreeDataRange) String() string {
+	if t.StrFunc != nil {
+		return t.StrFunc()
+	}
+
+	var ls string
+	for it := t.Iterator(); it.HasNext(); {
+		_, e := it.Next()
+		if ls == "" {
+			ls = fmt.Sprintf("%s", e)
+		} else {
+			ls = fmt.Sprintf("%s, %s", ls, e)
+		}
+	}
+	return fmt.Sprintf("List:[%s], Size:%d, Base:%d", ls, t.Size(), t.Base())
+}
+
+func (t *TreeDataRange) ElementStrFunc(strFunc func() string) {
+	walk(t.root, func(n *treeNode) {
+		n.entry.WithStrFunc(strFunc)
+	})
+}
+
+func (t *TreeDataRange) WithStrFunc(strFunc func() string) {
+	t.StrFunc = strFunc
+}
+
+func (t *TreeDataRange) PushString(low string, high string, addition *ExtendData) (bool, error) {
+	l, h, err := parseRange(low, high)
+	if err != nil {
+		return false, err
+	}
+	return t.Push(l, h, addition)
+}
+
+func (t *TreeDataRange) PushEntry(e EntryInt) (bool, error) {
+	ec := e.Copy().(EntryInt)
+	return t.Push(ec.Low(), ec.High(), ec.Data())
+}
+
+func (t *TreeDataRange) Push(low *big.Int, high *big.Int, addition *ExtendData) (bool, error) {
+	if err := t.check(low, high); err != nil {
+		return false, err
+	}
+	if t.policy != MERGE_KEEP_FIRST {
+		return t.pushWithPolicy(low, high, addition)
+	}
+
+	newLow := utils.CopyInt(low)
+	newHigh := utils.CopyInt(high)
+	data := addition
+	merged := false
+
+	l, r := split(t.root, low)
+	// l中最后一个区间与[low, high]重叠或者相邻时需要合并
+	l, prev := removeMax(l)
+	if prev != nil {
+		if utils.AddInt(prev.entry.High(), 1).Cmp(low) >= 0 {
+			newLow = utils.CopyInt(prev.entry.Low())
+			if prev.entry.High().Cmp(newHigh) > 0 {
+				newHigh = utils.CopyInt(prev.entry.High())
+			}
+			data = prev.entry.Data()
+			merged = true
+			t.count--
+		} else {
+			l = merge(l, prev)
+		}
+	}
+
+	// r中low <= high+1的区间全部与[low, high]重叠或者相邻
+	m, r := split(r, utils.AddInt(high, 2))
+	walk(m, func(n *treeNode) {
+		if !merged {
+			data = n.entry.Data()
+			merged = true
+		}
+		if n.entry.High().Cmp(newHigh) > 0 {
+			newHigh = utils.CopyInt(n.entry.High())
+		}
+		t.count--
+	})
+
+	t.root = merge(merge(l, newTreeNode(&Entry{low: newLow, high: newHigh, data: data, strFunc: nil})), r)
+	t.count++
+	t.dirty()
+	return true, nil
+}
+
+func (t *TreeDataRange) RemoveString(low string, high string) (DataRangeInf, error) {
+	l, h, err := parseRange(low, high)
+	if err != nil {
+		return nil, err
+	}
+	return t.Remove(l, h)
+}
+
+// Remove 删除[low, high]，返回被删除的部分
+func (t *TreeDataRange) Remove(low *big.Int, high *big.Int) (DataRangeInf, error) {
+	if err := t.check(low, high); err != nil {
+		return nil, err
+	}
+	if t.root == nil {
+		return nil, nil
+	}
+
+	result := NewDataRange(t.Size(), t.Base())
+	var keepLeft, keepRight *Entry
+
+	cut := func(e *Entry) {
+		if e.Low().Cmp(low) < 0 {
+			keepLeft = &Entry{low: e.Low(), high: utils.AddInt(low, -1), data: e.Data(), strFunc: nil}
+		}
+		if e.High().Cmp(high) > 0 {
+			keepRight = &Entry{low: utils.AddInt(high, 1), high: e.High(), data: e.Data(), strFunc: nil}
+		}
+
+		l := e.Low()
+		if l.Cmp(low) < 0 {
+			l = low
+		}
+		h := e.High()
+		if h.Cmp(high) > 0 {
+			h = high
+		}
+		result.L = append(result.L, &Entry{low: utils.CopyInt(l), high: utils.CopyInt(h), data: e.Data(), strFunc: nil})
+		t.count--
+	}
+
+	l, r := split(t.root, low)
+	l, prev := removeMax(l)
+	if prev != nil {
+		if prev.entry.High().Cmp(low) >= 0 {
+			cut(prev.entry)
+		} else {
+			l = merge(l, prev)
+		}
+	}
+
+	m, r := split(r, utils.AddInt(high, 1))
+	walk(m, func(n *treeNode) {
+		cut(n.entry)
+	})
+
+	if keepLeft != nil {
+		l = merge(l, newTreeNode(keepLeft))
+		t.count++
+	}
+	if keepRight != nil {
+		r = merge(newTreeNode(keepRight), r)
+		t.count++
+	}
+	t.root = merge(l, r)
+	t.dirty()
+
+	return result, nil
+}
+
+// Match 判断other中的每一个区间是否都被t中的某一个区间完整包含
+func (t *TreeDataRange) Match(other DataRangeInf) bool {
+	for it := other.Iterator(); it.HasNext(); {
+		_, o := it.Next()
+		e := t.floor(o.Low())
+		if e == nil || e.High().Cmp(o.High()) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *TreeDataRange) Same(other DataRangeInf) bool {
+	return t.Match(other) && other.Match(t)
+}
+
+func (t *TreeDataRange) Add(other DataRangeInf) DataRangeInf {
+	if other == nil {
+		return nil
+	}
+	if t.Base().Cmp(other.Base()) != 0 || other.Size() != t.Size() {
+		return nil
+	}
+
+	for it := other.Iterator(); it.HasNext(); {
+		_, cur := it.Next()
+		if err := t.check(cur.Low(), cur.High()); err != nil {
+			return nil
+		}
+	}
+	for it := other.Iterator(); it.HasNext(); {
+		_, cur := it.Next()
+		t.Push(utils.CopyInt(cur.Low()), utils.CopyInt(cur.High()), cur.Data())
+	}
+
+	return t
+}
+
+// Sub t中保持减去other剩余的部分，返回共同减去的部分
+func (t *TreeDataRange) Sub(other DataRangeInf) (DataRangeInf, error) {
+	if other == nil {
+		return nil, fmt.Errorf("other: %+v", other)
+	}
+	if t.Base().Cmp(other.Base()) != 0 {
+		return nil, fmt.Errorf("other: %+v", other)
+	}
+	if other.Size() != t.Size() {
+		return nil, fmt.Errorf("other.Size: %d, t.Size: %d", other.Size(), t.Size())
+	}
+
+	for it := other.Iterator(); it.HasNext(); {
+		_, cur := it.Next()
+		if err := t.check(cur.Low(), cur.High()); err != nil {
+			return nil, err
+		}
+	}
+
+	rm := NewTreeDataRange(t.Size(), t.Base())
+	for it := other.Iterator(); it.HasNext(); {
+		_, cur := it.Next()
+		tmp, err := t.Remove(utils.CopyInt(cur.Low()), utils.CopyInt(cur.High()))
+		if err != nil {
+			return nil, err
+		}
+		if tmp != nil {
+			for it2 := tmp.Iterator(); it2.HasNext(); {
+				_, e := it2.Next()
+				rm.PushEntry(e)
+			}
+		}
+	}
+
+	return rm, nil
+}
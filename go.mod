@@ -1,6 +1,6 @@
 module tools
 
-go 1.17
+go 1.18
 
 require (
 	github.com/360EntSecGroup-Skylar/excelize v1.4.1
@@ -9,6 +9,7 @@ require (
 	github.com/mitchellh/mapstructure v1.5.0
 	github.com/shakinm/xlsReader v0.9.12
 	github.com/thedevsaddam/gojsonq/v2 v2.5.2
+	gopkg.in/yaml.v3 v3.0.1
 	gorm.io/gorm v1.23.6
 )
 
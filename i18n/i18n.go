@@ -0,0 +1,208 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 按照错误码生成消息，模板中的{name}会被参数中同名的值替换，比如:
+//
+//	"ip address format error, ip:{ip}"
+//
+// 默认使用英文，SetLanguage、SetTranslator可以切换
+
+const (
+	LANG_EN = "en"
+	LANG_ZH = "zh"
+)
+
+// Translator 返回code对应的消息，没有对应的模板时ok为false
+type Translator interface {
+	Translate(code string, params map[string]interface{}) (msg string, ok bool)
+}
+
+// Catalog 错误码到模板的映射
+type Catalog map[string]string
+
+func (c Catalog) Translate(code string, params map[string]interface{}) (string, bool) {
+	tpl, ok := c[code]
+	if !ok {
+		return "", false
+	}
+	return Render(tpl, params), true
+}
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[string]Catalog{
+		LANG_EN: messagesEN,
+		LANG_ZH: messagesZH,
+	}
+	current Translator = messagesEN
+	// lang 默认Translator对应的语言，通过SetTranslator设置时为空
+	lang = LANG_EN
+)
+
+// RegisterMessages 向lang的Catalog中添加模板，与已有模板同名时覆盖。
+// 已经返回给调用方的Catalog不会被修改
+func RegisterMessages(l string, messages map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	old := catalogs[l]
+	c := make(Catalog, len(old)+len(messages))
+	for k, v := range old {
+		c[k] = v
+	}
+	for k, v := range messages {
+		c[k] = v
+	}
+	catalogs[l] = c
+	if l == lang {
+		current = c
+	}
+}
+
+// GetCatalog 返回lang对应的Catalog，只能读取
+func GetCatalog(l string) (Catalog, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := catalogs[l]
+	return c, ok
+}
+
+// Languages 已注册的语言
+func Languages() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]string, 0, len(catalogs))
+	for l := range catalogs {
+		list = append(list, l)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// SetLanguage 使用lang对应的Catalog作为默认的Translator
+func SetLanguage(l string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	c, ok := catalogs[l]
+	if !ok {
+		return fmt.Errorf("unknown language: %s", l)
+	}
+	current, lang = c, l
+	return nil
+}
+
+// Language 默认Translator对应的语言，通过SetTranslator设置时为空
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return lang
+}
+
+// SetTranslator 替换默认的Translator，t为nil时恢复为英文
+func SetTranslator(t Translator) {
+	mu.Lock()
+	defer mu.Unlock()
+	if t == nil {
+		current, lang = catalogs[LANG_EN], LANG_EN
+		return
+	}
+	current, lang = t, ""
+}
+
+// Default 返回默认的Translator
+func Default() Translator {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T 使用默认的Translator生成消息
+func T(code string, params map[string]interface{}) string {
+	return Translate(Default(), code, params)
+}
+
+// Translate 使用t生成消息，t中没有code时依次尝试英文模板、code本身
+func Translate(t Translator, code string, params map[string]interface{}) string {
+	if t != nil {
+		if msg, ok := t.Translate(code, params); ok {
+			return msg
+		}
+	}
+	if c, ok := GetCatalog(LANG_EN); ok {
+		if msg, ok := c.Translate(code, params); ok {
+			return msg
+		}
+	}
+	if len(params) == 0 {
+		return code
+	}
+	return fmt.Sprintf("%s %v", code, params)
+}
+
+// Render 替换tpl中的{name}，params中不存在的name保持原样
+func Render(tpl string, params map[string]interface{}) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(tpl, '{')
+		if i == -1 {
+			break
+		}
+		j := strings.IndexByte(tpl[i:], '}')
+		if j == -1 {
+			break
+		}
+		name := tpl[i+1 : i+j]
+		b.WriteString(tpl[:i])
+		if v, ok := params[name]; ok {
+			b.WriteString(format(v))
+		} else {
+			b.WriteString(tpl[i : i+j+1])
+		}
+		tpl = tpl[i+j+1:]
+	}
+	b.WriteString(tpl)
+	return b.String()
+}
+
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case map[string]interface{}:
+		return fmt.Sprintf("%+v", v)
+	}
+	return fmt.Sprint(v)
+}
+
+// Error 带错误码的error，Error()使用默认的Translator生成消息，
+// Params["err"]为error时作为被包装的错误
+type Error struct {
+	Code   string
+	Params map[string]interface{}
+}
+
+func NewError(code string, params map[string]interface{}) *Error {
+	return &Error{Code: code, Params: params}
+}
+
+func (e *Error) Error() string {
+	return T(e.Code, e.Params)
+}
+
+// Translate 使用t生成消息
+func (e *Error) Translate(t Translator) string {
+	return Translate(t, e.Code, e.Params)
+}
+
+func (e *Error) Unwrap() error {
+	err, _ := e.Params["err"].(error)
+	return err
+}
@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"tpl": "ip:{ip}", "params": map[string]interface{}{"ip": "1.1.1.1"}, "want": "ip:1.1.1.1"},
+		{"tpl": "{value} out of range {min}-{max}", "params": map[string]interface{}{"value": 0, "min": 1.0, "max": 4094.0}, "want": "0 out of range 1-4094"},
+		{"tpl": "{a}{b}", "params": map[string]interface{}{"a": "x", "b": io.EOF}, "want": "xEOF"},
+		{"tpl": "missing {name}", "params": map[string]interface{}{}, "want": "missing {name}"},
+		{"tpl": "unclosed {name", "params": map[string]interface{}{"name": "x"}, "want": "unclosed {name"},
+		{"tpl": "no params", "params": map[string]interface{}(nil), "want": "no params"},
+	}
+	for _, tc := range testCases {
+		got := Render(tc["tpl"].(string), tc["params"].(map[string]interface{}))
+		if got != tc["want"] {
+			t.Errorf("Render(%s), got = %s, want = %s", tc["tpl"], got, tc["want"])
+		}
+	}
+}
+
+// 内置的中英文模板需要一一对应
+func TestCatalogs(t *testing.T) {
+	for code := range messagesEN {
+		if _, ok := messagesZH[code]; !ok {
+			t.Errorf("code %s missing in %s", code, LANG_ZH)
+		}
+	}
+	for code := range messagesZH {
+		if _, ok := messagesEN[code]; !ok {
+			t.Errorf("code %s missing in %s", code, LANG_EN)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLanguage(LANG_EN)
+
+	err := error(NewError("file_read", map[string]interface{}{"path": "a.xlsx", "err": io.EOF}))
+	if err.Error() != "read file a.xlsx failed: EOF" {
+		t.Errorf("err = %s", err)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("errors.Is(%v, io.EOF) = false", err)
+	}
+
+	if err := SetLanguage("xx"); err == nil {
+		t.Errorf("SetLanguage(xx), err = nil")
+	}
+	if err := SetLanguage(LANG_ZH); err != nil {
+		t.Fatalf("SetLanguage(%s), err = %v", LANG_ZH, err)
+	}
+	if err.Error() != "读取文件（a.xlsx）失败：EOF" || Language() != LANG_ZH {
+		t.Errorf("err = %s, lang = %s", err, Language())
+	}
+
+	// 当前语言的模板可以在运行时补充，缺少的模板使用英文
+	RegisterMessages(LANG_ZH, map[string]string{"test_code": "测试{n}"})
+	RegisterMessages(LANG_EN, map[string]string{"test_only_en": "test {n}"})
+	params := map[string]interface{}{"n": 1}
+	if got := T("test_code", params); got != "测试1" {
+		t.Errorf("T(test_code) = %s", got)
+	}
+	if got := T("test_only_en", params); got != "test 1" {
+		t.Errorf("T(test_only_en) = %s", got)
+	}
+	if got := T("no_such_code", params); got != "no_such_code map[n:1]" {
+		t.Errorf("T(no_such_code) = %s", got)
+	}
+
+	SetTranslator(Catalog{"file_read": "{path}"})
+	if err.Error() != "a.xlsx" || Language() != "" {
+		t.Errorf("err = %s, lang = %s", err, Language())
+	}
+	SetTranslator(nil)
+	if Language() != LANG_EN {
+		t.Errorf("lang = %s", Language())
+	}
+}
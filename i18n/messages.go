@@ -0,0 +1,141 @@
+package i18n
+
+// 内置的英文、中文模板，键为错误码
+
+var messagesEN = Catalog{
+	// validator
+	"field_empty":       "{key} field is empty, data:{data}",
+	"field_not_string":  "{key} field is not string, data:{data}",
+	"ip_empty":          "ip address is empty",
+	"ip_bad_octet":      "bad octet '{octet}', ip:{ip}",
+	"ip_octet_count":    "ipv4 address needs 4 octets, got {count}, ip:{ip}",
+	"ip_bad_group":      "bad group '{group}', ip:{ip}",
+	"ip_bad_char":       "unexpected character '{char}', ip:{ip}",
+	"ip_group_count":    "ipv6 address needs 8 groups, ip:{ip}",
+	"ip_double_colon":   "'::' must appear at most once and replace at least one group, ip:{ip}",
+	"ip_embedded_v4":    "embedded ipv4 address must replace the last 2 groups, ip:{ip}",
+	"ip_family":         "not an {family} address, ip:{ip}",
+	"ip_prefix_format":  "bad prefix '{prefix}', ip:{ip}",
+	"ip_prefix_range":   "prefix {prefix} out of range 0-{max}, ip:{ip}",
+	"ip_host_bits":      "host bits are set for prefix {prefix}, ip:{ip}",
+	"required":          "field is required",
+	"unknown_rule":      "unknown validate rule: '{rule}'",
+	"struct_nil":        "struct is nil",
+	"type_string":       "need string, got {type}",
+	"type_number":       "need number, got {type}",
+	"type_struct":       "need struct, got {type}",
+	"not_number":        "not a number: {value}",
+	"range":             "{value} out of range {min}-{max}",
+	"range_param":       "invalid range param: '{param}'",
+	"ip":                "ip address valide error, ip:{value}",
+	"ipv4":              "ip address valide error, ip:{value}",
+	"ipv6":              "ip address valide error, ip:{value}",
+	"cidr":              "ip address valide error, ip:{value}",
+	"iprange":           "ip range valide error, range:{value}",
+	"mac":               "mac address format error: {value}",
+	"vlan":              "vlan format error: {value}",
+	"vlans":             "vlan range format error: {value}",
+	"port":              "port format error: {value}",
+	"ports":             "port range format error: {value}",
+	"asn":               "asn format error: {value}",
+	"rd":                "route distinguisher format error: {value}",
+	"rt":                "route target format error: {value}",
+	"interface":         "interface name format error: {value}",
+	"hostname":          "hostname format error: {value}",
+	"fqdn":              "fqdn format error: {value}",
+	"wildcard":          "wildcard mask format error: {value}",
+	"any_of":            "none of the validators passed",
+	"not":               "{validator} should not pass",
+	"type_list":         "need list, got {type}",
+	"in_subnet":         "{value} is not in {other}: {other_value}",
+	"canceled":          "{validator} canceled: {err}",
+	"deadline_exceeded": "{validator} did not finish before deadline: {err}",
+
+	// network
+	"nexthop_vs":                 "next hop vs {vs} does not implement VsInt, interface:{interface}, ip:{ip}",
+	"nexthop_ip":                 "next hop ip format error, interface:{interface}, ip:{ip}",
+	"nexthop_ip_required":        "next hop ip is required when not connected, interface:{interface}",
+	"nexthop_interface_required": "next hop interface is required when connected, ip:{ip}",
+	"nexthop_empty":              "{route} ip and interface is empty",
+	"nexthop_unreachable":        "next hop ip {ip} match route failed",
+
+	// file
+	"file_path_empty": "file path is empty",
+	"file_not_exist":  "file {path} does not exist",
+	"file_read":       "read file {path} failed: {err}",
+	"file_open":       "open file {path} failed: {err}",
+	"file_create":     "create file {path} failed: {err}",
+	"file_copy":       "copy file failed: {err}",
+	"excel_cell":      "sheet {sheet}, cell {cell} ({header}): {msg}",
+	"excel_row":       "sheet {sheet}, row {row}: {msg}",
+	"excel_convert":   "cannot convert '{value}': {err}",
+}
+
+var messagesZH = Catalog{
+	// validator
+	"field_empty":       "{key}字段为空，data:{data}",
+	"field_not_string":  "{key}字段不是字符串，data:{data}",
+	"ip_empty":          "IP地址为空",
+	"ip_bad_octet":      "无效的字段'{octet}'，ip:{ip}",
+	"ip_octet_count":    "IPv4地址需要4个字段，实际为{count}个，ip:{ip}",
+	"ip_bad_group":      "无效的分组'{group}'，ip:{ip}",
+	"ip_bad_char":       "无效的字符'{char}'，ip:{ip}",
+	"ip_group_count":    "IPv6地址需要8个分组，ip:{ip}",
+	"ip_double_colon":   "'::'最多出现一次，并且至少代表一个分组，ip:{ip}",
+	"ip_embedded_v4":    "嵌入的IPv4地址只能替换最后两个分组，ip:{ip}",
+	"ip_family":         "不是{family}地址，ip:{ip}",
+	"ip_prefix_format":  "无效的前缀'{prefix}'，ip:{ip}",
+	"ip_prefix_range":   "前缀{prefix}超出范围0-{max}，ip:{ip}",
+	"ip_host_bits":      "前缀{prefix}的主机位不为0，ip:{ip}",
+	"required":          "字段不能为空",
+	"unknown_rule":      "未知的校验规则：'{rule}'",
+	"struct_nil":        "struct为空",
+	"type_string":       "需要字符串，实际为{type}",
+	"type_number":       "需要数字，实际为{type}",
+	"type_struct":       "需要struct，实际为{type}",
+	"not_number":        "不是数字：{value}",
+	"range":             "{value}超出范围{min}-{max}",
+	"range_param":       "无效的范围参数：'{param}'",
+	"ip":                "IP地址无效，ip:{value}",
+	"ipv4":              "IPv4地址无效，ip:{value}",
+	"ipv6":              "IPv6地址无效，ip:{value}",
+	"cidr":              "网段无效，ip:{value}",
+	"iprange":           "IP范围无效，range:{value}",
+	"mac":               "MAC地址格式错误：{value}",
+	"vlan":              "VLAN格式错误：{value}",
+	"vlans":             "VLAN范围格式错误：{value}",
+	"port":              "端口格式错误：{value}",
+	"ports":             "端口范围格式错误：{value}",
+	"asn":               "AS号格式错误：{value}",
+	"rd":                "RD格式错误：{value}",
+	"rt":                "RT格式错误：{value}",
+	"interface":         "接口名格式错误：{value}",
+	"hostname":          "主机名格式错误：{value}",
+	"fqdn":              "域名格式错误：{value}",
+	"wildcard":          "反掩码格式错误：{value}",
+	"any_of":            "所有校验都没有通过",
+	"not":               "{validator}不应该通过",
+	"type_list":         "需要列表，实际为{type}",
+	"in_subnet":         "{value}不在{other}（{other_value}）中",
+	"canceled":          "{validator}已取消：{err}",
+	"deadline_exceeded": "{validator}超时：{err}",
+
+	// network
+	"nexthop_vs":                 "下一跳的vs（{vs}）没有实现VsInt，interface:{interface}，ip:{ip}",
+	"nexthop_ip":                 "下一跳IP格式错误，interface:{interface}，ip:{ip}",
+	"nexthop_ip_required":        "非直连路由的下一跳IP不能为空，interface:{interface}",
+	"nexthop_interface_required": "直连路由的出接口不能为空，ip:{ip}",
+	"nexthop_empty":              "{route}的下一跳IP和出接口都为空",
+	"nexthop_unreachable":        "下一跳IP {ip} 没有匹配的路由",
+
+	// file
+	"file_path_empty": "文件路径不能为空",
+	"file_not_exist":  "文件: {path} ,不存在",
+	"file_read":       "读取文件（{path}）失败：{err}",
+	"file_open":       "打开文件（{path}）失败：{err}",
+	"file_create":     "创建文件（{path}）失败：{err}",
+	"file_copy":       "文件拷贝失败：{err}",
+	"excel_cell":      "工作表{sheet}，单元格{cell}（{header}）：{msg}",
+	"excel_row":       "工作表{sheet}，第{row}行：{msg}",
+	"excel_convert":   "无法转换'{value}'：{err}",
+}
@@ -0,0 +1,156 @@
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"tools/flexrange"
+)
+
+// NetworkList、NetworkGroup的集合运算，基于flexrange中的线性扫描实现
+
+func familySize(fa IPFamily) uint32 {
+	if fa == IPv6 {
+		return 128
+	}
+	return 32
+}
+
+// dataRange 与DataRange()相同，但是列表为空时返回空的DataRange而不是nil
+func (nl *NetworkList) dataRange() flexrange.DataRangeInf {
+	if dr := nl.DataRange(); dr != nil {
+		return dr
+	}
+	return flexrange.NewDataRange(familySize(nl.Type()), big.NewInt(0))
+}
+
+func newNetworkListFromDataRange(fa IPFamily, dr flexrange.DataRangeInf) (*NetworkList, error) {
+	if dr == nil || dr.Empty() {
+		return &NetworkList{fa, []*Network{}}, nil
+	}
+	return NewNetworkListFromDataRange(dr)
+}
+
+func (nl *NetworkList) setOp(other *NetworkList, op func(a, b flexrange.DataRangeInf) flexrange.DataRangeInf) (*NetworkList, error) {
+	if nl.Type() != other.Type() {
+		return nil, fmt.Errorf("nl.Type() = %s, other.Type() = %s", nl.Type(), other.Type())
+	}
+	return newNetworkListFromDataRange(nl.Type(), op(nl.dataRange(), other.dataRange()))
+}
+
+func (nl *NetworkList) Intersect(other *NetworkList) (*NetworkList, error) {
+	return nl.setOp(other, flexrange.Intersect)
+}
+
+func (nl *NetworkList) Difference(other *NetworkList) (*NetworkList, error) {
+	return nl.setOp(other, flexrange.Difference)
+}
+
+func (nl *NetworkList) SymmetricDiff(other *NetworkList) (*NetworkList, error) {
+	return nl.setOp(other, flexrange.SymmetricDiff)
+}
+
+// Complement 返回同一地址族中不在nl中的地址
+func (nl *NetworkList) Complement() (*NetworkList, error) {
+	return newNetworkListFromDataRange(nl.Type(), flexrange.Complement(nl.dataRange()))
+}
+
+func (nl *NetworkList) IsDisjoint(other *NetworkList) bool {
+	if nl.Type() != other.Type() {
+		return true
+	}
+	return flexrange.IsDisjoint(nl.dataRange(), other.dataRange())
+}
+
+func (nl *NetworkList) IsSubsetOf(other *NetworkList) bool {
+	if nl.IsEmpty() {
+		return true
+	}
+	if nl.Type() != other.Type() {
+		return false
+	}
+	return flexrange.IsSubsetOf(nl.dataRange(), other.dataRange())
+}
+
+// UnionNetworkList 多个NetworkList的并集，地址族必须相同
+func UnionNetworkList(fa IPFamily, lists ...*NetworkList) (*NetworkList, error) {
+	drs := []flexrange.DataRangeInf{flexrange.NewDataRange(familySize(fa), big.NewInt(0))}
+	for _, nl := range lists {
+		if nl.Type() != fa {
+			return nil, fmt.Errorf("nl.Type() = %s, fa = %s", nl.Type(), fa)
+		}
+		drs = append(drs, nl.dataRange())
+	}
+	return newNetworkListFromDataRange(fa, flexrange.Union(drs...))
+}
+
+// v4、v6 返回地址族正确的NetworkList，空列表的family可能没有被设置
+func (ng *NetworkGroup) v4() *NetworkList {
+	return &NetworkList{IPv4, ng.ipv4.list}
+}
+
+func (ng *NetworkGroup) v6() *NetworkList {
+	return &NetworkList{IPv6, ng.ipv6.list}
+}
+
+func (ng *NetworkGroup) setOp(other *NetworkGroup, op func(a, b *NetworkList) (*NetworkList, error)) (*NetworkGroup, error) {
+	v4, err := op(ng.v4(), other.v4())
+	if err != nil {
+		return nil, err
+	}
+	v6, err := op(ng.v6(), other.v6())
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkGroup{*v4, *v6}, nil
+}
+
+func (ng *NetworkGroup) Intersect(other *NetworkGroup) (*NetworkGroup, error) {
+	return ng.setOp(other, (*NetworkList).Intersect)
+}
+
+func (ng *NetworkGroup) Difference(other *NetworkGroup) (*NetworkGroup, error) {
+	return ng.setOp(other, (*NetworkList).Difference)
+}
+
+func (ng *NetworkGroup) SymmetricDiff(other *NetworkGroup) (*NetworkGroup, error) {
+	return ng.setOp(other, (*NetworkList).SymmetricDiff)
+}
+
+// Complement 分别对IPv4、IPv6求补集，没有IPv6地址的ng的补集包含全部IPv6地址
+func (ng *NetworkGroup) Complement() (*NetworkGroup, error) {
+	v4, err := ng.v4().Complement()
+	if err != nil {
+		return nil, err
+	}
+	v6, err := ng.v6().Complement()
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkGroup{*v4, *v6}, nil
+}
+
+func (ng *NetworkGroup) IsDisjoint(other *NetworkGroup) bool {
+	return ng.v4().IsDisjoint(other.v4()) && ng.v6().IsDisjoint(other.v6())
+}
+
+func (ng *NetworkGroup) IsSubsetOf(other *NetworkGroup) bool {
+	return ng.v4().IsSubsetOf(other.v4()) && ng.v6().IsSubsetOf(other.v6())
+}
+
+// UnionNetworkGroup 多个NetworkGroup的并集
+func UnionNetworkGroup(groups ...*NetworkGroup) (*NetworkGroup, error) {
+	var v4, v6 []*NetworkList
+	for _, ng := range groups {
+		v4 = append(v4, ng.v4())
+		v6 = append(v6, ng.v6())
+	}
+	l4, err := UnionNetworkList(IPv4, v4...)
+	if err != nil {
+		return nil, err
+	}
+	l6, err := UnionNetworkList(IPv6, v6...)
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkGroup{*l4, *l6}, nil
+}
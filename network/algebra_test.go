@@ -0,0 +1,64 @@
+package network
+
+import "testing"
+
+func TestNetworkGroupSetAlgebra(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{
+			"a":         "10.0.0.0/8,2001:db8::/32",
+			"b":         "10.1.0.0/16,11.0.0.0/8",
+			"intersect": "10.1.0.0/16",
+			"symdiff":   "10.0.0.0/16,10.2.0.0/15,10.4.0.0/14,10.8.0.0/13,10.16.0.0/12,10.32.0.0/11,10.64.0.0/10,10.128.0.0/9,11.0.0.0/8\n2001:db8::/32",
+			"union":     "10.0.0.0/7\n2001:db8::/32",
+			"disjoint":  false,
+			"subset":    false,
+		},
+		{
+			"a":         "192.168.1.0/24",
+			"b":         "192.168.0.0/16",
+			"intersect": "192.168.1.0/24",
+			"symdiff":   "192.168.0.0/24,192.168.2.0/23,192.168.4.0/22,192.168.8.0/21,192.168.16.0/20,192.168.32.0/19,192.168.64.0/18,192.168.128.0/17",
+			"union":     "192.168.0.0/16",
+			"disjoint":  false,
+			"subset":    true,
+		},
+		{
+			"a":         "1.1.1.1",
+			"b":         "2001:db8::1",
+			"intersect": "",
+			"symdiff":   "1.1.1.1/32\n2001:db8::1/128",
+			"union":     "1.1.1.1/32\n2001:db8::1/128",
+			"disjoint":  true,
+			"subset":    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		a, _ := NewNetworkGroupFromString(tc["a"].(string))
+		b, _ := NewNetworkGroupFromString(tc["b"].(string))
+
+		if r, err := a.Intersect(b); err != nil || r.String() != tc["intersect"] {
+			t.Errorf("%s Intersect %s, got = %s, want = %s, err = %v", tc["a"], tc["b"], r, tc["intersect"], err)
+		}
+		if r, err := a.SymmetricDiff(b); err != nil || r.String() != tc["symdiff"] {
+			t.Errorf("%s SymmetricDiff %s, got = %s, want = %s, err = %v", tc["a"], tc["b"], r, tc["symdiff"], err)
+		}
+		if r, err := UnionNetworkGroup(a, b); err != nil || r.String() != tc["union"] {
+			t.Errorf("%s Union %s, got = %s, want = %s, err = %v", tc["a"], tc["b"], r, tc["union"], err)
+		}
+		if a.IsDisjoint(b) != tc["disjoint"] {
+			t.Errorf("%s IsDisjoint %s, want = %v", tc["a"], tc["b"], tc["disjoint"])
+		}
+		if a.IsSubsetOf(b) != tc["subset"] {
+			t.Errorf("%s IsSubsetOf %s, want = %v", tc["a"], tc["b"], tc["subset"])
+		}
+
+		c, err := a.Complement()
+		if err != nil || !c.IsDisjoint(a) {
+			t.Errorf("%s Complement = %s, err = %v", tc["a"], c, err)
+		}
+		if all, _ := UnionNetworkGroup(a, c); all.String() != "0.0.0.0/0\n::/0" {
+			t.Errorf("%s Union Complement = %s", tc["a"], all)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"tools/utils"
+)
+
+// 二进制编码，格式见utils.BinaryWriter
+//
+// IP:           地址族(1字节) 定长地址(4或者16字节)
+// IPNet:        IP 前缀长度(1字节)，不连续的掩码为0xFF加定长掩码
+// IPRange:      地址族 Start End
+// Network:      AbbrNetType(1字节) 对应类型的内容
+// NetworkList:  地址族 数量 Network...
+// NetworkGroup: IPv4的NetworkList IPv6的NetworkList
+
+const (
+	BINARY_IP byte = iota + 16
+	BINARY_IPNET
+	BINARY_IPRANGE
+	BINARY_NETWORK
+	BINARY_NETWORKLIST
+	BINARY_NETWORKGROUP
+)
+
+const maskNotContiguous = 0xFF
+
+// maxBinaryDepth Network以及NetworkList嵌套的最大层数，防止构造的数据导致栈溢出
+const maxBinaryDepth = 32
+
+func familyLen(fa IPFamily) (int, error) {
+	switch fa {
+	case IPv4:
+		return IPv4Len, nil
+	case IPv6:
+		return IPv6Len, nil
+	}
+	return 0, fmt.Errorf("unknown ip family: %d", fa)
+}
+
+func encodeIP(w *utils.BinaryWriter, ip IP) error {
+	if len(ip) != IPv4Len && len(ip) != IPv6Len {
+		return fmt.Errorf("len(ip) = %d, invalid ip", len(ip))
+	}
+	w.Byte(byte(ip.Type()))
+	w.Raw(ip)
+	return nil
+}
+
+func decodeFamily(r *utils.BinaryReader) (IPFamily, int, error) {
+	fa := IPFamily(r.Byte())
+	if r.Err() != nil {
+		return fa, 0, r.Err()
+	}
+	n, err := familyLen(fa)
+	return fa, n, err
+}
+
+func decodeIP(r *utils.BinaryReader) (IP, error) {
+	_, n, err := decodeFamily(r)
+	if err != nil {
+		return nil, err
+	}
+	ip := IP(r.Raw(n))
+	return ip, r.Err()
+}
+
+func (n *IPNet) encode(w *utils.BinaryWriter) error {
+	if err := encodeIP(w, n.IP); err != nil {
+		return err
+	}
+	if len(n.Mask) != len(n.IP) {
+		return fmt.Errorf("len(mask) = %d, len(ip) = %d", len(n.Mask), len(n.IP))
+	}
+	if prefix := n.Mask.Prefix(); prefix > -1 {
+		w.Byte(byte(prefix))
+	} else {
+		w.Byte(maskNotContiguous)
+		w.Raw(n.Mask)
+	}
+	return nil
+}
+
+func decodeIPNet(r *utils.BinaryReader) (*IPNet, error) {
+	ip, err := decodeIP(r)
+	if err != nil {
+		return nil, err
+	}
+	prefix := r.Byte()
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+
+	var mask IPMask
+	if prefix == maskNotContiguous {
+		mask = IPMask(r.Raw(len(ip)))
+		if r.Err() != nil {
+			return nil, r.Err()
+		}
+	} else {
+		if int(prefix) > len(ip)*8 {
+			return nil, fmt.Errorf("prefix %d out of range", prefix)
+		}
+		m, err := NewIPMask(uint(prefix), ip.Type())
+		if err != nil {
+			return nil, err
+		}
+		mask = *m
+	}
+	return &IPNet{IP: ip, Mask: mask}, nil
+}
+
+func (r *IPRange) encode(w *utils.BinaryWriter) error {
+	if len(r.Start) != len(r.End) {
+		return fmt.Errorf("len(start) = %d, len(end) = %d", len(r.Start), len(r.End))
+	}
+	if err := encodeIP(w, r.Start); err != nil {
+		return err
+	}
+	w.Raw(r.End)
+	return nil
+}
+
+func decodeIPRange(r *utils.BinaryReader) (*IPRange, error) {
+	start, err := decodeIP(r)
+	if err != nil {
+		return nil, err
+	}
+	end := IP(r.Raw(len(start)))
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+	if bytes.Compare(start, end) > 0 {
+		return nil, fmt.Errorf("start %s is greater than end %s", start, end)
+	}
+	return &IPRange{Start: start, End: end}, nil
+}
+
+func (net *Network) encode(w *utils.BinaryWriter) error {
+	switch n := net.AbbrNet.(type) {
+	case *IPNet:
+		w.Byte(byte(IPNET))
+		return n.encode(w)
+	case *IPRange:
+		w.Byte(byte(IPRANGE))
+		return n.encode(w)
+	case *Network:
+		w.Byte(byte(NETWORK))
+		return n.encode(w)
+	case *NetworkList:
+		w.Byte(byte(NETWORKLIST))
+		return n.encode(w)
+	}
+	return fmt.Errorf("unsupported AbbrNet type: %T", net.AbbrNet)
+}
+
+func decodeNetwork(r *utils.BinaryReader, depth int) (*Network, error) {
+	if depth > maxBinaryDepth {
+		return nil, fmt.Errorf("network nested too deep, max depth is %d", maxBinaryDepth)
+	}
+	var n AbbrNet
+	var err error
+	switch t := AbbrNetType(r.Byte()); t {
+	case IPNET:
+		n, err = decodeIPNet(r)
+	case IPRANGE:
+		n, err = decodeIPRange(r)
+	case NETWORK:
+		n, err = decodeNetwork(r, depth+1)
+	case NETWORKLIST:
+		n, err = decodeNetworkList(r, depth+1)
+	default:
+		if r.Err() != nil {
+			return nil, r.Err()
+		}
+		return nil, fmt.Errorf("unknown AbbrNet type: %d", t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Network{n}, nil
+}
+
+// encode 空的NetworkList也写入地址族，零值的地址族为IPv4
+func (nl *NetworkList) encode(w *utils.BinaryWriter) error {
+	if _, err := familyLen(nl.Type()); err != nil {
+		return err
+	}
+	w.Byte(byte(nl.Type()))
+	w.Uvarint(uint64(len(nl.list)))
+	for _, n := range nl.list {
+		if n.Type() != nl.Type() {
+			return fmt.Errorf("network %s, family is not %s", n, nl.Type())
+		}
+		if err := n.encode(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeNetworkList(r *utils.BinaryReader, depth int) (*NetworkList, error) {
+	if depth > maxBinaryDepth {
+		return nil, fmt.Errorf("network nested too deep, max depth is %d", maxBinaryDepth)
+	}
+	fa, _, err := decodeFamily(r)
+	if err != nil {
+		return nil, err
+	}
+	count := r.Count()
+	list := make([]*Network, 0, count)
+	for i := 0; i < count; i++ {
+		n, err := decodeNetwork(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if n.Type() != fa {
+			return nil, fmt.Errorf("network %s, family is not %s", n, fa)
+		}
+		list = append(list, n)
+	}
+	return &NetworkList{fa, list}, r.Err()
+}
+
+func (m IP) MarshalBinary() ([]byte, error) {
+	w := utils.NewBinaryWriter(BINARY_IP)
+	if err := encodeIP(w, m); err != nil {
+		return nil, err
+	}
+	return w.Result(), nil
+}
+
+func (m *IP) UnmarshalBinary(b []byte) error {
+	r := utils.NewBinaryReader(b, BINARY_IP)
+	ip, err := decodeIP(r)
+	if err != nil {
+		return err
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	*m = ip
+	return nil
+}
+
+func (n IPNet) MarshalBinary() ([]byte, error) {
+	w := utils.NewBinaryWriter(BINARY_IPNET)
+	if err := n.encode(w); err != nil {
+		return nil, err
+	}
+	return w.Result(), nil
+}
+
+func (n *IPNet) UnmarshalBinary(b []byte) error {
+	r := utils.NewBinaryReader(b, BINARY_IPNET)
+	d, err := decodeIPNet(r)
+	if err != nil {
+		return err
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	*n = *d
+	return nil
+}
+
+func (r IPRange) MarshalBinary() ([]byte, error) {
+	w := utils.NewBinaryWriter(BINARY_IPRANGE)
+	if err := r.encode(w); err != nil {
+		return nil, err
+	}
+	return w.Result(), nil
+}
+
+func (r *IPRange) UnmarshalBinary(b []byte) error {
+	reader := utils.NewBinaryReader(b, BINARY_IPRANGE)
+	d, err := decodeIPRange(reader)
+	if err != nil {
+		return err
+	}
+	if err := reader.Close(); err != nil {
+		return err
+	}
+	*r = *d
+	return nil
+}
+
+func (net Network) MarshalBinary() ([]byte, error) {
+	w := utils.NewBinaryWriter(BINARY_NETWORK)
+	if err := net.encode(w); err != nil {
+		return nil, err
+	}
+	return w.Result(), nil
+}
+
+func (net *Network) UnmarshalBinary(b []byte) error {
+	r := utils.NewBinaryReader(b, BINARY_NETWORK)
+	d, err := decodeNetwork(r, 0)
+	if err != nil {
+		return err
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	*net = *d
+	return nil
+}
+
+func (nl *NetworkList) MarshalBinary() ([]byte, error) {
+	w := utils.NewBinaryWriter(BINARY_NETWORKLIST)
+	if err := nl.encode(w); err != nil {
+		return nil, err
+	}
+	return w.Result(), nil
+}
+
+func (nl *NetworkList) UnmarshalBinary(b []byte) error {
+	r := utils.NewBinaryReader(b, BINARY_NETWORKLIST)
+	d, err := decodeNetworkList(r, 0)
+	if err != nil {
+		return err
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	*nl = *d
+	return nil
+}
+
+func (ng NetworkGroup) MarshalBinary() ([]byte, error) {
+	w := utils.NewBinaryWriter(BINARY_NETWORKGROUP)
+	if err := ng.v4().encode(w); err != nil {
+		return nil, err
+	}
+	if err := ng.v6().encode(w); err != nil {
+		return nil, err
+	}
+	return w.Result(), nil
+}
+
+func (ng *NetworkGroup) UnmarshalBinary(b []byte) error {
+	r := utils.NewBinaryReader(b, BINARY_NETWORKGROUP)
+	v4, err := decodeNetworkList(r, 0)
+	if err != nil {
+		return err
+	}
+	v6, err := decodeNetworkList(r, 0)
+	if err != nil {
+		return err
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	if v4.Type() != IPv4 || v6.Type() != IPv6 {
+		return fmt.Errorf("invalid NetworkGroup family")
+	}
+	ng.ipv4 = *v4
+	ng.ipv6 = *v6
+	return nil
+}
@@ -0,0 +1,168 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+	"tools/utils"
+)
+
+func TestNetworkBinary(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"ng": "192.168.1.0/24"},
+		{"ng": "10.0.0.1-10.0.0.9,172.16.0.0/12,2001:db8::/32"},
+		{"ng": "1.1.1.1,2.2.2.0/24,3.3.3.3-3.3.4.4,fe80::1-fe80::ff"},
+	}
+
+	for _, tc := range testCases {
+		ng, err := NewNetworkGroupFromString(tc["ng"].(string))
+		if err != nil {
+			t.Fatalf("NewNetworkGroupFromString(%s), err = %v", tc["ng"], err)
+		}
+		b, err := ng.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(%s), err = %v", tc["ng"], err)
+		}
+
+		got := &NetworkGroup{}
+		if err := got.UnmarshalBinary(b); err != nil || got.String() != ng.String() {
+			t.Errorf("round trip %s, got = %s, err = %v", tc["ng"], got, err)
+		}
+		if js, _ := json.Marshal(ng); len(b) > len(js) {
+			t.Errorf("%s, len(binary) = %d, len(json) = %d", tc["ng"], len(b), len(js))
+		}
+		for i := 0; i < len(b); i++ {
+			if err := (&NetworkGroup{}).UnmarshalBinary(b[:i]); err == nil {
+				t.Errorf("UnmarshalBinary(b[:%d]) of %s, want error", i, tc["ng"])
+			}
+		}
+		if err := (&NetworkList{}).UnmarshalBinary(b); err == nil {
+			t.Errorf("UnmarshalBinary NetworkGroup as NetworkList, want error")
+		}
+	}
+}
+
+func TestIPBinary(t *testing.T) {
+	for _, s := range []string{"0.0.0.0", "192.168.1.1", "::", "2001:db8::1"} {
+		ip, _ := ParseIP(s)
+		b, err := ip.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(%s), err = %v", s, err)
+		}
+		got := IP{}
+		if err := got.UnmarshalBinary(b); err != nil || got.String() != ip.String() {
+			t.Errorf("round trip %s, got = %s, err = %v", s, got, err)
+		}
+	}
+
+	for _, s := range []string{"10.0.0.0/8", "0.0.0.0/0", "2001:db8::/64", "::1/128"} {
+		n, _ := ParseIPNet(s)
+		b, err := n.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(%s), err = %v", s, err)
+		}
+		got := IPNet{}
+		if err := got.UnmarshalBinary(b); err != nil || got.String() != n.String() {
+			t.Errorf("round trip %s, got = %s, err = %v", s, got.String(), err)
+		}
+	}
+
+	// 不连续的掩码
+	n := IPNet{IP: IP{10, 0, 0, 0}, Mask: IPMask{255, 0, 255, 0}}
+	b, _ := n.MarshalBinary()
+	got := IPNet{}
+	if err := got.UnmarshalBinary(b); err != nil || string(got.Mask) != string(n.Mask) {
+		t.Errorf("round trip mask %v, got = %v, err = %v", n.Mask, got.Mask, err)
+	}
+
+	r, _ := NewIPRange("10.0.0.1-10.0.2.3")
+	b, _ = r.MarshalBinary()
+	gr := IPRange{}
+	if err := gr.UnmarshalBinary(b); err != nil || gr.String() != r.String() {
+		t.Errorf("round trip %s, got = %s, err = %v", r, gr.String(), err)
+	}
+
+	// 错误的地址族以及超出范围的前缀
+	if err := (&IP{}).UnmarshalBinary([]byte{1, BINARY_IP, 5, 1, 2, 3, 4}); err == nil {
+		t.Errorf("UnmarshalBinary with unknown family, want error")
+	}
+	if err := (&IPNet{}).UnmarshalBinary([]byte{1, BINARY_IPNET, 0, 1, 2, 3, 4, 33}); err == nil {
+		t.Errorf("UnmarshalBinary with prefix 33, want error")
+	}
+}
+
+func TestNetworkBinaryDepth(t *testing.T) {
+	nested := func(depth int) []byte {
+		w := utils.NewBinaryWriter(BINARY_NETWORK)
+		for i := 0; i < depth; i++ {
+			w.Byte(byte(NETWORK))
+		}
+		w.Byte(byte(IPNET))
+		w.Byte(byte(IPv4))
+		w.Raw([]byte{10, 0, 0, 0})
+		w.Byte(8)
+		return w.Result()
+	}
+
+	testCases := []map[string]interface{}{
+		{"depth": 0, "ok": true},
+		{"depth": maxBinaryDepth, "ok": true},
+		{"depth": maxBinaryDepth + 1, "ok": false},
+		{"depth": 1 << 20, "ok": false},
+	}
+	for _, tc := range testCases {
+		n := Network{}
+		err := n.UnmarshalBinary(nested(tc["depth"].(int)))
+		if (err == nil) != tc["ok"].(bool) {
+			t.Errorf("UnmarshalBinary(depth %d), err = %v", tc["depth"], err)
+		}
+		if err == nil && n.String() != "10.0.0.0/8" {
+			t.Errorf("UnmarshalBinary(depth %d) = %s", tc["depth"], n)
+		}
+	}
+
+	// 嵌套的NetworkList
+	w := utils.NewBinaryWriter(BINARY_NETWORKLIST)
+	for i := 0; i < 1<<16; i++ {
+		w.Byte(byte(IPv4))
+		w.Uvarint(1)
+		w.Byte(byte(NETWORKLIST))
+	}
+	if err := (&NetworkList{}).UnmarshalBinary(w.Result()); err == nil {
+		t.Errorf("UnmarshalBinary(nested NetworkList), want error")
+	}
+}
+
+func TestIPRangeBinaryInverted(t *testing.T) {
+	w := utils.NewBinaryWriter(BINARY_IPRANGE)
+	w.Byte(byte(IPv4))
+	w.Raw([]byte{10, 0, 0, 9})
+	w.Raw([]byte{10, 0, 0, 1})
+	if err := (&IPRange{}).UnmarshalBinary(w.Result()); err == nil {
+		t.Errorf("UnmarshalBinary(10.0.0.9-10.0.0.1), want error")
+	}
+}
+
+func TestNetworkListBinaryEmpty(t *testing.T) {
+	for _, nl := range []*NetworkList{{}, NewNetworkGroup().IPv4(), NewNetworkGroup().IPv6()} {
+		b, err := nl.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(empty %s), err = %v", nl.Type(), err)
+		}
+		got := &NetworkList{}
+		if err := got.UnmarshalBinary(b); err != nil || got.Type() != nl.Type() || len(got.List()) != 0 {
+			t.Errorf("round trip empty %s, got = %s %v, err = %v", nl.Type(), got.Type(), got.List(), err)
+		}
+	}
+
+	b, err := NewNetworkGroup().MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(empty NetworkGroup), err = %v", err)
+	}
+	if err := (&NetworkGroup{}).UnmarshalBinary(b); err != nil {
+		t.Errorf("round trip empty NetworkGroup, err = %v", err)
+	}
+
+	if _, err := (&NetworkList{family: IPFamily(7)}).MarshalBinary(); err == nil {
+		t.Errorf("MarshalBinary(family 7), want error")
+	}
+}
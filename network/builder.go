@@ -0,0 +1,24 @@
+package network
+
+import (
+	"math/big"
+	"tools/flexrange"
+)
+
+// ParseIPInt 将IP地址解析为整数，可以作为flexrange.LineSource、flexrange.CSVSource的Value
+func ParseIPInt(s string) (*big.Int, error) {
+	ip, err := ParseIP(s)
+	if err != nil {
+		return nil, err
+	}
+	return ip.Int(), nil
+}
+
+// NewNetworkListFromSource 从按地址升序的src构建NetworkList，比如威胁情报、GeoIP等大量地址段
+func NewNetworkListFromSource(fa IPFamily, src flexrange.Source) (*NetworkList, error) {
+	dr, err := flexrange.BuildDataRange(familySize(fa), big.NewInt(0), src)
+	if err != nil {
+		return nil, err
+	}
+	return newNetworkListFromDataRange(fa, dr)
+}
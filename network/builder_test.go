@@ -0,0 +1,23 @@
+package network
+
+import (
+	"strings"
+	"testing"
+	"tools/flexrange"
+)
+
+func TestNewNetworkListFromSource(t *testing.T) {
+	input := "start,end,country\n1.0.0.0,1.0.0.255,AU\n1.0.1.0,1.0.3.255,CN\n1.0.8.0,1.0.15.255,CN\n"
+	src := flexrange.NewCSVSource(strings.NewReader(input), true)
+	src.Value = ParseIPInt
+	nl, err := NewNetworkListFromSource(IPv4, src)
+	if err != nil || nl.String() != "1.0.0.0/22,1.0.8.0/21" {
+		t.Errorf("got = %s, err = %v", nl, err)
+	}
+
+	ls := flexrange.NewLineSource(strings.NewReader("10.0.0.1\n9.0.0.0-9.0.0.9\n"))
+	ls.Value = ParseIPInt
+	if _, err := NewNetworkListFromSource(IPv4, ls); err == nil {
+		t.Errorf("unsorted input, want error")
+	}
+}
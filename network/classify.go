@@ -0,0 +1,358 @@
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"tools/flexrange"
+)
+
+type AddressClass int
+
+const (
+	CLASS_GLOBAL AddressClass = iota
+	CLASS_PRIVATE
+	CLASS_SHARED
+	CLASS_LOOPBACK
+	CLASS_LINK_LOCAL
+	CLASS_MULTICAST
+	CLASS_DOCUMENTATION
+	CLASS_BENCHMARKING
+	CLASS_THIS_NETWORK
+	CLASS_UNSPECIFIED
+	CLASS_RESERVED
+	CLASS_BROADCAST
+	CLASS_PROTOCOL_ASSIGNMENT
+	CLASS_6TO4
+	CLASS_TEREDO
+	CLASS_IPV4_MAPPED
+	CLASS_NAT64
+	CLASS_DISCARD
+	CLASS_SITE_LOCAL
+	CLASS_BOGON
+)
+
+func (c AddressClass) String() string {
+	return [...]string{
+		"Global", "Private", "Shared", "Loopback", "LinkLocal", "Multicast", "Documentation",
+		"Benchmarking", "ThisNetwork", "Unspecified", "Reserved", "Broadcast", "ProtocolAssignment",
+		"6to4", "Teredo", "IPv4Mapped", "NAT64", "Discard", "SiteLocal", "Bogon",
+	}[c]
+}
+
+type MulticastScope int
+
+const (
+	SCOPE_NONE MulticastScope = iota
+	SCOPE_INTERFACE_LOCAL
+	SCOPE_LINK_LOCAL
+	SCOPE_ADMIN_LOCAL
+	SCOPE_SITE_LOCAL
+	SCOPE_ORGANIZATION_LOCAL
+	SCOPE_GLOBAL
+	// SCOPE_REALM_LOCAL IPv6 scope 3，RFC7346
+	SCOPE_REALM_LOCAL
+	// SCOPE_UNASSIGNED IPv6 scope 6、7、9-D，由管理员定义
+	SCOPE_UNASSIGNED
+	// SCOPE_RESERVED IPv6 scope 0与F
+	SCOPE_RESERVED
+)
+
+func (s MulticastScope) String() string {
+	return [...]string{"None", "InterfaceLocal", "LinkLocal", "AdminLocal", "SiteLocal", "OrganizationLocal", "Global",
+		"RealmLocal", "Unassigned", "Reserved"}[s]
+}
+
+// SpecialRange IANA special-purpose地址注册表中的一项
+// Bogon表示该地址段不应该出现在公网路由中
+type SpecialRange struct {
+	Net   *IPNet
+	Class AddressClass
+	RFC   string
+	Bogon bool
+}
+
+func newSpecialRange(s string, class AddressClass, rfc string, bogon bool) *SpecialRange {
+	net, err := ParseIPNet(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return &SpecialRange{
+		Net:   net,
+		Class: class,
+		RFC:   rfc,
+		Bogon: bogon,
+	}
+}
+
+// 参考 iana-ipv4-special-registry 与 iana-ipv6-special-registry
+var specialRegistry = []*SpecialRange{
+	newSpecialRange("0.0.0.0/8", CLASS_THIS_NETWORK, "RFC791", true),
+	newSpecialRange("0.0.0.0/32", CLASS_UNSPECIFIED, "RFC1122", true),
+	newSpecialRange("10.0.0.0/8", CLASS_PRIVATE, "RFC1918", true),
+	newSpecialRange("100.64.0.0/10", CLASS_SHARED, "RFC6598", true),
+	newSpecialRange("127.0.0.0/8", CLASS_LOOPBACK, "RFC1122", true),
+	newSpecialRange("169.254.0.0/16", CLASS_LINK_LOCAL, "RFC3927", true),
+	newSpecialRange("172.16.0.0/12", CLASS_PRIVATE, "RFC1918", true),
+	newSpecialRange("192.0.0.0/24", CLASS_PROTOCOL_ASSIGNMENT, "RFC6890", true),
+	newSpecialRange("192.0.2.0/24", CLASS_DOCUMENTATION, "RFC5737", true),
+	newSpecialRange("192.88.99.0/24", CLASS_6TO4, "RFC7526", true),
+	newSpecialRange("192.168.0.0/16", CLASS_PRIVATE, "RFC1918", true),
+	newSpecialRange("198.18.0.0/15", CLASS_BENCHMARKING, "RFC2544", true),
+	newSpecialRange("198.51.100.0/24", CLASS_DOCUMENTATION, "RFC5737", true),
+	newSpecialRange("203.0.113.0/24", CLASS_DOCUMENTATION, "RFC5737", true),
+	newSpecialRange("224.0.0.0/4", CLASS_MULTICAST, "RFC5771", true),
+	newSpecialRange("240.0.0.0/4", CLASS_RESERVED, "RFC1112", true),
+	newSpecialRange("255.255.255.255/32", CLASS_BROADCAST, "RFC919", true),
+
+	newSpecialRange("::/128", CLASS_UNSPECIFIED, "RFC4291", true),
+	newSpecialRange("::1/128", CLASS_LOOPBACK, "RFC4291", true),
+	newSpecialRange("::ffff:0:0/96", CLASS_IPV4_MAPPED, "RFC4291", true),
+	newSpecialRange("64:ff9b::/96", CLASS_NAT64, "RFC6052", false),
+	newSpecialRange("64:ff9b:1::/48", CLASS_NAT64, "RFC8215", false),
+	newSpecialRange("100::/64", CLASS_DISCARD, "RFC6666", true),
+	newSpecialRange("2001::/23", CLASS_PROTOCOL_ASSIGNMENT, "RFC2928", false),
+	newSpecialRange("2001::/32", CLASS_TEREDO, "RFC4380", false),
+	newSpecialRange("2001:2::/48", CLASS_BENCHMARKING, "RFC5180", true),
+	newSpecialRange("2001:db8::/32", CLASS_DOCUMENTATION, "RFC3849", true),
+	newSpecialRange("2002::/16", CLASS_6TO4, "RFC3056", false),
+	newSpecialRange("3fff::/20", CLASS_DOCUMENTATION, "RFC9637", true),
+	newSpecialRange("fc00::/7", CLASS_PRIVATE, "RFC4193", true),
+	newSpecialRange("fe80::/10", CLASS_LINK_LOCAL, "RFC4291", true),
+	newSpecialRange("fec0::/10", CLASS_SITE_LOCAL, "RFC3879", true),
+	newSpecialRange("ff00::/8", CLASS_MULTICAST, "RFC4291", true),
+}
+
+// SpecialRanges 返回指定地址族的special-purpose注册表
+func SpecialRanges(fa IPFamily) []*SpecialRange {
+	list := []*SpecialRange{}
+	for _, r := range specialRegistry {
+		if r.Net.Type() == fa {
+			list = append(list, r)
+		}
+	}
+	return list
+}
+
+// classRanges 每个地址族中每个类别合并后的DataRange，第一次使用时计算
+var (
+	classRangesOnce sync.Once
+	classRanges     map[IPFamily][]flexrange.DataRangeInf
+)
+
+func classRange(fa IPFamily, c AddressClass) flexrange.DataRangeInf {
+	classRangesOnce.Do(func() {
+		classRanges = map[IPFamily][]flexrange.DataRangeInf{}
+		for _, f := range []IPFamily{IPv4, IPv6} {
+			for c := CLASS_GLOBAL; c <= CLASS_BOGON; c++ {
+				classRanges[f] = append(classRanges[f], classDataRange(f, c))
+			}
+		}
+	})
+	return classRanges[fa][c]
+}
+
+// classify 返回完整覆盖[first, last]的所有地址类别，按照类别合并后的地址段判断，
+// 所以跨越多个相邻注册表项的范围也能得到正确的类别。CLASS_GLOBAL即不属于任何Bogon，
+// 与classDataRange(CLASS_GLOBAL)一致，部分为Bogon的范围既不是Bogon也不是Global
+func classify(first, last *IP) []AddressClass {
+	size := uint32(len(*first) * 8)
+	target := flexrange.NewDataRange(size, big.NewInt(0))
+	target.Push(first.Int(), last.Int(), nil)
+
+	classes := []AddressClass{}
+	for c := CLASS_GLOBAL; c <= CLASS_BOGON; c++ {
+		if classRange(first.Type(), c).Match(target) {
+			classes = append(classes, c)
+		}
+	}
+
+	return classes
+}
+
+func hasClass(classes []AddressClass, c AddressClass) bool {
+	for _, e := range classes {
+		if e == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (ip *IP) Classes() []AddressClass {
+	return classify(ip, ip)
+}
+
+func (ip *IP) IsClass(c AddressClass) bool {
+	return hasClass(ip.Classes(), c)
+}
+
+func (ip *IP) IsPrivate() bool {
+	return ip.IsClass(CLASS_PRIVATE)
+}
+
+func (ip *IP) IsLoopback() bool {
+	return ip.IsClass(CLASS_LOOPBACK)
+}
+
+func (ip *IP) IsLinkLocal() bool {
+	return ip.IsClass(CLASS_LINK_LOCAL)
+}
+
+func (ip *IP) IsMulticast() bool {
+	return ip.IsClass(CLASS_MULTICAST)
+}
+
+func (ip *IP) IsDocumentation() bool {
+	return ip.IsClass(CLASS_DOCUMENTATION)
+}
+
+func (ip *IP) IsBogon() bool {
+	return ip.IsClass(CLASS_BOGON)
+}
+
+func (ip *IP) IsGlobal() bool {
+	return ip.IsClass(CLASS_GLOBAL)
+}
+
+// MulticastScope IPv6根据ffXY中的scope字段Y判断（RFC4291、RFC7346），IPv4根据RFC5771与RFC2365划分
+func (ip *IP) MulticastScope() MulticastScope {
+	if !ip.IsMulticast() {
+		return SCOPE_NONE
+	}
+
+	if ip.Type() == IPv6 {
+		switch (*ip)[1] & 0x0F {
+		case 0x0, 0xF:
+			return SCOPE_RESERVED
+		case 0x1:
+			return SCOPE_INTERFACE_LOCAL
+		case 0x2:
+			return SCOPE_LINK_LOCAL
+		case 0x3:
+			return SCOPE_REALM_LOCAL
+		case 0x4:
+			return SCOPE_ADMIN_LOCAL
+		case 0x5:
+			return SCOPE_SITE_LOCAL
+		case 0x8:
+			return SCOPE_ORGANIZATION_LOCAL
+		case 0xE:
+			return SCOPE_GLOBAL
+		default:
+			return SCOPE_UNASSIGNED
+		}
+	}
+
+	switch {
+	case (*ip)[0] == 224 && (*ip)[1] == 0 && (*ip)[2] == 0:
+		return SCOPE_LINK_LOCAL
+	case (*ip)[0] == 239 && (*ip)[1] == 255:
+		return SCOPE_SITE_LOCAL
+	case (*ip)[0] == 239 && (*ip)[1]&0xFC == 192:
+		return SCOPE_ORGANIZATION_LOCAL
+	case (*ip)[0] == 239:
+		return SCOPE_ADMIN_LOCAL
+	default:
+		return SCOPE_GLOBAL
+	}
+}
+
+// Classes 返回完整包含n的地址类别
+func (n *IPNet) Classes() []AddressClass {
+	return classify(n.First(), n.Last())
+}
+
+func (n *IPNet) IsClass(c AddressClass) bool {
+	return hasClass(n.Classes(), c)
+}
+
+func (n *IPNet) IsBogon() bool {
+	return n.IsClass(CLASS_BOGON)
+}
+
+// Classes 返回完整包含r的地址类别
+func (r *IPRange) Classes() []AddressClass {
+	return classify(r.First(), r.Last())
+}
+
+func (r *IPRange) IsClass(c AddressClass) bool {
+	return hasClass(r.Classes(), c)
+}
+
+func (r *IPRange) IsBogon() bool {
+	return r.IsClass(CLASS_BOGON)
+}
+
+// classDataRange SpecialRanges中都是合法的CIDR，Push的错误可以忽略
+func classDataRange(fa IPFamily, c AddressClass) flexrange.DataRangeInf {
+	size := uint32(32)
+	if fa == IPv6 {
+		size = 128
+	}
+	dr := flexrange.NewDataRange(size, big.NewInt(0))
+	for _, r := range SpecialRanges(fa) {
+		if r.Class == c || (c == CLASS_BOGON && r.Bogon) {
+			dr.Push(r.Net.First().Int(), r.Net.Last().Int(), nil)
+		}
+	}
+
+	if c == CLASS_GLOBAL {
+		bogon := classDataRange(fa, CLASS_BOGON)
+		dr.Push(big.NewInt(0), dr.MaxValue(), nil)
+		dr.Sub(bogon)
+	}
+
+	return dr
+}
+
+func (nl *NetworkList) classSubset(c AddressClass) (*NetworkList, error) {
+	dr := nl.DataRange()
+	if dr == nil {
+		return &NetworkList{nl.Type(), []*Network{}}, nil
+	}
+
+	_, mid, _ := flexrange.DataRangeCmp(dr, classRange(nl.Type(), c))
+	if mid == nil {
+		return &NetworkList{nl.Type(), []*Network{}}, nil
+	}
+
+	return NewNetworkListFromDataRange(mid)
+}
+
+// ClassSubset 返回ng中属于类别c的部分
+func (ng *NetworkGroup) ClassSubset(c AddressClass) (*NetworkGroup, error) {
+	v4, err := ng.ipv4.classSubset(c)
+	if err != nil {
+		return nil, err
+	}
+	v6, err := ng.ipv6.classSubset(c)
+	if err != nil {
+		return nil, err
+	}
+	v4.family = IPv4
+	v6.family = IPv6
+
+	return &NetworkGroup{
+		*v4,
+		*v6,
+	}, nil
+}
+
+// SplitByClass 将ng按照地址类别进行拆分，只返回非空的子集
+// 由于注册表中的地址段存在包含关系（比如RFC1918同时也是Bogon），同一地址可能出现在多个子集中
+func (ng *NetworkGroup) SplitByClass() (map[AddressClass]*NetworkGroup, error) {
+	result := map[AddressClass]*NetworkGroup{}
+	for c := CLASS_GLOBAL; c <= CLASS_BOGON; c++ {
+		sub, err := ng.ClassSubset(c)
+		if err != nil {
+			return nil, fmt.Errorf("class: %s, %v", c, err)
+		}
+		if !sub.IsEmpty() {
+			result[c] = sub
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"addr": "8.8.8.8", "classes": "[Global]"},
+		{"addr": "10.1.1.1", "classes": "[Private Bogon]"},
+		{"addr": "0.0.0.0", "classes": "[ThisNetwork Unspecified Bogon]"},
+		{"addr": "255.255.255.255", "classes": "[Reserved Broadcast Bogon]"},
+		{"addr": "224.0.0.1", "classes": "[Multicast Bogon]"},
+		{"addr": "::1", "classes": "[Loopback Bogon]"},
+		{"addr": "2400:cb00::1", "classes": "[Global]"},
+		// 不是Bogon的注册表项同时也是Global
+		{"addr": "2002::1", "classes": "[Global 6to4]"},
+		{"addr": "64:ff9b::1", "classes": "[Global NAT64]"},
+		{"addr": "2001::1", "classes": "[Global ProtocolAssignment Teredo]"},
+		{"addr": "2001:db8::1", "classes": "[Documentation Bogon]"},
+		// 跨越相邻的注册表项
+		{"addr": "224.0.0.0-255.255.255.255", "classes": "[Bogon]"},
+		{"addr": "240.0.0.0-255.255.255.255", "classes": "[Reserved Bogon]"},
+		{"addr": "10.0.0.0/8", "classes": "[Private Bogon]"},
+		{"addr": "2001:db8::/31", "classes": "[]"},
+		{"addr": "9.255.255.255-10.0.0.0", "classes": "[]"},
+		{"addr": "1.0.0.0/8", "classes": "[Global]"},
+	}
+
+	for _, tc := range testCases {
+		addr := tc["addr"].(string)
+		var classes []AddressClass
+		if r, err := NewIPRange(addr); err == nil && r.First().String() != r.Last().String() {
+			classes = r.Classes()
+		} else if n, err := ParseIPNet(addr); err == nil {
+			classes = n.Classes()
+		} else {
+			t.Fatalf("invalid addr %s", addr)
+		}
+		if got := fmt.Sprint(classes); got != tc["classes"] {
+			t.Errorf("Classes(%s) = %s, want = %s", addr, got, tc["classes"])
+		}
+	}
+}
+
+func TestIsGlobal(t *testing.T) {
+	ng, _ := NewNetworkGroupFromString("2002::1,64:ff9b::1,2001:db8::1,8.8.8.8,10.0.0.1")
+	global, err := ng.ClassSubset(CLASS_GLOBAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"2002::1", "64:ff9b::1", "2001:db8::1", "8.8.8.8", "10.0.0.1"} {
+		ip, _ := ParseIP(s)
+		n, _ := ParseIPNet(s)
+		inSubset := global.Match(n)
+		if ip.IsGlobal() != ip.IsClass(CLASS_GLOBAL) || ip.IsGlobal() != inSubset || ip.IsGlobal() == ip.IsBogon() {
+			t.Errorf("%s, IsGlobal = %v, IsClass(Global) = %v, in ClassSubset(Global) = %v, IsBogon = %v",
+				s, ip.IsGlobal(), ip.IsClass(CLASS_GLOBAL), inSubset, ip.IsBogon())
+		}
+	}
+}
+
+func TestMulticastScope(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"addr": "8.8.8.8", "scope": SCOPE_NONE},
+		{"addr": "224.0.0.1", "scope": SCOPE_LINK_LOCAL},
+		{"addr": "239.255.0.1", "scope": SCOPE_SITE_LOCAL},
+		{"addr": "239.192.0.1", "scope": SCOPE_ORGANIZATION_LOCAL},
+		{"addr": "239.1.1.1", "scope": SCOPE_ADMIN_LOCAL},
+		{"addr": "233.1.1.1", "scope": SCOPE_GLOBAL},
+		{"addr": "2001:db8::1", "scope": SCOPE_NONE},
+		{"addr": "ff00::1", "scope": SCOPE_RESERVED},
+		{"addr": "ff01::1", "scope": SCOPE_INTERFACE_LOCAL},
+		{"addr": "ff02::1", "scope": SCOPE_LINK_LOCAL},
+		{"addr": "ff03::1", "scope": SCOPE_REALM_LOCAL},
+		{"addr": "ff04::1", "scope": SCOPE_ADMIN_LOCAL},
+		{"addr": "ff05::1", "scope": SCOPE_SITE_LOCAL},
+		{"addr": "ff06::1", "scope": SCOPE_UNASSIGNED},
+		{"addr": "ff08::1", "scope": SCOPE_ORGANIZATION_LOCAL},
+		{"addr": "ff0e::1", "scope": SCOPE_GLOBAL},
+		{"addr": "ff1e::1", "scope": SCOPE_GLOBAL},
+		{"addr": "ff0f::1", "scope": SCOPE_RESERVED},
+	}
+
+	for _, tc := range testCases {
+		ip, _ := ParseIP(tc["addr"].(string))
+		if got := ip.MulticastScope(); got != tc["scope"] {
+			t.Errorf("%s, scope = %s, want = %s", tc["addr"], got, tc["scope"])
+		}
+	}
+}
+
+// ClassSubset与classify使用相同的合并后的地址段
+func TestClassSubsetConsistent(t *testing.T) {
+	ng, _ := NewNetworkGroupFromString("1.0.0.0/8,10.0.0.0/8,224.0.0.0/3,2001::/16,fe80::/9")
+	for c := CLASS_GLOBAL; c <= CLASS_BOGON; c++ {
+		sub, err := ng.ClassSubset(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, nl := range []*NetworkList{sub.IPv4(), sub.IPv6()} {
+			for _, n := range nl.List() {
+				if !hasClass(classify(n.First(), n.Last()), c) {
+					t.Errorf("%s in ClassSubset(%s), Classes = %v", n, c, classify(n.First(), n.Last()))
+				}
+			}
+		}
+	}
+}
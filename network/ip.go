@@ -1,15 +1,15 @@
 package network
 
 import (
+	"bytes"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
-	"net"
 	"strconv"
-	"strings"
+	"tools/utils"
 	"tools/validator"
 )
 
@@ -548,19 +548,24 @@ func (ip *IP) Int() *big.Int {
 	return z
 }
 
+// Uint128 返回ip的定长整数表示，内部地址运算优先使用该表示，避免分配big.Int
+func (ip *IP) Uint128() utils.Uint128 {
+	return utils.Uint128FromBytes(*ip)
+}
+
 func (ip *IP) Equal(o IP) bool {
 	if ip.Type() != o.Type() {
 		return false
 	}
 
-	if ip.Int().Cmp(o.Int()) == 0 {
-		return true
-	}
-
-	return false
+	return bytes.Equal(*ip, o)
 }
 
 func (ip *IP) Add(i *big.Int) (*IP, error) {
+	if i.IsInt64() {
+		return ip.AddInt64(i.Int64())
+	}
+
 	high := IPMaxInt(ip.Type())
 
 	add := new(big.Int)
@@ -575,53 +580,60 @@ func (ip *IP) Add(i *big.Int) (*IP, error) {
 	return NewIPFromInt(add, ip.Type()), nil
 }
 
+func (ip *IP) AddInt64(i int64) (*IP, error) {
+	var r utils.Uint128
+	var overflow bool
+	if i >= 0 {
+		r, overflow = ip.Uint128().AddUint64(uint64(i))
+	} else {
+		r, overflow = ip.Uint128().SubUint64(uint64(-i))
+		if overflow {
+			return nil, fmt.Errorf("%s + %d < %d", ip, i, 0)
+		}
+	}
+
+	if overflow || r.Cmp(ipMaxUint128(ip.Type())) > 0 {
+		return nil, fmt.Errorf("%s + %d > %d", ip, i, IPMaxInt(ip.Type()))
+	}
+
+	return NewIPFromUint128(r, ip.Type()), nil
+}
+
 func NewIPFromInt(i *big.Int, fa IPFamily) *IP {
+	u, ok := utils.Uint128FromBig(i)
+	if !ok {
+		return nil
+	}
+
+	return NewIPFromUint128(u, fa)
+}
+
+func NewIPFromUint128(u utils.Uint128, fa IPFamily) *IP {
 	var ip IP
 	if fa == IPv4 {
 		ip = make(IP, IPv4Len)
 	} else if fa == IPv6 {
 		ip = make(IP, IPv6Len)
-	}
-	high := IPMaxInt(fa)
-	low := big.NewInt(0)
-	if i.Cmp(low) < 0 || i.Cmp(high) > 0 {
+	} else {
 		return nil
 	}
 
-	tmp := i.Bytes()
-	last := len(ip) - 1
-	for index := len(tmp) - 1; index >= 0; index-- {
-		ip[last] = tmp[index]
-		last--
+	if u.Cmp(ipMaxUint128(fa)) > 0 {
+		return nil
 	}
 
+	u.PutBytes(ip)
 	return &ip
-
 }
 
+// ParseIP 使用validator.ParseIPAddress解析，失败时返回具体的原因
 func ParseIP(s string) (*IP, error) {
-	if validator.IsIPv4Address(s) {
-		ip := make(IP, IPv4Len)
-		tokens := strings.Split(s, ".")
-
-		for index, t := range tokens {
-			i, err := strconv.Atoi(t)
-			if err != nil {
-				return nil, err
-			}
-			ip[index] = byte(i)
-		}
-		return &ip, nil
-	} else if validator.IsIPv6Address(s) {
-		ip := make(IP, IPv6Len)
-		ip6 := net.ParseIP(s)
-		copy(ip, ip6)
-		return &ip, nil
-
-	} else {
-		return nil, fmt.Errorf("s:'%s' is not valid ip string", s)
+	b, err := validator.ParseIPAddress(s)
+	if err != nil {
+		return nil, err
 	}
-
+	ip := IP(b)
+	return &ip, nil
 }
 
 func MasktoIP(m IPMask) IP {
@@ -637,17 +649,12 @@ func IPtoMask(ip *IP) (*IPMask, error) {
 }
 
 func IPMaxInt(fa IPFamily) *big.Int {
-	var ip IP
+	return ipMaxUint128(fa).Big()
+}
+
+func ipMaxUint128(fa IPFamily) utils.Uint128 {
 	if fa == IPv4 {
-		ip = make(IP, IPv4Len)
-	} else {
-		ip = make(IP, IPv6Len)
+		return utils.Mask128(32)
 	}
-	for index, _ := range ip {
-		ip[index] = 0xFF
-	}
-
-	i := new(big.Int)
-	i.SetBytes(ip)
-	return i
+	return utils.MaxUint128
 }
@@ -0,0 +1,210 @@
+package network
+
+import (
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"tools/validator"
+)
+
+var cidrsTestList = []map[string]interface{}{
+	{
+		"range": "10.0.0.1-10.0.0.6",
+		"want":  "10.0.0.1/32,10.0.0.2/31,10.0.0.4/31,10.0.0.6/32",
+	},
+	{
+		"range": "0.0.0.0-255.255.255.255",
+		"want":  "0.0.0.0/0",
+	},
+	{
+		"range": "255.255.255.254-255.255.255.255",
+		"want":  "255.255.255.254/31",
+	},
+	{
+		"range": "::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+		"want":  "::/0",
+	},
+	{
+		"range": "2001:db8::-2001:db8::ffff:ffff:ffff:ffff",
+		"want":  "2001:db8::/64",
+	},
+	{
+		"range": "::1-::6",
+		"want":  "::1/128,::2/127,::4/127,::6/128",
+	},
+}
+
+func TestCIDRs(t *testing.T) {
+	for _, data := range cidrsTestList {
+		r, err := NewIPRange(data["range"].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		list := []string{}
+		for _, n := range r.CIDRs() {
+			list = append(list, n.String())
+		}
+		if got := strings.Join(list, ","); got != data["want"] {
+			t.Errorf("Test %+v, got = %s, want = %s", data, got, data["want"])
+		}
+	}
+}
+
+// 步长超过2^63时，math.Pow转换为int64会溢出
+func TestCIDRsLargeIPv6(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"range": "2001:db8::-2001:db8:0:1:ffff:ffff:ffff:ffff", "count": 1},
+		{"range": "::-7fff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "count": 1},
+		{"range": "2001:db8::1-2001:db8::ffff:ffff:ffff:ffff", "count": 64},
+		{"range": "::1-ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "count": 254},
+	}
+
+	for _, tc := range testCases {
+		r, err := NewIPRange(tc["range"].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		list := r.CIDRs()
+		if len(list) != tc["count"].(int) {
+			t.Errorf("CIDRs(%s), len = %d, want = %d", tc["range"], len(list), tc["count"])
+			continue
+		}
+		// 按顺序首尾相接，且覆盖整个范围
+		next := r.First().Int()
+		for _, n := range list {
+			if n.First().Int().Cmp(next) != 0 {
+				t.Errorf("CIDRs(%s), %s does not start at %s", tc["range"], n, NewIPFromInt(next, IPv6))
+				break
+			}
+			next = new(big.Int).Add(n.Last().Int(), big.NewInt(1))
+		}
+		if last := new(big.Int).Sub(next, big.NewInt(1)); last.Cmp(r.Last().Int()) != 0 {
+			t.Errorf("CIDRs(%s), last = %s", tc["range"], NewIPFromInt(last, IPv6))
+		}
+	}
+}
+
+func TestIPAdd(t *testing.T) {
+	ip, _ := ParseIP("255.255.255.254")
+	if n, err := ip.Add(big.NewInt(1)); err != nil || n.String() != "255.255.255.255" {
+		t.Errorf("got = %s, err = %v", n, err)
+	}
+	if _, err := ip.Add(big.NewInt(2)); err == nil {
+		t.Errorf("want overflow error")
+	}
+
+	ip6, _ := ParseIP("::1")
+	if n, err := ip6.Add(big.NewInt(-1)); err != nil || n.String() != "::" {
+		t.Errorf("got = %s, err = %v", n, err)
+	}
+	if _, err := ip6.Add(big.NewInt(-2)); err == nil {
+		t.Errorf("want underflow error")
+	}
+}
+
+// ParseIP与net.ParseIP、validator中的校验一致
+func FuzzParseIP(f *testing.F) {
+	for _, s := range []string{"1.2.3.4", "0.0.0.0", "1.2.3.04", "::", "::ffff:1.2.3.4", "2001:db8::1", "fe80::1%eth0", "1:2:3:4:5:6:7:8:9"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		ip, err := ParseIP(s)
+		want := net.ParseIP(s)
+		if (err == nil) != (want != nil) {
+			t.Fatalf("ParseIP(%q), err = %v, net.ParseIP = %v", s, err, want)
+		}
+		if err != nil {
+			return
+		}
+		if !net.IP(*ip).Equal(want) {
+			t.Fatalf("ParseIP(%q) = %s, net.ParseIP = %s", s, ip, want)
+		}
+		if (ip.Type() == IPv4) != validator.IsIPv4Address(s) || (ip.Type() == IPv6) != validator.IsIPv6Address(s) {
+			t.Fatalf("ParseIP(%q).Type() = %s", s, ip.Type())
+		}
+	})
+}
+
+func BenchmarkParseIP(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ParseIP("192.168.100.200")
+		ParseIP("2001:db8:3:4::192.0.2.33")
+	}
+}
+
+func BenchmarkIPInt(b *testing.B) {
+	ip, _ := ParseIP("2001:db8::1")
+	for i := 0; i < b.N; i++ {
+		ip.Int()
+	}
+}
+
+func BenchmarkIPUint128(b *testing.B) {
+	ip, _ := ParseIP("2001:db8::1")
+	for i := 0; i < b.N; i++ {
+		ip.Uint128()
+	}
+}
+
+func BenchmarkIPAdd(b *testing.B) {
+	ip, _ := ParseIP("2001:db8::1")
+	one := big.NewInt(1)
+	for i := 0; i < b.N; i++ {
+		ip.Add(one)
+	}
+}
+
+func BenchmarkNewIPFromInt(b *testing.B) {
+	ip, _ := ParseIP("2001:db8::1")
+	v := ip.Int()
+	for i := 0; i < b.N; i++ {
+		NewIPFromInt(v, IPv6)
+	}
+}
+
+func BenchmarkIPNetCount(b *testing.B) {
+	n, _ := ParseIPNet("2001:db8::/64")
+	for i := 0; i < b.N; i++ {
+		n.Count()
+	}
+}
+
+func BenchmarkIPRangeCIDRs(b *testing.B) {
+	r, _ := NewIPRange("10.0.0.1-10.255.255.254")
+	for i := 0; i < b.N; i++ {
+		r.CIDRs()
+	}
+}
+
+func BenchmarkIPRangeIterator(b *testing.B) {
+	r, _ := NewIPRange("10.0.0.0-10.0.255.255")
+	for i := 0; i < b.N; i++ {
+		for it := r.Iterator(); it.HasNext(); {
+			it.Next()
+		}
+	}
+}
+
+// network中的类型作为InSubnet的字段值
+func TestInSubnetNetworkTypes(t *testing.T) {
+	subnet, _ := ParseIPNet("10.0.0.0/8")
+	ip, _ := ParseIP("10.1.1.1")
+	other, _ := ParseIP("11.1.1.1")
+
+	testCases := []map[string]interface{}{
+		{"ip": "10.1.1.1", "subnet": *subnet, "want": true},
+		{"ip": "10.1.1.1", "subnet": subnet, "want": true},
+		{"ip": *ip, "subnet": *subnet, "want": true},
+		{"ip": other, "subnet": *subnet, "want": false},
+	}
+
+	v := validator.InSubnet("ip", "subnet")
+	for _, tc := range testCases {
+		if r := v.Validate(tc); r.Status() != tc["want"] {
+			t.Errorf("InSubnet(%v, %v), status = %v, msg = %s", tc["ip"], tc["subnet"], r.Status(), r.Msg())
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
 	"tools/flexrange"
@@ -262,8 +263,22 @@ func ParseIPNet(s string) (*IPNet, error) {
 	} else if validator.IsIPv4AddressWithMask(s) || validator.IsIPv6AddressWithMask(s) {
 		return parseIPNet(s)
 	} else {
-		return nil, fmt.Errorf("s:%s format error", s)
+		return nil, ipNetError(s)
+	}
+}
+
+// ipNetError 返回s无法解析的具体原因
+func ipNetError(s string) error {
+	var err error
+	if strings.Contains(s, "/") {
+		_, _, err = validator.ParseCIDR(s, false)
+	} else {
+		_, err = validator.ParseIPAddress(s)
+	}
+	if err == nil {
+		err = fmt.Errorf("s:%s format error", s)
 	}
+	return err
 }
 
 func parseIPNet(s string) (*IPNet, error) {
@@ -312,9 +327,6 @@ func parseIPNet(s string) (*IPNet, error) {
 			Mask: *_mask,
 		}, nil
 	}
-
-	return nil, fmt.Errorf("unknown error")
-
 }
 
 func (n *IPNet) IPNetList() ([]*IPNet, error) {
@@ -323,6 +335,7 @@ func (n *IPNet) IPNetList() ([]*IPNet, error) {
 	return result, nil
 }
 
+// DataRange First()和Last()按照n.Size()计算，不会反向也不会超出范围，Push的错误可以忽略
 func (n *IPNet) DataRange() flexrange.DataRangeInf {
 	dr := flexrange.NewDataRange(uint32(n.Size()), big.NewInt(0))
 	if n.Mask.Prefix() != -1 {
@@ -374,3 +387,219 @@ func (net *IPNet) AllIPToString() []string {
 	}
 	return ips
 }
+
+func (n *IPNet) contiguous() (*IPNet, error) {
+	prefix := n.Mask.Prefix()
+	if prefix == -1 {
+		return nil, fmt.Errorf("not support non-contiguous mask, net: %s", n)
+	}
+
+	return &IPNet{
+		IP:   n.IP.AfterMask(&n.Mask),
+		Mask: *n.Mask.Copy(),
+	}, nil
+}
+
+func newIPNetFromInt(i *big.Int, prefix int, fa IPFamily) (*IPNet, error) {
+	ip := NewIPFromInt(i, fa)
+	if ip == nil {
+		return nil, fmt.Errorf("%d out of range", i)
+	}
+	mask, err := NewIPMask(uint(prefix), fa)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IPNet{
+		IP:   *ip,
+		Mask: *mask,
+	}, nil
+}
+
+type IPNetSubnetIterator struct {
+	fa     IPFamily
+	prefix int
+	start  *big.Int
+	step   *big.Int
+	count  *big.Int
+	index  *big.Int
+}
+
+// Subnets 按照newPrefix对n进行切分，返回惰性迭代器，只在Next()时计算下一个子网
+func (n *IPNet) Subnets(newPrefix int) (*IPNetSubnetIterator, error) {
+	net, err := n.contiguous()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := net.Prefix()
+	if newPrefix < prefix || newPrefix > net.Size() {
+		return nil, fmt.Errorf("newPrefix: %d, prefix: %d, size: %d", newPrefix, prefix, net.Size())
+	}
+
+	step := new(big.Int).Lsh(big.NewInt(1), uint(net.Size()-newPrefix))
+	count := new(big.Int).Lsh(big.NewInt(1), uint(newPrefix-prefix))
+
+	return &IPNetSubnetIterator{
+		fa:     net.Type(),
+		prefix: newPrefix,
+		start:  net.First().Int(),
+		step:   step,
+		count:  count,
+		index:  big.NewInt(0),
+	}, nil
+}
+
+func (it *IPNetSubnetIterator) Count() *big.Int {
+	return utils.CopyInt(it.count)
+}
+
+func (it *IPNetSubnetIterator) HasNext() bool {
+	return it.index.Cmp(it.count) < 0
+}
+
+func (it *IPNetSubnetIterator) Next() (*IPNet, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more subnets, count: %s", it.count)
+	}
+
+	i := new(big.Int).Mul(it.step, it.index)
+	i = i.Add(i, it.start)
+	it.index = utils.AddInt(it.index, 1)
+
+	return newIPNetFromInt(i, it.prefix, it.fa)
+}
+
+// Next 返回与n相同掩码长度的下一个相邻网段
+func (n *IPNet) Next() (*IPNet, error) {
+	net, err := n.contiguous()
+	if err != nil {
+		return nil, err
+	}
+
+	i := utils.AddInt(net.Last().Int(), 1)
+	if i.Cmp(IPMaxInt(net.Type())) > 0 {
+		return nil, fmt.Errorf("%s is the last network", net)
+	}
+
+	return newIPNetFromInt(i, net.Prefix(), net.Type())
+}
+
+// Previous 返回与n相同掩码长度的上一个相邻网段
+func (n *IPNet) Previous() (*IPNet, error) {
+	net, err := n.contiguous()
+	if err != nil {
+		return nil, err
+	}
+
+	first := net.First().Int()
+	if first.Sign() == 0 {
+		return nil, fmt.Errorf("%s is the first network", net)
+	}
+
+	i := new(big.Int).Sub(first, net.Count())
+	return newIPNetFromInt(i, net.Prefix(), net.Type())
+}
+
+// Parent 返回向上第level级的父网段，Parent(1)即掩码长度减1的网段
+func (n *IPNet) Parent(level int) (*IPNet, error) {
+	net, err := n.contiguous()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := net.Prefix() - level
+	if level < 0 || prefix < 0 {
+		return nil, fmt.Errorf("level: %d, prefix: %d", level, net.Prefix())
+	}
+
+	mask, err := NewIPMask(uint(prefix), net.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	return &IPNet{
+		IP:   net.IP.AfterMask(mask),
+		Mask: *mask,
+	}, nil
+}
+
+// IsSiblingOf 判断n与other是否为同一父网段切分出来的两个不同子网
+func (n *IPNet) IsSiblingOf(other *IPNet) bool {
+	if n.Type() != other.Type() {
+		return false
+	}
+
+	if n.Prefix() == -1 || n.Prefix() == 0 || n.Prefix() != other.Prefix() {
+		return false
+	}
+
+	if bytes.Equal(*n.First(), *other.First()) {
+		return false
+	}
+
+	p1, err := n.Parent(1)
+	if err != nil {
+		return false
+	}
+	p2, err := other.Parent(1)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(p1.IP, p2.IP)
+}
+
+// Exclude 从n中排除other，返回剩余部分的CIDR列表，按地址从小到大排列
+func (n *IPNet) Exclude(other *IPNet) ([]*IPNet, error) {
+	net, err := n.contiguous()
+	if err != nil {
+		return nil, err
+	}
+	o, err := other.contiguous()
+	if err != nil {
+		return nil, err
+	}
+
+	if net.Type() != o.Type() {
+		return nil, fmt.Errorf("net: %s, other: %s, type is different", net, o)
+	}
+
+	result := []*IPNet{}
+	if bytes.Compare(*o.Last(), *net.First()) < 0 || bytes.Compare(*o.First(), *net.Last()) > 0 {
+		return append(result, net), nil
+	}
+
+	if o.MatchIPNet(net) {
+		return result, nil
+	}
+
+	for net.Prefix() < o.Prefix() {
+		it, err := net.Subnets(net.Prefix() + 1)
+		if err != nil {
+			return nil, err
+		}
+		low, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		high, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if low.MatchIPNet(o) {
+			result = append(result, high)
+			net = low
+		} else {
+			result = append(result, low)
+			net = high
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return bytes.Compare(result[i].IP, result[j].IP) < 0
+	})
+
+	return result, nil
+}
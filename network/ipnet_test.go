@@ -0,0 +1,170 @@
+package network
+
+import (
+	"strings"
+	"testing"
+)
+
+func ipNetList(l []*IPNet) string {
+	s := []string{}
+	for _, n := range l {
+		s = append(s, n.String())
+	}
+	return strings.Join(s, ",")
+}
+
+func TestIPNetSubnets(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"net": "10.0.0.0/24", "prefix": 26, "want": "10.0.0.0/26,10.0.0.64/26,10.0.0.128/26,10.0.0.192/26"},
+		{"net": "10.0.0.1/24", "prefix": 25, "want": "10.0.0.0/25,10.0.0.128/25"},
+		{"net": "1.1.1.1/32", "prefix": 32, "want": "1.1.1.1/32"},
+		{"net": "255.255.255.254/31", "prefix": 32, "want": "255.255.255.254/32,255.255.255.255/32"},
+		{"net": "::1/128", "prefix": 128, "want": "::1/128"},
+		{"net": "2001:db8::/32", "prefix": 34, "want": "2001:db8::/34,2001:db8:4000::/34,2001:db8:8000::/34,2001:db8:c000::/34"},
+		{"net": "1.1.1.1/32", "prefix": 33, "err": true},
+		{"net": "::1/128", "prefix": 129, "err": true},
+		{"net": "10.0.0.0/24", "prefix": 23, "err": true},
+	}
+
+	for _, tc := range testCases {
+		n, _ := ParseIPNet(tc["net"].(string))
+		it, err := n.Subnets(tc["prefix"].(int))
+		if tc["err"] != nil {
+			if err == nil {
+				t.Errorf("Subnets(%s, %d), want error", tc["net"], tc["prefix"])
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Subnets(%s, %d), err = %v", tc["net"], tc["prefix"], err)
+		}
+
+		list := []*IPNet{}
+		for it.HasNext() {
+			sub, err := it.Next()
+			if err != nil {
+				t.Fatalf("Subnets(%s, %d).Next(), err = %v", tc["net"], tc["prefix"], err)
+			}
+			list = append(list, sub)
+		}
+		if got := ipNetList(list); got != tc["want"] || it.Count().Int64() != int64(len(list)) {
+			t.Errorf("Subnets(%s, %d) = %s, count = %s, want = %s", tc["net"], tc["prefix"], got, it.Count(), tc["want"])
+		}
+		if _, err := it.Next(); err == nil {
+			t.Errorf("Subnets(%s, %d).Next() after the last one, want error", tc["net"], tc["prefix"])
+		}
+	}
+}
+
+func TestIPNetNavigation(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"op": "next", "net": "10.0.0.0/24", "want": "10.0.1.0/24"},
+		{"op": "next", "net": "1.1.1.1/32", "want": "1.1.1.2/32"},
+		{"op": "next", "net": "255.255.255.255/32"},
+		{"op": "next", "net": "255.255.255.0/24"},
+		{"op": "next", "net": "0.0.0.0/0"},
+		{"op": "next", "net": "::/128", "want": "::1/128"},
+		{"op": "next", "net": "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff/128"},
+		{"op": "previous", "net": "10.0.1.0/24", "want": "10.0.0.0/24"},
+		{"op": "previous", "net": "255.255.255.255/32", "want": "255.255.255.254/32"},
+		{"op": "previous", "net": "0.0.0.0/32"},
+		{"op": "previous", "net": "0.0.0.0/8"},
+		{"op": "previous", "net": "0.0.0.0/0"},
+		{"op": "previous", "net": "::/128"},
+		{"op": "previous", "net": "2001:db8:1::/48", "want": "2001:db8::/48"},
+		{"op": "parent", "net": "10.1.2.3/32", "level": 1, "want": "10.1.2.2/31"},
+		{"op": "parent", "net": "10.0.0.0/8", "level": 8, "want": "0.0.0.0/0"},
+		{"op": "parent", "net": "10.0.0.0/8", "level": 0, "want": "10.0.0.0/8"},
+		{"op": "parent", "net": "::1/128", "level": 128, "want": "::/0"},
+		{"op": "parent", "net": "0.0.0.0/0", "level": 1},
+		{"op": "parent", "net": "::/0", "level": 1},
+		{"op": "parent", "net": "10.0.0.0/8", "level": -1},
+	}
+
+	for _, tc := range testCases {
+		n, _ := ParseIPNet(tc["net"].(string))
+		var got *IPNet
+		var err error
+		switch tc["op"] {
+		case "next":
+			got, err = n.Next()
+		case "previous":
+			got, err = n.Previous()
+		case "parent":
+			got, err = n.Parent(tc["level"].(int))
+		}
+
+		if tc["want"] == nil {
+			if err == nil {
+				t.Errorf("%s(%s) = %s, want error", tc["op"], tc["net"], got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tc["want"] {
+			t.Errorf("%s(%s) = %v, err = %v, want = %s", tc["op"], tc["net"], got, err, tc["want"])
+		}
+	}
+}
+
+func TestIPNetIsSiblingOf(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"a": "10.0.0.0/25", "b": "10.0.0.128/25", "want": true},
+		{"a": "10.0.0.128/25", "b": "10.0.0.0/25", "want": true},
+		{"a": "1.1.1.0/32", "b": "1.1.1.1/32", "want": true},
+		{"a": "1.1.1.1/32", "b": "1.1.1.2/32", "want": false},
+		{"a": "10.0.0.0/25", "b": "10.0.0.0/25", "want": false},
+		{"a": "10.0.0.128/25", "b": "10.0.1.0/25", "want": false},
+		{"a": "10.0.0.0/25", "b": "10.0.0.128/26", "want": false},
+		{"a": "0.0.0.0/0", "b": "0.0.0.0/0", "want": false},
+		{"a": "::/128", "b": "::1/128", "want": true},
+		{"a": "::/1", "b": "8000::/1", "want": true},
+		{"a": "0.0.0.0/32", "b": "::1/128", "want": false},
+	}
+
+	for _, tc := range testCases {
+		a, _ := ParseIPNet(tc["a"].(string))
+		b, _ := ParseIPNet(tc["b"].(string))
+		if got := a.IsSiblingOf(b); got != tc["want"] {
+			t.Errorf("%s.IsSiblingOf(%s) = %v, want = %v", tc["a"], tc["b"], got, tc["want"])
+		}
+	}
+}
+
+func TestIPNetExclude(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"net": "10.0.0.0/24", "other": "10.0.0.0/24", "want": ""},
+		{"net": "10.0.0.0/24", "other": "10.0.0.0/8", "want": ""},
+		{"net": "0.0.0.0/0", "other": "0.0.0.0/0", "want": ""},
+		{"net": "10.0.0.0/24", "other": "11.0.0.0/8", "want": "10.0.0.0/24"},
+		{"net": "10.0.0.0/24", "other": "10.0.1.0/24", "want": "10.0.0.0/24"},
+		{"net": "10.0.0.0/24", "other": "10.0.0.0/26", "want": "10.0.0.64/26,10.0.0.128/25"},
+		{"net": "10.0.0.0/24", "other": "10.0.0.255/32", "want": "10.0.0.0/25,10.0.0.128/26,10.0.0.192/27,10.0.0.224/28," +
+			"10.0.0.240/29,10.0.0.248/30,10.0.0.252/31,10.0.0.254/32"},
+		{"net": "1.1.1.0/31", "other": "1.1.1.1/32", "want": "1.1.1.0/32"},
+		{"net": "0.0.0.0/0", "other": "128.0.0.0/1", "want": "0.0.0.0/1"},
+		{"net": "2001:db8::/126", "other": "2001:db8::2/128", "want": "2001:db8::/127,2001:db8::3/128"},
+		{"net": "2001:db8::/32", "other": "2001:db9::/32", "want": "2001:db8::/32"},
+		{"net": "10.0.0.0/24", "other": "::/0", "err": true},
+	}
+
+	for _, tc := range testCases {
+		n, _ := ParseIPNet(tc["net"].(string))
+		other, _ := ParseIPNet(tc["other"].(string))
+		got, err := n.Exclude(other)
+		if tc["err"] != nil {
+			if err == nil {
+				t.Errorf("%s.Exclude(%s), want error", tc["net"], tc["other"])
+			}
+			continue
+		}
+		if err != nil || ipNetList(got) != tc["want"] {
+			t.Errorf("%s.Exclude(%s) = %s, err = %v, want = %s", tc["net"], tc["other"], ipNetList(got), err, tc["want"])
+		}
+	}
+
+	// 不连续的掩码
+	n := &IPNet{IP: IP{10, 0, 0, 0}, Mask: IPMask{255, 0, 255, 0}}
+	if _, err := n.Subnets(24); err == nil {
+		t.Errorf("Subnets of non-contiguous mask, want error")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
 	"math/big"
 	"strings"
 	"tools/flexrange"
@@ -77,7 +76,7 @@ func (IPRange) GormDataType() string {
 }
 
 func (r *IPRange) AddressType() AddressType {
-	if r.Start.Int().Cmp(r.End.Int()) == 0 {
+	if bytes.Equal(r.Start, r.End) {
 		return HOST
 	}
 
@@ -198,109 +197,42 @@ func (r *IPRange) MatchIPRange(n *IPRange) bool {
 }
 
 func (r *IPRange) IPNetList() (result []*IPNet, err error) {
-	var mask *IPMask
-	mask, err = NewIPMask(uint(r.Start.Size()), r.Start.Type())
-	if err != nil {
-		return
+	if bytes.Compare(r.Start, r.End) > 0 {
+		return nil, fmt.Errorf("start: %s > end: %s", r.Start, r.End)
 	}
-
-	//net := &IPNet{r.Start, NewIPMask(uint(r.Start.Size()), r.Start.Type())}
-	net := &IPNet{IP: r.Start, Mask: *mask}
-
-	count := new(big.Int)
-	count = count.Sub(r.End.Int(), r.Start.Int())
-	count = count.Add(count, big.NewInt(1))
-	zero := big.NewInt(0)
-
-	nr := &IPRange{
-		r.Start,
-		r.End,
-	}
-
-	result = []*IPNet{}
-
-	tmp := net
-	test_count := new(big.Int)
-	test_count.Set(count)
-	for test_count.Cmp(zero) > 0 || test_count.Cmp(zero) == 0 {
-		if nr.MatchIPNet(net) {
-			test_count = test_count.Sub(count, net.Count())
-			tmp = net.Copy().(*IPNet)
-			if net.MatchIPRange(nr) {
-				result = append(result, tmp)
-				return
-			}
-			net, err = net.SuperNet()
-			if err != nil {
-				return
-			}
-
-		} else {
-			result = append(result, tmp)
-			nr, err = nr.StartShift(tmp.Count())
-			if err != nil {
-				return
-			}
-
-			count = count.Sub(nr.End.Int(), nr.Start.Int())
-			count = count.Add(count, big.NewInt(1))
-			test_count.Set(count)
-			mask, err = NewIPMask(uint(nr.Start.Size()), nr.Start.Type())
-			if err != nil {
-				return
-			}
-			//net = &IPNet{nr.Start, NewIPMask(uint(nr.Start.Size()), nr.Start.Type())}
-			net = &IPNet{IP: nr.Start, Mask: *mask}
-		}
-
-	}
-	return
+	return r.CIDRs(), nil
 }
 
+// CIDRs 将r拆分为最少数量的CIDR，使用定长整数计算，避免在循环中分配big.Int
 func (r *IPRange) CIDRs() []*IPNet {
-	start := r.First().Int()
-	end := r.Last().Int()
-	var max int
-	if r.Type() == IPv4 {
-		max = 32
-	} else {
-		max = 128
-	}
+	fa := r.Type()
+	max := r.Size()
+	start := r.Start.Uint128()
+	end := r.End.Uint128()
 
-	var prev, current *IPNet
 	var result []*IPNet
-	for i := start; i.Cmp(end) <= 0; {
-		ip := NewIPFromInt(i, r.Type())
-		mask, _ := NewIPMask(uint(max), r.Type())
-		prev = &IPNet{
-			IP:   *ip,
-			Mask: *mask,
+	for start.Cmp(end) <= 0 {
+		step := start.TrailingZeros()
+		if step > max {
+			step = max
 		}
-		for m := max - 1; m >= 0; m-- {
-			mask, _ := NewIPMask(uint(m), r.Type())
-			current = &IPNet{
-				IP:   *ip,
-				Mask: *mask,
-			}
 
-			step := max - m - 1
-			nr := NewIPRangeFromInt(i, end, r.Type())
+		remain, _ := end.Sub(start)
+		for step > 0 && remain.Cmp(utils.Mask128(uint(step))) < 0 {
+			step--
+		}
 
-			if !nr.MatchIPNet(current) {
-				result = append(result, prev)
-				i = i.Add(i, big.NewInt(int64(math.Pow(2, float64(step)))))
-				break
-			}
-			if m == 0 {
-				result = append(result, current)
-				i = i.Add(i, big.NewInt(int64(math.Pow(2, float64(step+1)))))
-				break
-			}
-			prev = current
+		mask, _ := NewIPMask(uint(max-step), fa)
+		result = append(result, &IPNet{
+			IP:   *NewIPFromUint128(start, fa),
+			Mask: *mask,
+		})
 
-			// if
+		next, overflow := start.Add(utils.NewUint128(1).Lsh(uint(step)))
+		if overflow || next.IsZero() {
+			break
 		}
-
+		start = next
 	}
 
 	return result
@@ -341,6 +273,8 @@ func (r *IPRange) SuperNet() (*IPNet, error) {
 
 }
 
+// DataRange NewIPRange以及UnmarshalBinary保证Start不大于End，直接构造的反向IPRange返回空的DataRange，
+// 需要区分错误时使用AddressTable.Match等返回error的方法
 func (r *IPRange) DataRange() flexrange.DataRangeInf {
 	dr := flexrange.NewDataRange(uint32(r.Size()), big.NewInt(0))
 	low := r.First().Int()
@@ -351,39 +285,35 @@ func (r *IPRange) DataRange() flexrange.DataRangeInf {
 }
 
 type IPRangeIterator struct {
-	start *IP
-	end   *IP
-	index *big.Int
+	fa   IPFamily
+	cur  utils.Uint128
+	end  utils.Uint128
+	done bool
 }
 
 func (ir *IPRange) Iterator() *IPRangeIterator {
-
 	return &IPRangeIterator{
-		&ir.Start,
-		&ir.End,
-		big.NewInt(0),
+		fa:   ir.Type(),
+		cur:  ir.Start.Uint128(),
+		end:  ir.End.Uint128(),
+		done: bytes.Compare(ir.Start, ir.End) > 0,
 	}
 }
 
 func (it *IPRangeIterator) HasNext() bool {
-	s1 := it.start.Int()
-	s2 := it.end.Int()
-
-	if s1.Add(s1, it.index); !(s1.Cmp(s2) > 0) {
-		return true
-	}
-	return false
+	return !it.done
 }
 
 func (it *IPRangeIterator) Next() *IP {
-	s1 := it.start.Int()
-	s2 := it.end.Int()
-
-	r := s1.Add(s1, it.index)
-	if r.Cmp(s2) > 0 {
+	if it.done {
 		return nil
 	}
-	it.index = utils.AddInt(it.index, 1)
-	return NewIPFromInt(r, it.start.Type())
 
+	ip := NewIPFromUint128(it.cur, it.fa)
+	if it.cur.Equal(it.end) {
+		it.done = true
+	} else {
+		it.cur, _ = it.cur.AddUint64(1)
+	}
+	return ip
 }
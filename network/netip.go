@@ -0,0 +1,276 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// IP与标准库net/netip、net之间的转换
+//
+// netip.Addr区分IPv4与IPv4-mapped IPv6地址(::ffff:a.b.c.d)，默认保持原样，只有在unmap为true时才转换为IPv4；
+// net.IP中的IPv4通常也是16字节的形式(net.ParseIP总是返回16字节)，所以从net.IP转换时默认转换为IPv4，
+// 只有在keepMapped为true时才保持为IPv6。
+// IP本身不保存zone，带zone的地址在转换时会返回错误，需要调用方先使用WithZone("")去除
+
+func NewIPFromNetipAddr(a netip.Addr, unmap bool) (*IP, error) {
+	if !a.IsValid() {
+		return nil, fmt.Errorf("invalid netip.Addr")
+	}
+	if a.Zone() != "" {
+		return nil, fmt.Errorf("ip: %s, zone is not supported", a)
+	}
+
+	if unmap {
+		a = a.Unmap()
+	}
+
+	ip := IP(a.AsSlice())
+	return &ip, nil
+}
+
+// NewIPFromNetIP net.IP中的IPv4-mapped地址默认转换为IPv4，比如net.ParseIP("1.2.3.4")
+func NewIPFromNetIP(nip net.IP, keepMapped bool) (*IP, error) {
+	switch len(nip) {
+	case net.IPv4len:
+		ip := make(IP, IPv4Len)
+		copy(ip, nip)
+		return &ip, nil
+	case net.IPv6len:
+		if !keepMapped && nip.To4() != nil {
+			ip := make(IP, IPv4Len)
+			copy(ip, nip.To4())
+			return &ip, nil
+		}
+		ip := make(IP, IPv6Len)
+		copy(ip, nip)
+		return &ip, nil
+	default:
+		return nil, fmt.Errorf("len(ip) = %d, invalid net.IP", len(nip))
+	}
+}
+
+func (ip *IP) NetipAddr() netip.Addr {
+	a, _ := netip.AddrFromSlice(*ip)
+	return a
+}
+
+func (ip *IP) NetipAddrWithZone(zone string) netip.Addr {
+	return ip.NetipAddr().WithZone(zone)
+}
+
+func (ip *IP) NetIP() net.IP {
+	n := make(net.IP, ip.Len())
+	copy(n, *ip)
+	return n
+}
+
+func NewIPNetFromNetipPrefix(p netip.Prefix, unmap bool) (*IPNet, error) {
+	if !p.IsValid() {
+		return nil, fmt.Errorf("invalid netip.Prefix")
+	}
+
+	bits := p.Bits()
+	addr := p.Addr()
+	if unmap && addr.Is4In6() {
+		if bits < 96 {
+			return nil, fmt.Errorf("prefix: %s, bits < 96, can not unmap to IPv4", p)
+		}
+		bits = bits - 96
+	}
+
+	ip, err := NewIPFromNetipAddr(addr, unmap)
+	if err != nil {
+		return nil, err
+	}
+
+	mask, err := NewIPMask(uint(bits), ip.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	return &IPNet{
+		IP:   *ip,
+		Mask: *mask,
+	}, nil
+}
+
+// NewIPNetFromNetIPNet 与NewIPFromNetIP相同，默认转换为IPv4，此时16字节的掩码需要至少96位
+func NewIPNetFromNetIPNet(n *net.IPNet, keepMapped bool) (*IPNet, error) {
+	if n == nil {
+		return nil, fmt.Errorf("net.IPNet is nil")
+	}
+
+	mask := n.Mask
+	ip, err := NewIPFromNetIP(n.IP, keepMapped && len(mask) == net.IPv6len)
+	if err != nil {
+		return nil, err
+	}
+
+	if ip.Type() == IPv4 && len(mask) == net.IPv6len {
+		if ones, bits := mask.Size(); bits == 0 || ones < 96 {
+			return nil, fmt.Errorf("net: %s, mask can not unmap to IPv4", n)
+		}
+		mask = mask[12:]
+	}
+
+	if len(mask) != ip.Len() {
+		return nil, fmt.Errorf("net: %s, len(mask) = %d, len(ip) = %d", n, len(mask), ip.Len())
+	}
+
+	m := make(IPMask, len(mask))
+	copy(m, mask)
+
+	return &IPNet{
+		IP:   *ip,
+		Mask: m,
+	}, nil
+}
+
+func (n *IPNet) NetipPrefix() (netip.Prefix, error) {
+	prefix := n.Mask.Prefix()
+	if prefix == -1 {
+		return netip.Prefix{}, fmt.Errorf("net: %s, non-contiguous mask can not convert to netip.Prefix", n)
+	}
+
+	return netip.PrefixFrom(n.IP.NetipAddr(), prefix), nil
+}
+
+func (n *IPNet) NetIPNet() *net.IPNet {
+	m := make(net.IPMask, n.Mask.Len())
+	copy(m, n.Mask)
+
+	return &net.IPNet{
+		IP:   n.IP.NetIP(),
+		Mask: m,
+	}
+}
+
+func NewIPRangeFromNetipAddr(start, end netip.Addr, unmap bool) (*IPRange, error) {
+	ip1, err := NewIPFromNetipAddr(start, unmap)
+	if err != nil {
+		return nil, err
+	}
+	ip2, err := NewIPFromNetipAddr(end, unmap)
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewIPRangeFromInt(ip1.Int(), ip2.Int(), ip1.Type())
+	if r == nil || ip1.Type() != ip2.Type() {
+		return nil, fmt.Errorf("start: %s, end: %s, invalid range", start, end)
+	}
+	return r, nil
+}
+
+func (r *IPRange) NetipAddrs() (netip.Addr, netip.Addr) {
+	return r.Start.NetipAddr(), r.End.NetipAddr()
+}
+
+func (r *IPRange) NetipPrefixes() []netip.Prefix {
+	list := []netip.Prefix{}
+	for _, n := range r.CIDRs() {
+		list = append(list, netip.PrefixFrom(n.IP.NetipAddr(), n.Prefix()))
+	}
+	return list
+}
+
+func NewNetworkFromNetipPrefix(p netip.Prefix, unmap bool) (*Network, error) {
+	n, err := NewIPNetFromNetipPrefix(p, unmap)
+	if err != nil {
+		return nil, err
+	}
+	return NewNetworkFromIPNet(n), nil
+}
+
+func NewNetworkFromNetIPNet(n *net.IPNet, keepMapped bool) (*Network, error) {
+	ipnet, err := NewIPNetFromNetIPNet(n, keepMapped)
+	if err != nil {
+		return nil, err
+	}
+	return NewNetworkFromIPNet(ipnet), nil
+}
+
+func abbrNetPrefixes(n AbbrNet) ([]netip.Prefix, error) {
+	nets, err := n.IPNetList()
+	if err != nil {
+		return nil, err
+	}
+
+	list := []netip.Prefix{}
+	for _, ipnet := range nets {
+		if ipnet.Prefix() == -1 {
+			return nil, fmt.Errorf("net: %s, non-contiguous mask can not convert to netip.Prefix", ipnet)
+		}
+		if r := ipnet.ToRange(); r != nil {
+			list = append(list, r.NetipPrefixes()...)
+		}
+	}
+	return list, nil
+}
+
+func (net Network) NetipPrefixes() ([]netip.Prefix, error) {
+	return abbrNetPrefixes(net.AbbrNet)
+}
+
+func (nl *NetworkList) NetipPrefixes() ([]netip.Prefix, error) {
+	if len(nl.list) == 0 {
+		return []netip.Prefix{}, nil
+	}
+	return abbrNetPrefixes(nl)
+}
+
+func NewNetworkGroupFromNetipPrefixes(ps []netip.Prefix, unmap bool) (*NetworkGroup, error) {
+	ng := NewNetworkGroup()
+	for _, p := range ps {
+		n, err := NewIPNetFromNetipPrefix(p, unmap)
+		if err != nil {
+			return nil, err
+		}
+		ng.Add(n)
+	}
+	return ng, nil
+}
+
+func NewNetworkGroupFromNetIPNets(ns []*net.IPNet, keepMapped bool) (*NetworkGroup, error) {
+	ng := NewNetworkGroup()
+	for _, n := range ns {
+		ipnet, err := NewIPNetFromNetIPNet(n, keepMapped)
+		if err != nil {
+			return nil, err
+		}
+		ng.Add(ipnet)
+	}
+	return ng, nil
+}
+
+// NetipPrefixes 返回ng聚合后的前缀列表，IPv4在前，IPv6在后
+func (ng *NetworkGroup) NetipPrefixes() ([]netip.Prefix, error) {
+	list := []netip.Prefix{}
+	for _, nl := range []*NetworkList{&ng.ipv4, &ng.ipv6} {
+		ps, err := nl.NetipPrefixes()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, ps...)
+	}
+	return list, nil
+}
+
+func (ng *NetworkGroup) NetIPNets() ([]*net.IPNet, error) {
+	ps, err := ng.NetipPrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*net.IPNet{}
+	for _, p := range ps {
+		ip := p.Addr().AsSlice()
+		bits := p.Addr().BitLen()
+		list = append(list, &net.IPNet{
+			IP:   net.IP(ip),
+			Mask: net.CIDRMask(p.Bits(), bits),
+		})
+	}
+	return list, nil
+}
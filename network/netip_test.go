@@ -0,0 +1,184 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestNetIPConvert(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"ip": net.ParseIP("1.2.3.4"), "keep": false, "want": "1.2.3.4", "family": IPv4},
+		{"ip": net.ParseIP("1.2.3.4").To4(), "keep": true, "want": "1.2.3.4", "family": IPv4},
+		{"ip": net.ParseIP("1.2.3.4"), "keep": true, "want": "::ffff:1.2.3.4", "family": IPv6},
+		{"ip": net.ParseIP("2001:db8::1"), "keep": false, "want": "2001:db8::1", "family": IPv6},
+		{"ip": net.ParseIP("::"), "keep": false, "want": "::", "family": IPv6},
+		{"ip": net.IP{1, 2, 3}, "keep": false, "err": true},
+	}
+
+	for _, tc := range testCases {
+		nip := tc["ip"].(net.IP)
+		ip, err := NewIPFromNetIP(nip, tc["keep"].(bool))
+		if tc["err"] != nil {
+			if err == nil {
+				t.Errorf("NewIPFromNetIP(%v), want error", nip)
+			}
+			continue
+		}
+		if err != nil || ip.NetipAddr().String() != tc["want"] || ip.Type() != tc["family"] {
+			t.Errorf("NewIPFromNetIP(%s, %v) = %v, err = %v, want = %s", nip, tc["keep"], ip, err, tc["want"])
+			continue
+		}
+		if !ip.NetIP().Equal(nip) {
+			t.Errorf("round trip %s, got = %s", nip, ip.NetIP())
+		}
+	}
+}
+
+func TestNetipAddrConvert(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"addr": "1.2.3.4", "unmap": false, "want": "1.2.3.4"},
+		{"addr": "::ffff:1.2.3.4", "unmap": false, "want": "::ffff:1.2.3.4"},
+		{"addr": "::ffff:1.2.3.4", "unmap": true, "want": "1.2.3.4"},
+		{"addr": "2001:db8::1", "unmap": true, "want": "2001:db8::1"},
+		{"addr": "fe80::1%eth0", "unmap": false, "err": true},
+	}
+
+	for _, tc := range testCases {
+		a := netip.MustParseAddr(tc["addr"].(string))
+		ip, err := NewIPFromNetipAddr(a, tc["unmap"].(bool))
+		if tc["err"] != nil {
+			if err == nil {
+				t.Errorf("NewIPFromNetipAddr(%s), want error", a)
+			}
+			if got := ip2Addr(t, a.WithZone("")); got != a.WithZone("") {
+				t.Errorf("WithZone(\"\") round trip %s, got = %s", a, got)
+			}
+			continue
+		}
+		if err != nil || ip.NetipAddr().String() != tc["want"] {
+			t.Errorf("NewIPFromNetipAddr(%s, %v) = %v, err = %v, want = %s", a, tc["unmap"], ip, err, tc["want"])
+		}
+		if !tc["unmap"].(bool) && ip.NetipAddr() != a {
+			t.Errorf("round trip %s, got = %s", a, ip.NetipAddr())
+		}
+	}
+	if _, err := NewIPFromNetipAddr(netip.Addr{}, false); err == nil {
+		t.Errorf("NewIPFromNetipAddr(zero), want error")
+	}
+}
+
+func ip2Addr(t *testing.T, a netip.Addr) netip.Addr {
+	ip, err := NewIPFromNetipAddr(a, false)
+	if err != nil {
+		t.Fatalf("NewIPFromNetipAddr(%s), err = %v", a, err)
+	}
+	return ip.NetipAddr()
+}
+
+func TestNetipPrefixConvert(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"prefix": "10.0.0.0/8", "unmap": false, "want": "10.0.0.0/8"},
+		{"prefix": "0.0.0.0/0", "unmap": false, "want": "0.0.0.0/0"},
+		{"prefix": "2001:db8::/32", "unmap": false, "want": "2001:db8::/32"},
+		{"prefix": "::ffff:10.0.0.0/104", "unmap": false, "want": "::ffff:10.0.0.0/104"},
+		{"prefix": "::ffff:10.0.0.0/104", "unmap": true, "want": "10.0.0.0/8"},
+		{"prefix": "::ffff:0.0.0.0/95", "unmap": true, "err": true},
+	}
+
+	for _, tc := range testCases {
+		p := netip.MustParsePrefix(tc["prefix"].(string))
+		n, err := NewIPNetFromNetipPrefix(p, tc["unmap"].(bool))
+		if tc["err"] != nil {
+			if err == nil {
+				t.Errorf("NewIPNetFromNetipPrefix(%s), want error", p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("NewIPNetFromNetipPrefix(%s), err = %v", p, err)
+		}
+		got, err := n.NetipPrefix()
+		if err != nil || got.String() != tc["want"] {
+			t.Errorf("NewIPNetFromNetipPrefix(%s, %v).NetipPrefix() = %s, err = %v, want = %s", p, tc["unmap"], got, err, tc["want"])
+		}
+	}
+
+	n := &IPNet{IP: IP{10, 0, 0, 0}, Mask: IPMask{255, 0, 255, 0}}
+	if _, err := n.NetipPrefix(); err == nil {
+		t.Errorf("NetipPrefix of non-contiguous mask, want error")
+	}
+}
+
+func TestNetIPNetConvert(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"cidr": "10.0.0.0/8", "keep": false, "want": "10.0.0.0/8"},
+		{"cidr": "192.168.1.1/32", "keep": true, "want": "192.168.1.1/32"},
+		{"cidr": "2001:db8::/32", "keep": false, "want": "2001:db8::/32"},
+	}
+
+	for _, tc := range testCases {
+		_, nn, _ := net.ParseCIDR(tc["cidr"].(string))
+		n, err := NewIPNetFromNetIPNet(nn, tc["keep"].(bool))
+		if err != nil || n.String() != tc["want"] {
+			t.Errorf("NewIPNetFromNetIPNet(%s) = %v, err = %v, want = %s", nn, n, err, tc["want"])
+			continue
+		}
+		if back := n.NetIPNet(); back.String() != nn.String() {
+			t.Errorf("round trip %s, got = %s", nn, back)
+		}
+	}
+
+	// 16字节的IPv4-mapped地址与掩码
+	mapped := &net.IPNet{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(104, 128)}
+	if n, err := NewIPNetFromNetIPNet(mapped, false); err != nil || n.String() != "10.0.0.0/8" {
+		t.Errorf("NewIPNetFromNetIPNet(%s, false) = %v, err = %v", mapped, n, err)
+	}
+	if n, err := NewIPNetFromNetIPNet(mapped, true); err != nil || n.Type() != IPv6 {
+		t.Errorf("NewIPNetFromNetIPNet(%s, true) = %v, err = %v", mapped, n, err)
+	}
+	short := &net.IPNet{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(64, 128)}
+	if _, err := NewIPNetFromNetIPNet(short, false); err == nil {
+		t.Errorf("NewIPNetFromNetIPNet(%s, false), want error", short)
+	}
+	if _, err := NewIPNetFromNetIPNet(nil, false); err == nil {
+		t.Errorf("NewIPNetFromNetIPNet(nil), want error")
+	}
+}
+
+func TestNetworkGroupNetipConvert(t *testing.T) {
+	prefixes := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8::/32"),
+		netip.MustParsePrefix("11.0.0.0/8"),
+	}
+	ng, err := NewNetworkGroupFromNetipPrefixes(prefixes, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := ng.NetipPrefixes()
+	if got := fmt.Sprint(ps); err != nil || got != "[10.0.0.0/7 2001:db8::/32]" {
+		t.Errorf("NetipPrefixes() = %s, err = %v", got, err)
+	}
+
+	nets, err := ng.NetIPNets()
+	if got := fmt.Sprint(nets); err != nil || got != "[10.0.0.0/7 2001:db8::/32]" {
+		t.Errorf("NetIPNets() = %s, err = %v", got, err)
+	}
+	back, err := NewNetworkGroupFromNetIPNets(nets, false)
+	if err != nil || !back.Same(ng) {
+		t.Errorf("NewNetworkGroupFromNetIPNets(%s) = %v, err = %v", nets, back, err)
+	}
+
+	r, err := NewIPRangeFromNetipAddr(netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.6"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(r.NetipPrefixes()); got != "[10.0.0.1/32 10.0.0.2/31 10.0.0.4/31 10.0.0.6/32]" {
+		t.Errorf("NetipPrefixes() = %s", got)
+	}
+	if _, err := NewIPRangeFromNetipAddr(netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("::1"), false); err == nil {
+		t.Errorf("NewIPRangeFromNetipAddr(mixed family), want error")
+	}
+}
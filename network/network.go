@@ -1024,40 +1024,10 @@ func NewNetworkGroup() *NetworkGroup {
 	}
 }
 
+// NewNetworkGroupFromString 使用generic dialect解析，除了逗号分隔的CIDR、范围以外，
+// 也接受ParseNetworkGroup支持的其他写法
 func NewNetworkGroupFromString(s string) (*NetworkGroup, error) {
-	if s == "" {
-		return nil, fmt.Errorf("s is empty")
-	}
-
-	list := strings.Split(s, ",")
-
-	ipv4_netlist := []*Network{}
-	ipv6_netlist := []*Network{}
-	for _, addr := range list {
-		n, err := NewNetworkFromString(addr)
-		if err != nil {
-			return nil, err
-		}
-		if n.Type() == IPv4 {
-			ipv4_netlist = append(ipv4_netlist, n)
-		} else {
-			ipv6_netlist = append(ipv6_netlist, n)
-		}
-	}
-
-	ipv4NetworkList, err := NewNetworkListFromList(ipv4_netlist)
-	if err != nil {
-		return nil, err
-	}
-	ipv6NetworkList, err := NewNetworkListFromList(ipv6_netlist)
-	if err != nil {
-		return nil, err
-	}
-
-	return &NetworkGroup{
-		ipv4: *ipv4NetworkList,
-		ipv6: *ipv6NetworkList,
-	}, nil
+	return ParseNetworkGroup(s, "generic")
 }
 
 func (ng *NetworkGroup) Add(net AbbrNet) {
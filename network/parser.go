@@ -0,0 +1,386 @@
+package network
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"tools/validator"
+)
+
+// 地址对象解析，不同厂商的地址对象写法通过Dialect进行扩展
+//
+// 输入按照','、';'或者换行拆分为多个条目，每个条目再按照空白拆分为Token，
+// Token中记录了在原始输入中的字节偏移，用于生成精确的错误位置
+
+type Token struct {
+	Text string
+	Pos  int
+}
+
+type ParseError struct {
+	Input string
+	Pos   int
+	Token string
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	if e.Input == "" {
+		return fmt.Sprintf("parse error at pos %d, token: %q, %s", e.Pos, e.Token, e.Msg)
+	}
+	return fmt.Sprintf("parse error at pos %d of %q, token: %q, %s", e.Pos, e.Input, e.Token, e.Msg)
+}
+
+func newParseError(tok Token, format string, a ...interface{}) *ParseError {
+	return &ParseError{
+		Pos:   tok.Pos,
+		Token: tok.Text,
+		Msg:   fmt.Sprintf(format, a...),
+	}
+}
+
+type Dialect interface {
+	Name() string
+	ParseItem(tokens []Token) ([]AbbrNet, error)
+}
+
+var (
+	dialectLock sync.RWMutex
+	dialects    = map[string]Dialect{}
+)
+
+func RegisterDialect(d Dialect) {
+	dialectLock.Lock()
+	defer dialectLock.Unlock()
+	dialects[d.Name()] = d
+}
+
+func GetDialect(name string) (Dialect, bool) {
+	dialectLock.RLock()
+	defer dialectLock.RUnlock()
+	d, ok := dialects[name]
+	return d, ok
+}
+
+type MaskStyle int
+
+const (
+	// MASK_AUTO 根据掩码的第一位进行判断，0开头视为反掩码
+	MASK_AUTO MaskStyle = iota
+	MASK_NETMASK
+	MASK_WILDCARD
+)
+
+// BaseDialect 内置的通用实现，通过开关控制各个厂商支持的写法
+type BaseDialect struct {
+	DialectName string
+	Mask        MaskStyle
+	// 支持 host 1.2.3.4
+	HostKeyword bool
+	// 支持 range 1.1.1.1 1.1.1.10
+	RangeKeyword bool
+	// 支持 any、any4、any6
+	Any bool
+	// 支持 10.0.0.1-20 形式的简写范围
+	ShortRange bool
+	// IPv6地址中的zone会被去除，否则返回错误
+	StripZone bool
+}
+
+func (d *BaseDialect) Name() string {
+	return d.DialectName
+}
+
+func init() {
+	RegisterDialect(&BaseDialect{
+		DialectName:  "generic",
+		Mask:         MASK_AUTO,
+		HostKeyword:  true,
+		RangeKeyword: true,
+		Any:          true,
+		ShortRange:   true,
+		StripZone:    true,
+	})
+	RegisterDialect(&BaseDialect{
+		DialectName:  "cisco-ios",
+		Mask:         MASK_WILDCARD,
+		HostKeyword:  true,
+		RangeKeyword: true,
+		Any:          true,
+	})
+	RegisterDialect(&BaseDialect{
+		DialectName:  "cisco-asa",
+		Mask:         MASK_NETMASK,
+		HostKeyword:  true,
+		RangeKeyword: true,
+		Any:          true,
+	})
+}
+
+func anyNets(keyword string) []AbbrNet {
+	var list []AbbrNet
+	if keyword == "any" || keyword == "any4" {
+		n, _ := ParseIPNet("0.0.0.0/0")
+		list = append(list, n)
+	}
+	if keyword == "any" || keyword == "any6" {
+		n, _ := ParseIPNet("::/0")
+		list = append(list, n)
+	}
+	return list
+}
+
+func (d *BaseDialect) parseIP(tok Token) (*IP, error) {
+	s := tok.Text
+	if index := strings.Index(s, "%"); index > -1 {
+		if !d.StripZone {
+			return nil, newParseError(Token{s[index:], tok.Pos + index}, "zone is not supported")
+		}
+		if !validator.IsIPv6Address(s[:index]) {
+			return nil, newParseError(tok, "zone only support IPv6 address")
+		}
+		s = s[:index]
+	}
+
+	ip, err := ParseIP(s)
+	if err != nil {
+		return nil, newParseError(tok, "invalid ip address")
+	}
+	return ip, nil
+}
+
+func (d *BaseDialect) parseMask(ipTok, maskTok Token) (AbbrNet, error) {
+	ip, err := d.parseIP(ipTok)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ParseIP(maskTok.Text)
+	if err != nil || m.Type() != ip.Type() {
+		return nil, newParseError(maskTok, "invalid mask")
+	}
+	mask, err := IPtoMask(m)
+	if err != nil {
+		return nil, newParseError(maskTok, "invalid mask")
+	}
+
+	wildcard := false
+	switch d.Mask {
+	case MASK_WILDCARD:
+		wildcard = true
+	case MASK_AUTO:
+		wildcard = mask.GetBit(0) == 0 && mask.Prefix() != 0
+	}
+
+	if wildcard {
+		mask = mask.Reverse()
+	} else if mask.Prefix() == -1 {
+		return nil, newParseError(maskTok, "netmask is not contiguous")
+	}
+
+	return &IPNet{
+		IP:   ip.AfterMask(mask),
+		Mask: *mask,
+	}, nil
+}
+
+func (d *BaseDialect) parseRange(startTok, endTok Token) (AbbrNet, error) {
+	start, err := d.parseIP(startTok)
+	if err != nil {
+		return nil, err
+	}
+	end, err := d.parseIP(endTok)
+	if err != nil {
+		return nil, err
+	}
+	if start.Type() != end.Type() {
+		return nil, newParseError(endTok, "ip family is different from %s", start)
+	}
+
+	r := NewIPRangeFromInt(start.Int(), end.Int(), start.Type())
+	if r == nil {
+		return nil, newParseError(endTok, "end is less than start %s", start)
+	}
+	return r, nil
+}
+
+// parseShortRange 10.0.0.1-20 或者 2001:db8::1-ff，只替换最后一段
+func (d *BaseDialect) parseShortRange(startTok, endTok Token) (AbbrNet, error) {
+	start, err := d.parseIP(startTok)
+	if err != nil {
+		return nil, err
+	}
+
+	end := start.Copy()
+	if start.Type() == IPv4 {
+		v, err := strconv.ParseUint(endTok.Text, 10, 8)
+		if err != nil {
+			return nil, newParseError(endTok, "invalid last octet")
+		}
+		(*end)[IPv4Len-1] = byte(v)
+	} else {
+		v, err := strconv.ParseUint(endTok.Text, 16, 16)
+		if err != nil {
+			return nil, newParseError(endTok, "invalid last group")
+		}
+		(*end)[IPv6Len-2] = byte(v >> 8)
+		(*end)[IPv6Len-1] = byte(v)
+	}
+
+	r := NewIPRangeFromInt(start.Int(), end.Int(), start.Type())
+	if r == nil {
+		return nil, newParseError(endTok, "end is less than start %s", start)
+	}
+	return r, nil
+}
+
+func (d *BaseDialect) parseSingle(tok Token) (AbbrNet, error) {
+	s := tok.Text
+	if index := strings.Index(s, "-"); index > -1 {
+		startTok := Token{s[:index], tok.Pos}
+		endTok := Token{s[index+1:], tok.Pos + index + 1}
+		if d.ShortRange && !strings.ContainsAny(endTok.Text, ".:") {
+			return d.parseShortRange(startTok, endTok)
+		}
+		return d.parseRange(startTok, endTok)
+	}
+
+	if index := strings.Index(s, "/"); index > -1 {
+		ip, err := d.parseIP(Token{s[:index], tok.Pos})
+		if err != nil {
+			return nil, err
+		}
+		maskTok := Token{s[index+1:], tok.Pos + index + 1}
+		if prefix, err := strconv.Atoi(maskTok.Text); err == nil {
+			if prefix < 0 || prefix > ip.Size() {
+				return nil, newParseError(maskTok, "prefix out of range [0, %d]", ip.Size())
+			}
+			mask, _ := NewIPMask(uint(prefix), ip.Type())
+			// 10.0.0.1/8与parseMask一致，转换为网络地址10.0.0.0/8
+			return &IPNet{IP: ip.AfterMask(mask), Mask: *mask}, nil
+		}
+		return d.parseMask(Token{s[:index], tok.Pos}, maskTok)
+	}
+
+	ip, err := d.parseIP(tok)
+	if err != nil {
+		return nil, err
+	}
+	mask, _ := NewIPMask(uint(ip.Size()), ip.Type())
+	return &IPNet{IP: *ip, Mask: *mask}, nil
+}
+
+func (d *BaseDialect) ParseItem(tokens []Token) ([]AbbrNet, error) {
+	first := tokens[0]
+	keyword := strings.ToLower(first.Text)
+
+	if keyword == "any" || keyword == "any4" || keyword == "any6" {
+		if !d.Any {
+			return nil, newParseError(first, "any is not supported by dialect %s", d.Name())
+		}
+		if len(tokens) > 1 {
+			return nil, newParseError(tokens[1], "unexpected token after %s", first.Text)
+		}
+		return anyNets(keyword), nil
+	}
+
+	var net AbbrNet
+	var err error
+	var used int
+	switch {
+	case keyword == "host":
+		if !d.HostKeyword {
+			return nil, newParseError(first, "host is not supported by dialect %s", d.Name())
+		}
+		if len(tokens) < 2 {
+			return nil, newParseError(first, "host need an ip address")
+		}
+		net, err = d.parseSingle(tokens[1])
+		if err == nil && net.AddressType() != HOST {
+			err = newParseError(tokens[1], "host need an ip address")
+		}
+		used = 2
+	case keyword == "range":
+		if !d.RangeKeyword {
+			return nil, newParseError(first, "range is not supported by dialect %s", d.Name())
+		}
+		if len(tokens) < 3 {
+			return nil, newParseError(first, "range need start and end address")
+		}
+		net, err = d.parseRange(tokens[1], tokens[2])
+		used = 3
+	case len(tokens) >= 2:
+		net, err = d.parseMask(tokens[0], tokens[1])
+		used = 2
+	default:
+		net, err = d.parseSingle(first)
+		used = 1
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) > used {
+		return nil, newParseError(tokens[used], "unexpected token")
+	}
+	return []AbbrNet{net}, nil
+}
+
+func splitItems(s string) [][]Token {
+	var items [][]Token
+	var tokens []Token
+	begin := -1
+	for i := 0; i <= len(s); i++ {
+		var c byte
+		if i < len(s) {
+			c = s[i]
+		}
+		sep := i == len(s) || c == ',' || c == ';' || c == '\n'
+		space := c == ' ' || c == '\t' || c == '\r'
+		if sep || space {
+			if begin > -1 {
+				tokens = append(tokens, Token{s[begin:i], begin})
+				begin = -1
+			}
+			if sep && len(tokens) > 0 {
+				items = append(items, tokens)
+				tokens = nil
+			}
+		} else if begin == -1 {
+			begin = i
+		}
+	}
+	return items
+}
+
+func ParseNetworkGroupWithDialect(s string, d Dialect) (*NetworkGroup, error) {
+	items := splitItems(s)
+	if len(items) == 0 {
+		return nil, &ParseError{Input: s, Pos: 0, Msg: "input is empty"}
+	}
+
+	ng := NewNetworkGroup()
+	for _, tokens := range items {
+		nets, err := d.ParseItem(tokens)
+		if err != nil {
+			if pe, ok := err.(*ParseError); ok {
+				pe.Input = s
+			}
+			return nil, err
+		}
+		for _, n := range nets {
+			ng.Add(n)
+		}
+	}
+
+	return ng, nil
+}
+
+// ParseNetworkGroup 使用已注册的dialect解析地址对象
+func ParseNetworkGroup(s string, dialect string) (*NetworkGroup, error) {
+	d, ok := GetDialect(dialect)
+	if !ok {
+		return nil, fmt.Errorf("unknown dialect: %s", dialect)
+	}
+	return ParseNetworkGroupWithDialect(s, d)
+}
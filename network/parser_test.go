@@ -0,0 +1,124 @@
+package network
+
+import (
+	"strings"
+	"testing"
+)
+
+var dialectTestList = []map[string]interface{}{
+	{
+		"dialect": "generic",
+		"input":   "10.0.0.0 255.255.255.0, host 1.2.3.4, 10.0.0.1-20, any6",
+		"want":    "10.0.0.0/24,1.2.3.4/32,10.0.0.1-10.0.0.20,::/0",
+	},
+	{
+		"dialect": "generic",
+		"input":   "fe80::1%eth0, 2001:db8::1-ff",
+		"want":    "fe80::1/128,2001:db8::1-2001:db8::ff",
+	},
+	{
+		"dialect": "cisco-ios",
+		"input":   "10.0.0.0 0.0.0.255; 10.1.0.0 0.0.255.0",
+		"want":    "10.0.0.0/24,10.1.0.0/255.255.0.255",
+	},
+	{
+		"dialect": "cisco-asa",
+		"input":   "10.0.0.0 255.255.0.0\nrange 1.1.1.1 1.1.1.9\nany4",
+		"want":    "10.0.0.0/16,1.1.1.1-1.1.1.9,0.0.0.0/0",
+	},
+	{
+		"dialect": "generic",
+		"input":   "10.0.0.1/8, 2001:db8::1/32",
+		"want":    "10.0.0.0/8,2001:db8::/32",
+	},
+	{
+		"dialect": "generic",
+		"input":   "10.0.0.1, 10.0.0.300",
+		"pos":     10,
+	},
+	{
+		"dialect": "cisco-asa",
+		"input":   "10.0.0.0 255.0.255.0",
+		"pos":     9,
+	},
+	{
+		"dialect": "generic",
+		"input":   "host 1.1.1.1 x",
+		"pos":     13,
+	},
+	{
+		"dialect": "generic",
+		"input":   "10.0.0.5-3",
+		"pos":     9,
+	},
+	{
+		"dialect": "cisco-ios",
+		"input":   "fe80::1%eth0",
+		"pos":     7,
+	},
+}
+
+func TestParseNetworkGroup(t *testing.T) {
+	for _, data := range dialectTestList {
+		ng, err := ParseNetworkGroup(data["input"].(string), data["dialect"].(string))
+		if pos, ok := data["pos"]; ok {
+			pe, ok := err.(*ParseError)
+			if !ok || pe.Pos != pos {
+				t.Errorf("Test %+v, got = %v, want pos = %d", data, err, pos)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Test %+v, err = %v", data, err)
+			continue
+		}
+		if got := strings.Join(ng.StringList(), ","); got != data["want"] {
+			t.Errorf("Test %+v, got = %s, want = %s", data, got, data["want"])
+		}
+	}
+}
+
+func TestParseItemMasked(t *testing.T) {
+	d, _ := GetDialect("generic")
+	nets, err := d.ParseItem([]Token{{"10.0.0.1/8", 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nets[0].(*IPNet).IP.String(); got != "10.0.0.0" {
+		t.Errorf("ParseItem(10.0.0.1/8), ip = %s, want = 10.0.0.0", got)
+	}
+}
+
+func TestParseErrorInput(t *testing.T) {
+	_, err := ParseNetworkGroup("10.0.0.1, 10.0.0.300", "generic")
+	if err == nil || !strings.Contains(err.Error(), `"10.0.0.1, 10.0.0.300"`) {
+		t.Errorf("err = %v, want the input in the message", err)
+	}
+}
+
+func TestNewNetworkGroupFromString(t *testing.T) {
+	testList := []map[string]interface{}{
+		{"input": "1.1.1.0/24,2001:db8::/32", "want": "1.1.1.0/24,2001:db8::/32"},
+		{"input": "1.1.1.1-1.1.1.9", "want": "1.1.1.1-1.1.1.9"},
+		{"input": "10.0.0.0 255.255.255.0, host 1.2.3.4", "want": "10.0.0.0/24,1.2.3.4/32"},
+		{"input": "", "err": true},
+		{"input": "1.1.1.1,1.1.1.300", "err": true},
+	}
+	for _, data := range testList {
+		ng, err := NewNetworkGroupFromString(data["input"].(string))
+		if data["err"] == true {
+			if _, ok := err.(*ParseError); !ok {
+				t.Errorf("Test %+v, err = %v, want ParseError", data, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %+v, err = %v", data, err)
+			continue
+		}
+		if got := strings.Join(ng.StringList(), ","); got != data["want"] {
+			t.Errorf("Test %+v, got = %s, want = %s", data, got, data["want"])
+		}
+	}
+}
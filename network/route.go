@@ -426,17 +426,23 @@ func (nv NextHopFormatValidator) Validate(data map[string]interface{}) validator
 	ip := data["ip"].(string)
 	connect := data["connect"].(bool)
 	vs := data["vs"]
+	params := map[string]interface{}{
+		"interface": it,
+		"ip":        ip,
+		"connect":   connect,
+		"vs":        fmt.Sprintf("%T", vs),
+	}
 
 	if vs != nil {
 		_, ok := vs.(VsInt)
 		if !ok {
-			return validator.NewValidateResult(false, fmt.Sprintf("error 1: interface:%s, ip:%s, connect:%t, vs:%T", it, ip, connect, vs))
+			return validator.NewCodeResult("nexthop_vs", vs, params)
 		}
 	}
 
 	if ip != "" {
 		if !(validator.IsIPv4Address(ip) || validator.IsIPv6Address(ip)) {
-			return validator.NewValidateResult(false, fmt.Sprintf("error 2: interface:%s, ip:%s, connect:%t, vs:%T", it, ip, connect, vs))
+			return validator.NewCodeResult("nexthop_ip", ip, params)
 		}
 		// if it == "" {
 		// return nil, errors.New(fmt.Sprintf("error 3: interface:%s, ip:%s, connect:%t, vs:%T", it, ip, connect, vs))
@@ -446,18 +452,18 @@ func (nv NextHopFormatValidator) Validate(data map[string]interface{}) validator
 	if it != "" {
 		if !connect {
 			if ip == "" {
-				return validator.NewValidateResult(false, fmt.Sprintf("error 3: interface:%s, ip:%s, connect:%t, vs:%T", it, ip, connect, vs))
+				return validator.NewCodeResult("nexthop_ip_required", ip, params)
 			}
 		}
 	}
 
 	if !connect {
 		if ip == "" {
-			return validator.NewValidateResult(false, fmt.Sprintf("error 4: interface:%s, ip:%s, connect:%t, vs:%T", it, ip, connect, vs))
+			return validator.NewCodeResult("nexthop_ip_required", ip, params)
 		}
 	} else {
 		if it == "" {
-			return validator.NewValidateResult(false, fmt.Sprintf("error 5: interface:%s, ip:%s, connect:%t, vs:%T", it, ip, connect, vs))
+			return validator.NewCodeResult("nexthop_interface_required", it, params)
 		}
 	}
 
@@ -478,7 +484,7 @@ func (nv NextHopRecursionValidator) Validate(data map[string]interface{}) valida
 			for _, nh := range next.next {
 				hop := nh.(*Hop)
 				if hop.Interface == "" && hop.Ip == "" {
-					return validator.NewValidateResult(false, fmt.Sprintf("%s ip and interface is empty", re))
+					return validator.NewCodeResult("nexthop_empty", nil, map[string]interface{}{"route": re.String()})
 				}
 
 				if hop.Interface == "" {
@@ -487,9 +493,12 @@ func (nv NextHopRecursionValidator) Validate(data map[string]interface{}) valida
 						return validator.NewValidateResult(false, fmt.Sprintf("%v", err))
 					}
 					// 递归检查时，应该允许默认路由进行递归查询
-					rmr := routeTable.Match(net, true, false)
+					rmr, err := routeTable.Match(net, true, false)
+					if err != nil {
+						return validator.NewValidateResult(false, fmt.Sprintf("%v", err))
+					}
 					if rmr.IsMatch() == false {
-						return validator.NewValidateResult(false, fmt.Sprintf("next hop ip %s match route failed", hop.Ip))
+						return validator.NewCodeResult("nexthop_unreachable", hop.Ip, map[string]interface{}{"ip": hop.Ip})
 					}
 				}
 			}
@@ -739,7 +748,7 @@ func (t *AddressTable) Push(net AbbrNet, data *flexrange.ExtendData) error {
 
 	et, err := flexrange.NewEntry(first, last, data)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("net: %s, %w", net, err)
 	}
 
 	et.SetData(data)
@@ -748,8 +757,8 @@ func (t *AddressTable) Push(net AbbrNet, data *flexrange.ExtendData) error {
 	if l == 0 {
 		// 如果路由项的Prefix为0，其实就是默认路由，单独保存为t.dgw中
 		t.dgw = et
-	} else {
-		t.table[l].Push(first, last, data)
+	} else if _, err := t.table[l].Push(first, last, data); err != nil {
+		return fmt.Errorf("net: %s, %w", net, err)
 	}
 
 	return nil
@@ -769,15 +778,15 @@ func (t *AddressTable) PushRoute(net AbbrNet, nx *NextHop) error {
 	}
 	ext, err := nx.MakeExtendData()
 	if err != nil {
-		panic(err)
+		return err
 	}
 	return t.Push(net, ext)
 }
 
-func (t AddressTable) Remove(net AbbrNet) (ok bool) {
+func (t AddressTable) Remove(net AbbrNet) (ok bool, err error) {
 	entry, err := flexrange.NewEntry(net.First().Int(), net.Last().Int(), nil)
 	if err != nil {
-		panic(err)
+		return false, fmt.Errorf("net: %s, %w", net, err)
 	}
 
 	for _, nl := range t.table {
@@ -790,10 +799,10 @@ func (t AddressTable) Remove(net AbbrNet) (ok bool) {
 	return
 }
 
-func (t *AddressTable) Equal(net AbbrNet) *NextHop {
+func (t *AddressTable) Equal(net AbbrNet) (*NextHop, error) {
 	entry, err := flexrange.NewEntry(net.First().Int(), net.Last().Int(), nil)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("net: %s, %w", net, err)
 	}
 
 	for _, nl := range t.table {
@@ -802,12 +811,12 @@ func (t *AddressTable) Equal(net AbbrNet) *NextHop {
 			//fmt.Printf("Compare(entry) = %+v\n", e.Compare(entry))
 			if e.Compare(entry) == flexrange.Equal {
 				nh := e.Data().Data.Copy()
-				return nh.(*NextHop)
+				return nh.(*NextHop), nil
 			}
 		}
 	}
 
-	return nil
+	return nil, nil
 }
 
 func (t *AddressTable) OutputInterface(route flexrange.EntryInt) []string {
@@ -828,9 +837,9 @@ func (t *AddressTable) OutputInterface(route flexrange.EntryInt) []string {
 
 }
 
-func (t *AddressTable) MatchNetList(nl NetworkList, dgw, ignoreGateway bool) *MatchResult {
+func (t *AddressTable) MatchNetList(nl NetworkList, dgw, ignoreGateway bool) (*MatchResult, error) {
 	if nl.Type() != t.ip {
-		panic(fmt.Sprintf("AddressTable type is: %s, NetworkList type is: %s", t.ip, nl.Type()))
+		return nil, fmt.Errorf("AddressTable type is: %s, NetworkList type is: %s", t.ip, nl.Type())
 	}
 
 	//match := flexrange.NewEntryList(uint32(t.Size()), big.NewInt(0))
@@ -838,7 +847,10 @@ func (t *AddressTable) MatchNetList(nl NetworkList, dgw, ignoreGateway bool) *Ma
 	//unmatch := flexrange.NewEntryList(uint32(t.Size()), big.NewInt(0))
 	unmatch := NewIPEntryList(t.ip)
 	for _, n := range nl.list {
-		res := t.Match(n, dgw, ignoreGateway)
+		res, err := t.Match(n, dgw, ignoreGateway)
+		if err != nil {
+			return nil, err
+		}
 		for it := res.Match.Iterator(); it.HasNext(); {
 			_, e := it.Next()
 			match.PushEntry(e)
@@ -852,14 +864,17 @@ func (t *AddressTable) MatchNetList(nl NetworkList, dgw, ignoreGateway bool) *Ma
 		Ip:      t.ip,
 		Match:   match,
 		Unmatch: unmatch,
-	}
+	}, nil
 }
 
-func (t *AddressTable) Match(net AbbrNet, dgw, ignoreGateway bool) *MatchResult {
+func (t *AddressTable) Match(net AbbrNet, dgw, ignoreGateway bool) (*MatchResult, error) {
+	if net.Type() != t.ip {
+		return nil, fmt.Errorf("net: %s, %w, AddressTable type is: %s", net, flexrange.ErrOutOfBounds, t.ip)
+	}
 	//unmatch表示net匹配路由以后的，还剩余的部分
 	other, err := flexrange.NewEntry(net.First().Int(), net.Last().Int(), nil)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("net: %s, %w", net, err)
 	}
 
 	targetList := NewIPEntryListFromList(t.ip, []flexrange.EntryInt{other})
@@ -896,7 +911,10 @@ func (t *AddressTable) Match(net AbbrNet, dgw, ignoreGateway bool) *MatchResult
 	if ignoreGateway == false {
 		if dgw && t.dgw != nil {
 			netDataRange := net.DataRange()
-			gwDataRange := t.dgw.WarpperWithDataRange(uint32(t.Size()), big.NewInt(0))
+			gwDataRange, err := t.dgw.WarpperWithDataRange(uint32(t.Size()), big.NewInt(0))
+			if err != nil {
+				return nil, fmt.Errorf("default gateway: %s, %w", t.dgw, err)
+			}
 			if netDataRange.Same(gwDataRange) {
 				match = NewIPEntryList(t.ip)
 				match.PushEntry(t.dgw)
@@ -923,7 +941,7 @@ func (t *AddressTable) Match(net AbbrNet, dgw, ignoreGateway bool) *MatchResult
 		Ip:      t.ip,
 		Match:   match,
 		Unmatch: targetList,
-	}
+	}, nil
 }
 
 // func (t AddressTable) Verify() bool {
@@ -931,14 +949,16 @@ func (t *AddressTable) Match(net AbbrNet, dgw, ignoreGateway bool) *MatchResult
 // return result.Status()
 // }
 
-func (t *AddressTable) RecursionRouteProcess() {
+// RecursionRouteProcess 将只有下一跳IP的Hop替换为递归查询到的出接口，
+// 校验失败或者查询失败时返回错误，此时路由表可能已经被部分修改
+func (t *AddressTable) RecursionRouteProcess() error {
 	data := map[string]interface{}{
 		"table": t,
 	}
 	result := NextHopRecursionValidator{}.Validate(data)
 
 	if result.Status() == false {
-		panic(result.Msg())
+		return fmt.Errorf("%s", result.Msg())
 	}
 
 	// for i := t.Size() - 1; i >= 0; i-- {
@@ -959,12 +979,18 @@ func (t *AddressTable) RecursionRouteProcess() {
 				if hop.Interface == "" && hop.Ip != "" {
 					fmt.Printf("进入递归路由查询: route: %+v, hop: %+v\n", re, hop)
 					// 如果接口为空，IP地址不为空，符合递归路由查询条件
-					net, _ := ParseIPNet(hop.Ip)
+					net, err := ParseIPNet(hop.Ip)
+					if err != nil {
+						return fmt.Errorf("next hop ip %s, %w", hop.Ip, err)
+					}
 
 					// 进行递归路由检查时，也应该对默认路由进行匹配
-					rmr := t.Match(net, true, false)
+					rmr, err := t.Match(net, true, false)
+					if err != nil {
+						return fmt.Errorf("next hop ip %s, %w", hop.Ip, err)
+					}
 					if rmr.IsMatch() == false {
-						panic(fmt.Sprintf("next hop ip %s match route failed", hop.Ip))
+						return fmt.Errorf("next hop ip %s match route failed", hop.Ip)
 					}
 					// 轮询Match结果，如果有多个下一跳，则针对每个下一跳生成Nexthop
 					for it2 := rmr.Match.Iterator(); it2.HasNext(); {
@@ -973,7 +999,7 @@ func (t *AddressTable) RecursionRouteProcess() {
 
 						for index, nh2 := range next2.next {
 							if nh2.(*Hop).Interface == "" {
-								panic(fmt.Sprintf("next hop ip %s's recursion route is invalid, %v", hop.Ip, nh2))
+								return fmt.Errorf("next hop ip %s's recursion route is invalid, %v", hop.Ip, nh2)
 							}
 							if index == 0 {
 								// 递归路由的第一个下一跳，直接修改原有HOP信息
@@ -1000,6 +1026,7 @@ func (t *AddressTable) RecursionRouteProcess() {
 			}
 		}
 	}
+	return nil
 }
 
 func (t AddressTable) String() string {
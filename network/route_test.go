@@ -0,0 +1,86 @@
+package network
+
+import (
+	"errors"
+	"testing"
+	"tools/flexrange"
+)
+
+func TestAddressTableErrors(t *testing.T) {
+	at := NewAddressTable(IPv4)
+	for _, r := range [][]string{{"10.0.0.0/8", "eth0"}, {"0.0.0.0/0", "eth1"}} {
+		net, _ := ParseIPNet(r[0])
+		nh := NewNextHop()
+		nh.AddHop(r[1], "", true, false, nil)
+		if err := at.PushRoute(net, nh); err != nil {
+			t.Fatalf("PushRoute(%s), err = %v", r[0], err)
+		}
+	}
+
+	inverted := &IPRange{Start: IP{10, 0, 0, 9}, End: IP{10, 0, 0, 1}}
+	v6, _ := ParseIPNet("2001:db8::/32")
+	nh := NewNextHop()
+	nh.AddHop("eth0", "", true, false, nil)
+
+	testCases := []map[string]interface{}{
+		{"name": "PushRoute(v6)", "err": at.PushRoute(v6, nh), "want": flexrange.ErrOutOfBounds},
+		{"name": "Match(v6)", "err": func() error { _, err := at.Match(v6, true, false); return err }(), "want": flexrange.ErrOutOfBounds},
+		{"name": "Match(inverted)", "err": func() error { _, err := at.Match(inverted, true, false); return err }(), "want": flexrange.ErrInvertedRange},
+		{"name": "Equal(inverted)", "err": func() error { _, err := at.Equal(inverted); return err }(), "want": flexrange.ErrInvertedRange},
+		{"name": "Remove(inverted)", "err": func() error { _, err := at.Remove(inverted); return err }(), "want": flexrange.ErrInvertedRange},
+	}
+	for _, tc := range testCases {
+		if err, _ := tc["err"].(error); !errors.Is(err, tc["want"].(error)) {
+			t.Errorf("%s, err = %v, want = %v", tc["name"], err, tc["want"])
+		}
+	}
+
+	net, _ := ParseIPNet("10.1.0.0/16")
+	if res, err := at.Match(net, true, false); err != nil || !res.IsMatch() {
+		t.Errorf("Match(%s) = %+v, err = %v", net, res, err)
+	}
+	exact, _ := ParseIPNet("10.0.0.0/8")
+	if got, err := at.Equal(exact); err != nil || got == nil || got.OutInterfaces()[0] != "eth0" {
+		t.Errorf("Equal(%s) = %v, err = %v", exact, got, err)
+	}
+	if ok, err := at.Remove(exact); err != nil || !ok {
+		t.Errorf("Remove(%s) = %v, err = %v", exact, ok, err)
+	}
+
+	nl := NetworkList{family: IPv6}
+	if _, err := at.MatchNetList(nl, true, false); err == nil {
+		t.Errorf("MatchNetList(IPv6), want error")
+	}
+}
+
+func TestRecursionRouteProcess(t *testing.T) {
+	newTable := func(routes [][]string) *AddressTable {
+		at := NewAddressTable(IPv4)
+		for _, r := range routes {
+			net, _ := ParseIPNet(r[0])
+			nh := NewNextHop()
+			nh.AddHop(r[1], r[2], r[1] != "", false, nil)
+			if err := at.PushRoute(net, nh); err != nil {
+				t.Fatalf("PushRoute(%s), err = %v", r[0], err)
+			}
+		}
+		return at
+	}
+
+	at := newTable([][]string{{"10.0.0.0/8", "eth0", ""}, {"20.0.0.0/8", "", "10.1.1.1"}})
+	if err := at.RecursionRouteProcess(); err != nil {
+		t.Fatalf("RecursionRouteProcess, err = %v", err)
+	}
+	net, _ := ParseIPNet("20.0.0.0/8")
+	if got, err := at.Equal(net); err != nil || got.OutInterfaces()[0] != "eth0" {
+		t.Errorf("Equal(%s) = %v, err = %v", net, got, err)
+	}
+
+	// 下一跳没有匹配的路由，或者与路由表的地址族不同时返回错误
+	for _, ip := range []string{"30.1.1.1", "2001:db8::1"} {
+		at := newTable([][]string{{"10.0.0.0/8", "eth0", ""}, {"20.0.0.0/8", "", ip}})
+		if err := at.RecursionRouteProcess(); err == nil {
+			t.Errorf("RecursionRouteProcess(next hop %s), want error", ip)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// 紧凑二进制编码的公共部分，格式为 [版本][类型][内容]，
+// 内容由无符号varint、带长度前缀的字节串以及带符号的big.Int组成
+
+const BINARY_VERSION byte = 1
+
+type BinaryWriter struct {
+	buf []byte
+}
+
+func NewBinaryWriter(kind byte) *BinaryWriter {
+	return &BinaryWriter{buf: []byte{BINARY_VERSION, kind}}
+}
+
+func (w *BinaryWriter) Byte(b byte) {
+	w.buf = append(w.buf, b)
+}
+
+// Raw 直接写入定长数据，不带长度前缀
+func (w *BinaryWriter) Raw(b []byte) {
+	w.buf = append(w.buf, b...)
+}
+
+func (w *BinaryWriter) Uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	w.buf = append(w.buf, b[:n]...)
+}
+
+func (w *BinaryWriter) Bytes(b []byte) {
+	w.Uvarint(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *BinaryWriter) String(s string) {
+	w.Bytes([]byte(s))
+}
+
+// BigUint 写入非负整数，负数返回错误
+func (w *BinaryWriter) BigUint(i *big.Int) error {
+	if i.Sign() < 0 {
+		return fmt.Errorf("negative value: %d", i)
+	}
+	w.Bytes(i.Bytes())
+	return nil
+}
+
+// BigInt 写入带符号整数，符号位占用一个字节
+func (w *BinaryWriter) BigInt(i *big.Int) {
+	if i.Sign() < 0 {
+		w.Byte(1)
+	} else {
+		w.Byte(0)
+	}
+	w.Bytes(i.Bytes())
+}
+
+func (w *BinaryWriter) Result() []byte {
+	return w.buf
+}
+
+// BinaryReader 读取BinaryWriter的输出，出现错误以后的读取均返回零值，
+// 调用方在最后通过Err检查
+type BinaryReader struct {
+	buf []byte
+	off int
+	err error
+}
+
+func NewBinaryReader(b []byte, kind byte) *BinaryReader {
+	r := &BinaryReader{buf: b}
+	if len(b) < 2 {
+		r.err = fmt.Errorf("binary data is too short")
+		return r
+	}
+	if b[0] != BINARY_VERSION {
+		r.err = fmt.Errorf("unsupported binary version: %d", b[0])
+		return r
+	}
+	if b[1] != kind {
+		r.err = fmt.Errorf("binary kind = %d, want %d", b[1], kind)
+		return r
+	}
+	r.off = 2
+	return r
+}
+
+func (r *BinaryReader) fail(format string, a ...interface{}) {
+	if r.err == nil {
+		r.err = fmt.Errorf("offset %d: %s", r.off, fmt.Sprintf(format, a...))
+	}
+}
+
+func (r *BinaryReader) Err() error {
+	return r.err
+}
+
+// Remain 剩余未读取的字节数
+func (r *BinaryReader) Remain() int {
+	return len(r.buf) - r.off
+}
+
+// Close 检查是否有错误以及是否还有多余的数据
+func (r *BinaryReader) Close() error {
+	if r.err == nil && r.off != len(r.buf) {
+		r.fail("%d trailing bytes", len(r.buf)-r.off)
+	}
+	return r.err
+}
+
+func (r *BinaryReader) Byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.off >= len(r.buf) {
+		r.fail("unexpected end of data")
+		return 0
+	}
+	b := r.buf[r.off]
+	r.off++
+	return b
+}
+
+func (r *BinaryReader) Raw(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > r.Remain() {
+		r.fail("need %d bytes, remain %d", n, r.Remain())
+		return nil
+	}
+	b := make([]byte, n)
+	copy(b, r.buf[r.off:])
+	r.off += n
+	return b
+}
+
+func (r *BinaryReader) Uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf[r.off:])
+	if n <= 0 {
+		r.fail("invalid uvarint")
+		return 0
+	}
+	r.off += n
+	return v
+}
+
+// Count 读取元素个数，每个元素至少占用一个字节，超过剩余长度时视为错误
+func (r *BinaryReader) Count() int {
+	v := r.Uvarint()
+	if v > uint64(r.Remain()) {
+		r.fail("count %d exceed remain %d", v, r.Remain())
+		return 0
+	}
+	return int(v)
+}
+
+func (r *BinaryReader) Bytes() []byte {
+	n := r.Uvarint()
+	if n > uint64(r.Remain()) {
+		r.fail("length %d exceed remain %d", n, r.Remain())
+		return nil
+	}
+	return r.Raw(int(n))
+}
+
+func (r *BinaryReader) String() string {
+	return string(r.Bytes())
+}
+
+func (r *BinaryReader) BigUint() *big.Int {
+	return new(big.Int).SetBytes(r.Bytes())
+}
+
+func (r *BinaryReader) BigInt() *big.Int {
+	sign := r.Byte()
+	i := r.BigUint()
+	if sign == 1 {
+		i.Neg(i)
+	} else if sign != 0 {
+		r.fail("invalid sign: %d", sign)
+	}
+	return i
+}
@@ -1,19 +1,15 @@
 package utils
 
 import (
-	"reflect"
 	"unsafe"
 )
 
 // String2Bytes string to []byte
 func String2Bytes(s string) []byte {
-	sh := (*reflect.StringHeader)(unsafe.Pointer(&s))
-	bh := reflect.SliceHeader{
-		Data: sh.Data,
-		Len:  sh.Len,
-		Cap:  sh.Len,
+	if len(s) == 0 {
+		return []byte{}
 	}
-	return *(*[]byte)(unsafe.Pointer(&bh))
+	return unsafe.Slice(*(**byte)(unsafe.Pointer(&s)), len(s))
 }
 
 // Bytes2String []byte to string
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+)
+
+// Uint128 定长128位无符号整数，用于IPv4/IPv6地址运算，避免big.Int的内存分配
+// 运算均按照2^128取模，需要调用方根据返回的carry/borrow判断是否溢出
+type Uint128 struct {
+	Hi uint64
+	Lo uint64
+}
+
+var MaxUint128 = Uint128{^uint64(0), ^uint64(0)}
+
+func NewUint128(v uint64) Uint128 {
+	return Uint128{0, v}
+}
+
+// Uint128FromBytes 按大端序解析，长度不足16字节时高位补0，超过16字节时只取最后16字节
+func Uint128FromBytes(b []byte) Uint128 {
+	var buf [16]byte
+	if len(b) > 16 {
+		b = b[len(b)-16:]
+	}
+	copy(buf[16-len(b):], b)
+	return Uint128{binary.BigEndian.Uint64(buf[:8]), binary.BigEndian.Uint64(buf[8:])}
+}
+
+// Uint128FromBig 将非负且不超过128位的big.Int转换为Uint128，不满足条件时ok为false
+func Uint128FromBig(i *big.Int) (u Uint128, ok bool) {
+	if i == nil || i.Sign() < 0 || i.BitLen() > 128 {
+		return
+	}
+
+	words := i.Bits()
+	if bits.UintSize == 64 {
+		if len(words) > 0 {
+			u.Lo = uint64(words[0])
+		}
+		if len(words) > 1 {
+			u.Hi = uint64(words[1])
+		}
+	} else {
+		for n := len(words) - 1; n >= 0; n-- {
+			u = u.Lsh(32)
+			u.Lo |= uint64(words[n])
+		}
+	}
+	return u, true
+}
+
+// PutBytes 按大端序写入b，只写入b的长度(4或16)对应的低位字节
+func (u Uint128) PutBytes(b []byte) {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], u.Hi)
+	binary.BigEndian.PutUint64(buf[8:], u.Lo)
+	if len(b) >= 16 {
+		copy(b[len(b)-16:], buf[:])
+	} else {
+		copy(b, buf[16-len(b):])
+	}
+}
+
+func (u Uint128) Bytes(n int) []byte {
+	b := make([]byte, n)
+	u.PutBytes(b)
+	return b
+}
+
+func (u Uint128) Big() *big.Int {
+	z := new(big.Int).SetUint64(u.Hi)
+	z.Lsh(z, 64)
+	return z.Or(z, new(big.Int).SetUint64(u.Lo))
+}
+
+func (u Uint128) String() string {
+	return u.Big().String()
+}
+
+func (u Uint128) IsZero() bool {
+	return u.Hi == 0 && u.Lo == 0
+}
+
+func (u Uint128) Cmp(o Uint128) int {
+	switch {
+	case u.Hi < o.Hi:
+		return -1
+	case u.Hi > o.Hi:
+		return 1
+	case u.Lo < o.Lo:
+		return -1
+	case u.Lo > o.Lo:
+		return 1
+	}
+	return 0
+}
+
+func (u Uint128) Equal(o Uint128) bool {
+	return u == o
+}
+
+func (u Uint128) Add(o Uint128) (Uint128, bool) {
+	lo, carry := bits.Add64(u.Lo, o.Lo, 0)
+	hi, carry := bits.Add64(u.Hi, o.Hi, carry)
+	return Uint128{hi, lo}, carry != 0
+}
+
+func (u Uint128) Sub(o Uint128) (Uint128, bool) {
+	lo, borrow := bits.Sub64(u.Lo, o.Lo, 0)
+	hi, borrow := bits.Sub64(u.Hi, o.Hi, borrow)
+	return Uint128{hi, lo}, borrow != 0
+}
+
+func (u Uint128) AddUint64(v uint64) (Uint128, bool) {
+	return u.Add(Uint128{0, v})
+}
+
+func (u Uint128) SubUint64(v uint64) (Uint128, bool) {
+	return u.Sub(Uint128{0, v})
+}
+
+func (u Uint128) Lsh(n uint) Uint128 {
+	switch {
+	case n >= 128:
+		return Uint128{}
+	case n >= 64:
+		return Uint128{u.Lo << (n - 64), 0}
+	case n == 0:
+		return u
+	}
+	return Uint128{u.Hi<<n | u.Lo>>(64-n), u.Lo << n}
+}
+
+func (u Uint128) Rsh(n uint) Uint128 {
+	switch {
+	case n >= 128:
+		return Uint128{}
+	case n >= 64:
+		return Uint128{0, u.Hi >> (n - 64)}
+	case n == 0:
+		return u
+	}
+	return Uint128{u.Hi >> n, u.Lo>>n | u.Hi<<(64-n)}
+}
+
+func (u Uint128) And(o Uint128) Uint128 {
+	return Uint128{u.Hi & o.Hi, u.Lo & o.Lo}
+}
+
+func (u Uint128) Or(o Uint128) Uint128 {
+	return Uint128{u.Hi | o.Hi, u.Lo | o.Lo}
+}
+
+func (u Uint128) Xor(o Uint128) Uint128 {
+	return Uint128{u.Hi ^ o.Hi, u.Lo ^ o.Lo}
+}
+
+func (u Uint128) Not() Uint128 {
+	return Uint128{^u.Hi, ^u.Lo}
+}
+
+// TrailingZeros 返回末尾连续0的个数，u为0时返回128
+func (u Uint128) TrailingZeros() int {
+	if u.Lo != 0 {
+		return bits.TrailingZeros64(u.Lo)
+	}
+	return 64 + bits.TrailingZeros64(u.Hi)
+}
+
+// BitLen 返回表示u所需的最少位数
+func (u Uint128) BitLen() int {
+	if u.Hi != 0 {
+		return 64 + bits.Len64(u.Hi)
+	}
+	return bits.Len64(u.Lo)
+}
+
+// Mask128 返回低n位全为1的值，n >= 128时返回MaxUint128
+func Mask128(n uint) Uint128 {
+	if n >= 128 {
+		return MaxUint128
+	}
+	return Uint128{}.Not().Lsh(n).Not()
+}
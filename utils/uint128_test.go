@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func randUint128(r *rand.Rand) Uint128 {
+	u := Uint128{r.Uint64(), r.Uint64()}
+	return u.Rsh(uint(r.Intn(128)))
+}
+
+func TestUint128(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	mod := new(big.Int).Lsh(big.NewInt(1), 128)
+
+	for i := 0; i < 10000; i++ {
+		a := randUint128(r)
+		b := randUint128(r)
+		ab := a.Big()
+		bb := b.Big()
+
+		if u, ok := Uint128FromBig(ab); !ok || u != a {
+			t.Fatalf("Uint128FromBig(%s) = %s, %t", ab, u, ok)
+		}
+
+		if a.Cmp(b) != ab.Cmp(bb) {
+			t.Errorf("%s.Cmp(%s) = %d, want = %d", a, b, a.Cmp(b), ab.Cmp(bb))
+		}
+
+		sum, carry := a.Add(b)
+		want := new(big.Int).Add(ab, bb)
+		if carry != (want.Cmp(mod) >= 0) || sum.Big().Cmp(want.Mod(want, mod)) != 0 {
+			t.Errorf("%s + %s = %s, carry = %t", a, b, sum, carry)
+		}
+
+		diff, borrow := a.Sub(b)
+		want = new(big.Int).Sub(ab, bb)
+		if borrow != (want.Sign() < 0) || diff.Big().Cmp(want.Mod(want, mod)) != 0 {
+			t.Errorf("%s - %s = %s, borrow = %t", a, b, diff, borrow)
+		}
+
+		n := uint(r.Intn(130))
+		want = new(big.Int).Lsh(ab, n)
+		if a.Lsh(n).Big().Cmp(want.Mod(want, mod)) != 0 {
+			t.Errorf("%s << %d = %s", a, n, a.Lsh(n))
+		}
+		if a.Rsh(n).Big().Cmp(new(big.Int).Rsh(ab, n)) != 0 {
+			t.Errorf("%s >> %d = %s", a, n, a.Rsh(n))
+		}
+
+		if Uint128FromBytes(a.Bytes(16)) != a {
+			t.Errorf("Uint128FromBytes(%x) != %s", a.Bytes(16), a)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+)
+
+// 组合多个Validator，比如:
+//
+//	// ip字段是IPv4或者IPv6地址，并且在subnet字段的网段中
+//	vc.Add(AnyOf(Ipv4Validator{}, Ipv6Validator{}))
+//	vc.Add(InSubnet("ip", "subnet"))
+//	// hops字段中的每个元素都是IP地址
+//	vc.Add(ForEach("hops", AnyOf(...)))
+
+// ValidatorFunc 将函数转换为Validator
+type ValidatorFunc func(data map[string]interface{}) Result
+
+func (f ValidatorFunc) Validate(data map[string]interface{}) Result {
+	return f(data)
+}
+
+// Predicate When的条件
+type Predicate func(data map[string]interface{}) bool
+
+// HasField data中存在key并且不为nil
+func HasField(key string) Predicate {
+	return func(data map[string]interface{}) bool {
+		return data[key] != nil
+	}
+}
+
+// FieldEquals data[key]等于value
+func FieldEquals(key string, value interface{}) Predicate {
+	return func(data map[string]interface{}) bool {
+		v, ok := data[key]
+		return ok && reflect.DeepEqual(v, value)
+	}
+}
+
+// 以下组合出来的Validator都实现了ContextValidator，ctx会传递给vs
+
+// AllOf 依次执行vs，全部通过时通过，返回所有的错误
+func AllOf(vs ...Validator) Validator {
+	return ContextValidatorFunc(func(ctx context.Context, data map[string]interface{}) Result {
+		vc := &ValidateChain{Chain: vs}
+		return vc.ValidateContext(ctx, data)
+	})
+}
+
+// AnyOf 依次执行vs，任意一个通过时通过，否则返回所有的错误
+func AnyOf(vs ...Validator) Validator {
+	return ContextValidatorFunc(func(ctx context.Context, data map[string]interface{}) Result {
+		result := NewCodeResult("any_of", nil, nil)
+		for _, v := range vs {
+			r := ValidateContext(ctx, v, data)
+			if r.Status() {
+				return r
+			}
+			result.AddError(r)
+		}
+		return result
+	})
+}
+
+// Not v通过时失败，v失败时通过
+func Not(v Validator) Validator {
+	return ContextValidatorFunc(func(ctx context.Context, data map[string]interface{}) Result {
+		if ValidateContext(ctx, v, data).Status() {
+			return NewCodeResult("not", nil, map[string]interface{}{"validator": fmt.Sprintf("%T", v)})
+		}
+		return NewValidateResult(true, "")
+	})
+}
+
+// When pred成立时执行v，否则直接通过
+func When(pred Predicate, v Validator) Validator {
+	return ContextValidatorFunc(func(ctx context.Context, data map[string]interface{}) Result {
+		if !pred(data) {
+			return NewValidateResult(true, "")
+		}
+		return ValidateContext(ctx, v, data)
+	})
+}
+
+// ForEach data[key]为slice或者array，将每个元素放到data[key]中执行v，
+// 返回的错误的字段路径以key[i]开头
+func ForEach(key string, v Validator) Validator {
+	return ContextValidatorFunc(func(ctx context.Context, data map[string]interface{}) Result {
+		value, ok := data[key]
+		if !ok || value == nil {
+			return NewCodeResult("field_empty", nil, map[string]interface{}{"key": key, "data": data})
+		}
+		list := reflect.ValueOf(value)
+		if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+			return NewFieldError(key, "type_list", value, map[string]interface{}{"type": list.Type().String()}, "")
+		}
+
+		result := NewValidateResult(true, "")
+		for i := 0; i < list.Len(); i++ {
+			m := make(map[string]interface{}, len(data))
+			for k, v := range data {
+				m[k] = v
+			}
+			m[key] = list.Index(i).Interface()
+			if r := ValidateContext(ctx, v, m); !r.Status() {
+				result.AddError(WithPath(r, fmt.Sprintf("%s[%d]", key, i)))
+			}
+		}
+		return result
+	})
+}
+
+// WithPath 在r中错误的字段路径前加上path，比如ip变为hops[1].ip，没有字段路径的错误使用path。
+// 返回r的副本，不修改r
+func WithPath(r Result, path string) Result {
+	vr, ok := r.(*validateResult)
+	if !ok {
+		return r
+	}
+	c := *vr
+	if len(c.errors) == 0 {
+		if !c.status {
+			c.field = joinPath(path, c.field)
+		}
+		return &c
+	}
+	c.errors = make([]Result, len(vr.errors))
+	for i, e := range vr.errors {
+		c.errors[i] = WithPath(e, path)
+	}
+	return &c
+}
+
+// CrossFieldValidator 比较data[Key]与data[Other]，Check返回false时失败，错误码为Code
+type CrossFieldValidator struct {
+	Key   string
+	Other string
+	Code  string
+	Check func(value interface{}, other interface{}) bool
+}
+
+func (cv CrossFieldValidator) Validate(data map[string]interface{}) Result {
+	for _, key := range []string{cv.Key, cv.Other} {
+		if _, ok := data[key]; !ok {
+			return NewCodeResult("field_empty", nil, map[string]interface{}{"key": key, "data": data})
+		}
+	}
+	value, other := data[cv.Key], data[cv.Other]
+	if !cv.Check(value, other) {
+		params := map[string]interface{}{"other": cv.Other, "other_value": other}
+		return NewFieldError(cv.Key, cv.Code, value, params, "")
+	}
+	return NewValidateResult(true, "")
+}
+
+// InSubnet data[ipKey]为IP地址，并且在data[subnetKey]表示的网段中，网段为CIDR字符串或者*net.IPNet。
+// 其他类型通过fmt.Stringer转换为字符串后解析，比如network.IP、network.IPNet
+func InSubnet(ipKey string, subnetKey string) Validator {
+	return CrossFieldValidator{
+		Key:   ipKey,
+		Other: subnetKey,
+		Code:  "in_subnet",
+		Check: func(value interface{}, other interface{}) bool {
+			s, ok := stringOf(value)
+			if !ok {
+				return false
+			}
+			b, err := ParseIPAddress(s)
+			if err != nil {
+				return false
+			}
+			ip := net.IP(b)
+
+			var subnet *net.IPNet
+			switch o := other.(type) {
+			case *net.IPNet:
+				subnet = o
+			case net.IPNet:
+				subnet = &o
+			default:
+				s, ok := stringOf(other)
+				if !ok {
+					return false
+				}
+				b, prefix, err := ParseCIDR(s, false)
+				if err != nil {
+					return false
+				}
+				mask := net.CIDRMask(prefix, len(b)*8)
+				subnet = &net.IPNet{IP: net.IP(b).Mask(mask), Mask: mask}
+			}
+			return subnet != nil && subnet.Contains(ip)
+		},
+	}
+}
+
+// stringOf 返回字符串或者fmt.Stringer的值，String方法定义在指针上的非指针值也可以使用
+func stringOf(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case nil:
+		return "", false
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", false
+		}
+	} else {
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		rv = p
+	}
+	if s, ok := rv.Interface().(fmt.Stringer); ok {
+		return s.String(), true
+	}
+	return "", false
+}
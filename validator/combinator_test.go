@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"net"
+	"testing"
+)
+
+var combinatorTestList = []map[string]interface{}{
+	{"validator": AnyOf(Ipv4Validator{}, Ipv6Validator{}), "ip": "1.1.1.1", "want": true},
+	{"validator": AnyOf(Ipv4Validator{}, Ipv6Validator{}), "ip": "2001:db8::1", "want": true},
+	{"validator": AnyOf(Ipv4Validator{}, Ipv6Validator{}), "ip": "1.1.1", "want": false},
+	{"validator": AnyOf(), "ip": "1.1.1.1", "want": false},
+	{"validator": AllOf(Ipv6Validator{}, Not(Ipv4Validator{})), "ip": "2001:db8::1", "want": true},
+	{"validator": AllOf(Ipv6Validator{}, Not(Ipv4Validator{})), "ip": "1.1.1.1", "want": false},
+	{"validator": AllOf(), "ip": "1.1.1", "want": true},
+	{"validator": Not(Ipv4Validator{}), "ip": "1.1.1.1", "want": false},
+	{"validator": Not(Ipv4Validator{}), "ip": "1.1.1", "want": true},
+
+	{"validator": When(HasField("mac"), MacValidator{}), "ip": "1.1.1.1", "want": true},
+	{"validator": When(HasField("mac"), MacValidator{}), "mac": "xx", "want": false},
+	{"validator": When(FieldEquals("range", true), VlanValidator{}), "vlan": "1-10", "want": true},
+	{"validator": When(FieldEquals("range", true), VlanValidator{}), "vlan": "1-10", "range": true, "want": true},
+	{"validator": When(FieldEquals("range", true), VlanValidator{}), "vlan": "10-1", "range": true, "want": false},
+
+	{"validator": ForEach("ip", Ipv4Validator{}), "ip": []string{"1.1.1.1", "2.2.2.2"}, "want": true},
+	{"validator": ForEach("ip", Ipv4Validator{}), "ip": []interface{}{"1.1.1.1", "2.2.2"}, "want": false},
+	{"validator": ForEach("ip", Ipv4Validator{}), "ip": [0]string{}, "want": true},
+	{"validator": ForEach("ip", Ipv4Validator{}), "ip": "1.1.1.1", "want": false},
+	{"validator": ForEach("ip", Ipv4Validator{}), "want": false},
+
+	{"validator": InSubnet("ip", "subnet"), "ip": "10.1.1.1", "subnet": "10.0.0.0/8", "want": true},
+	{"validator": InSubnet("ip", "subnet"), "ip": "11.1.1.1", "subnet": "10.0.0.0/8", "want": false},
+	{"validator": InSubnet("ip", "subnet"), "ip": "2001:db8::1", "subnet": "2001:db8::/32", "want": true},
+	{"validator": InSubnet("ip", "subnet"), "ip": "10.1.1.1", "subnet": mustCIDR("10.1.0.0/16"), "want": true},
+	{"validator": InSubnet("ip", "subnet"), "ip": "10.2.1.1", "subnet": *mustCIDR("10.1.0.0/16"), "want": false},
+	{"validator": InSubnet("ip", "subnet"), "ip": "10.1.1.1", "subnet": "10.0.0.0/33", "want": false},
+	{"validator": InSubnet("ip", "subnet"), "ip": "10.1.1.1", "want": false},
+	{"validator": InSubnet("ip", "subnet"), "ip": net.ParseIP("10.1.1.1"), "subnet": stringerCIDR{"10.0.0.0/8"}, "want": true},
+	{"validator": InSubnet("ip", "subnet"), "ip": "10.1.1.1", "subnet": &stringerCIDR{"11.0.0.0/8"}, "want": false},
+	{"validator": InSubnet("ip", "subnet"), "ip": "10.1.1.1", "subnet": (*stringerCIDR)(nil), "want": false},
+	{"validator": InSubnet("ip", "subnet"), "ip": 1, "subnet": "10.0.0.0/8", "want": false},
+}
+
+// stringerCIDR 与network.IPNet相同，String方法定义在指针上
+type stringerCIDR struct {
+	s string
+}
+
+func (c *stringerCIDR) String() string {
+	return c.s
+}
+
+func mustCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func TestCombinators(t *testing.T) {
+	for _, data := range combinatorTestList {
+		result := data["validator"].(Validator).Validate(data)
+		if result.Status() != data["want"] {
+			t.Errorf("Test %+v, got = %+v, want = %+v, msg = %s", data, result.Status(), data["want"], result.Msg())
+		}
+	}
+}
+
+func TestCombinatorFields(t *testing.T) {
+	vc := NewValidateChain()
+	vc.Add(ForEach("ip", AnyOf(Ipv4Validator{}, Ipv6Validator{})))
+	vc.Add(InSubnet("gateway", "subnet"))
+	data := map[string]interface{}{
+		"ip":      []string{"1.1.1.1", "1.1.1"},
+		"gateway": "11.1.1.1",
+		"subnet":  "10.0.0.0/8",
+	}
+	// ForEach在data副本中替换key，不修改data
+	defer func() {
+		if _, ok := data["ip"].([]string); !ok {
+			t.Errorf("data modified: %+v", data)
+		}
+	}()
+
+	failures := Failures(vc.Validate(data))
+	testCases := []map[string]interface{}{
+		{"field": "ip[1]", "code": "ip_octet_count", "msg": "ip[1]: ipv4 address needs 4 octets, got 3, ip:1.1.1"},
+		{"field": "ip[1]", "code": "ip_octet_count"},
+		{"field": "gateway", "code": "in_subnet", "msg": "gateway: 11.1.1.1 is not in subnet: 10.0.0.0/8"},
+	}
+	if len(failures) != len(testCases) {
+		t.Fatalf("len(failures) = %d, want = %d", len(failures), len(testCases))
+	}
+	for i, tc := range testCases {
+		f := failures[i]
+		if f.Field() != tc["field"] || f.Code() != tc["code"] {
+			t.Errorf("failure %d, field = %s, code = %s, want = %+v", i, f.Field(), f.Code(), tc)
+		}
+		if msg, ok := tc["msg"]; ok && f.Msg() != msg {
+			t.Errorf("failure %d, msg = %s, want = %s", i, f.Msg(), msg)
+		}
+	}
+}
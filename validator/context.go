@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ContextValidator 需要访问数据库、IPAM等外部系统的Validator，ctx取消或者超时时应该尽快返回。
+// ValidateChain以及组合出来的Validator都实现了ContextValidator，会把ctx传递下去
+type ContextValidator interface {
+	ValidateContext(ctx context.Context, data map[string]interface{}) Result
+}
+
+// ContextValidatorFunc 将函数转换为同时实现Validator和ContextValidator的类型，
+// 作为Validator使用时ctx为context.Background()
+type ContextValidatorFunc func(ctx context.Context, data map[string]interface{}) Result
+
+func (f ContextValidatorFunc) Validate(data map[string]interface{}) Result {
+	return f(context.Background(), data)
+}
+
+func (f ContextValidatorFunc) ValidateContext(ctx context.Context, data map[string]interface{}) Result {
+	return f(ctx, data)
+}
+
+// WithContext 将ContextValidator转换为Validator，以便加入ValidateChain
+func WithContext(v ContextValidator) Validator {
+	return ContextValidatorFunc(v.ValidateContext)
+}
+
+// ValidateContext v实现了ContextValidator时传入ctx，否则直接调用Validate
+func ValidateContext(ctx context.Context, v Validator, data map[string]interface{}) Result {
+	if cv, ok := v.(ContextValidator); ok {
+		return cv.ValidateContext(ctx, data)
+	}
+	return v.Validate(data)
+}
+
+// contextResult ctx结束时还没有返回的Validator的结果
+func contextResult(ctx context.Context, v Validator) Result {
+	code := "canceled"
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		code = "deadline_exceeded"
+	}
+	return NewCodeResult(code, nil, map[string]interface{}{"validator": fmt.Sprintf("%T", v), "err": ctx.Err()})
+}
+
+// run ctx已经结束时不再执行v，done为false。
+// ContextValidator在新的goroutine中执行，ctx结束时不再等待；
+// 没有实现ContextValidator的v无法被中断，直接在当前goroutine中执行，不会在后台残留
+func run(ctx context.Context, v Validator, data map[string]interface{}) (result Result, done bool) {
+	if ctx.Err() != nil {
+		return contextResult(ctx, v), false
+	}
+	cv, ok := v.(ContextValidator)
+	if !ok || ctx.Done() == nil {
+		return ValidateContext(ctx, v, data), true
+	}
+	ch := make(chan Result, 1)
+	go func() {
+		ch <- cv.ValidateContext(ctx, data)
+	}()
+	return wait(ctx, v, ch)
+}
+
+// wait 优先使用已经返回的结果，ctx结束时返回contextResult，done为false
+func wait(ctx context.Context, v Validator, ch <-chan Result) (result Result, done bool) {
+	select {
+	case r := <-ch:
+		return r, true
+	default:
+	}
+	select {
+	case r := <-ch:
+		return r, true
+	case <-ctx.Done():
+		return contextResult(ctx, v), false
+	}
+}
@@ -0,0 +1,170 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sleepValidator 等待d以后返回，status为false时错误码为code
+type sleepValidator struct {
+	d      time.Duration
+	status bool
+	code   string
+}
+
+func (v sleepValidator) Validate(data map[string]interface{}) Result {
+	time.Sleep(v.d)
+	if v.status {
+		return NewValidateResult(true, "")
+	}
+	return NewCodeResult(v.code, nil, nil)
+}
+
+// blockValidator 一直等到ctx结束
+func blockValidator(ctx context.Context, data map[string]interface{}) Result {
+	<-ctx.Done()
+	return NewCodeResult("blocked", nil, nil)
+}
+
+func codes(r Result) []string {
+	list := []string{}
+	for _, f := range Failures(r) {
+		list = append(list, f.Code())
+	}
+	return list
+}
+
+func TestValidateConcurrent(t *testing.T) {
+	// 后面的Validator先返回，结果仍然按照Chain中的顺序排列
+	vc := NewValidateChain().Concurrency(4)
+	for i := 0; i < 4; i++ {
+		vc.Add(sleepValidator{d: time.Duration(4-i) * 20 * time.Millisecond, code: fmt.Sprint(i)})
+	}
+	start := time.Now()
+	r := vc.Validate(map[string]interface{}{})
+	if d := time.Since(start); d > 150*time.Millisecond {
+		t.Errorf("concurrent validate took %s", d)
+	}
+	if got := fmt.Sprint(codes(r)); got != "[0 1 2 3]" {
+		t.Errorf("codes = %s", got)
+	}
+
+	vc.stopOnError = true
+	if r := vc.Validate(map[string]interface{}{}); r.Code() != "0" {
+		t.Errorf("stopOnError, code = %s", r.Code())
+	}
+}
+
+func TestValidateConcurrencyLimit(t *testing.T) {
+	var running, max int32
+	v := ValidatorFunc(func(data map[string]interface{}) Result {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return NewValidateResult(true, "")
+	})
+
+	vc := NewValidateChain().Concurrency(3)
+	for i := 0; i < 12; i++ {
+		vc.Add(v)
+	}
+	if r := vc.Validate(map[string]interface{}{}); !r.Status() {
+		t.Fatalf("msg = %s", r.Msg())
+	}
+	if max > 3 || max < 2 {
+		t.Errorf("max running = %d, want <= 3", max)
+	}
+}
+
+func TestValidateDeadline(t *testing.T) {
+	for _, n := range []int{1, 4} {
+		vc := NewValidateChain().Concurrency(n)
+		vc.Add(sleepValidator{status: true})
+		vc.AddContext(ContextValidatorFunc(blockValidator))
+		vc.Add(sleepValidator{d: time.Second, status: true})
+		vc.Add(AllOf(WithContext(ContextValidatorFunc(blockValidator))))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		start := time.Now()
+		r := vc.ValidateContext(ctx, map[string]interface{}{})
+		cancel()
+		if d := time.Since(start); d > 500*time.Millisecond {
+			t.Errorf("concurrency %d, validate took %s", n, d)
+		}
+
+		// 第二个Validator在ctx结束时返回deadline_exceeded，或者还没有返回，之后的Validator都不再出现在结果中
+		got := codes(r)
+		if len(got) != 1 || got[0] != "deadline_exceeded" {
+			t.Errorf("concurrency %d, codes = %v", n, got)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	vc := NewValidateChain()
+	vc.Add(sleepValidator{status: true})
+	if r := vc.ValidateContext(ctx, map[string]interface{}{}); r.Status() || Failures(r)[0].Code() != "canceled" {
+		t.Errorf("canceled, msg = %s", r.Msg())
+	}
+}
+
+// ctx结束后不再启动剩余的Validator
+func TestValidateStopScheduling(t *testing.T) {
+	for _, n := range []int{1, 2} {
+		ctx, cancel := context.WithCancel(context.Background())
+		var started int32
+		counter := ValidatorFunc(func(data map[string]interface{}) Result {
+			atomic.AddInt32(&started, 1)
+			return NewValidateResult(true, "")
+		})
+
+		vc := NewValidateChain().Concurrency(n)
+		vc.Add(ValidatorFunc(func(data map[string]interface{}) Result {
+			cancel()
+			return NewValidateResult(true, "")
+		}))
+		vc.AddContext(ContextValidatorFunc(blockValidator))
+		for i := 0; i < 10; i++ {
+			vc.Add(counter)
+		}
+
+		r := vc.ValidateContext(ctx, map[string]interface{}{})
+		if got := codes(r); len(got) != 1 || got[0] != "canceled" {
+			t.Errorf("concurrency %d, codes = %v", n, got)
+		}
+		if s := atomic.LoadInt32(&started); s != 0 {
+			t.Errorf("concurrency %d, %d validators started after cancel", n, s)
+		}
+	}
+}
+
+// 顺序执行时没有实现ContextValidator的Validator在当前goroutine中执行，返回时已经结束
+func TestValidatePlainInline(t *testing.T) {
+	var finished int32
+	vc := NewValidateChain()
+	vc.Add(ValidatorFunc(func(data map[string]interface{}) Result {
+		time.Sleep(50 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+		return NewValidateResult(true, "")
+	}))
+	vc.Add(sleepValidator{status: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	r := vc.ValidateContext(ctx, map[string]interface{}{})
+	if atomic.LoadInt32(&finished) != 1 {
+		t.Errorf("plain validator still running after ValidateContext returned")
+	}
+	if got := codes(r); len(got) != 1 || got[0] != "deadline_exceeded" {
+		t.Errorf("codes = %v", got)
+	}
+}
@@ -2,222 +2,107 @@ package validator
 
 import (
 	"bytes"
-	"fmt"
-	"net"
+	"errors"
 	"regexp"
-	"strconv"
 	"strings"
-
-	"github.com/mitchellh/mapstructure"
+	"tools/i18n"
 )
 
 type Ipv4Validator struct{}
 type Ipv6Validator struct{}
 
-func ipValidate(data string, ipv4, withPrefix bool) Result {
-	v4_regex := `^\s*((([0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5])\.){3}([0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5]))\s*$`
-	v4_prefix := `^([0-9]|(1[0-9])|(2[0-9])|(3[0-2]))$`
-
-	v6_prefix := `^([0-9]|([1-9][0-9])|(1[0-1][0-9])|(12[0-8]))$`
-	v6_regex := `^(([0-9a-fA-F]{1,4}:){7,7}[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,7}:|([0-9a-fA-F]{1,4}:){1,6}:[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,5}(:[0-9a-fA-F]{1,4}){1,2}|([0-9a-fA-F]{1,4}:){1,4}(:[0-9a-fA-F]{1,4}){1,3}|([0-9a-fA-F]{1,4}:){1,3}(:[0-9a-fA-F]{1,4}){1,4}|([0-9a-fA-F]{1,4}:){1,2}(:[0-9a-fA-F]{1,4}){1,5}|[0-9a-fA-F]{1,4}:((:[0-9a-fA-F]{1,4}){1,6})|:((:[0-9a-fA-F]{1,4}){1,7}|:)|fe80:(:[0-9a-fA-F]{0,4}){0,4}%[0-9a-zA-Z]{1,}|::(ffff(:0{1,4}){0,1}:){0,1}((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])\.){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])|([0-9a-fA-F]{1,4}:){1,4}:((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])\.){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9]))$`
-
-	ip := data
-	prefix := ""
+// ipValidate withPrefix为true时data为"ip/prefix"，strict为true时还要求主机位全部为0。
+// 失败时错误码为ParseIPAddress、ParseCIDR返回的具体原因，不是*i18n.Error时为ipv4或者ipv6
+func ipValidate(data string, ipv4, withPrefix, strict bool) Result {
+	var ip []byte
+	var err error
 	if withPrefix {
-		ds := strings.Split(data, "/")
-		if len(ds) != 2 {
-			return NewValidateResult(false, fmt.Sprintf("ip address format error, ip:%s", data))
-		}
-		ip = ds[0]
-		prefix = ds[1]
-	}
-
-	regex := ""
-	pxRegex := ""
-	if ipv4 {
-		regex = v4_regex
-		pxRegex = v4_prefix
+		ip, _, err = ParseCIDR(data, strict)
 	} else {
-		regex = v6_regex
-		pxRegex = v6_prefix
+		ip, err = ParseIPAddress(data)
 	}
-
-	re := regexp.MustCompile(regex)
-	if withPrefix == false {
-		return NewValidateResult(re.MatchString(ip), "")
-	}
-	if re.MatchString(ip) == false {
-		return NewValidateResult(false, fmt.Sprintf("ip address valide error, ip:%s", data))
+	if err == nil {
+		if ipv4 && len(ip) != IPV4_LEN {
+			err = ipError("ip_family", data, map[string]interface{}{"family": "ipv4"})
+		} else if !ipv4 && len(ip) != IPV6_LEN {
+			err = ipError("ip_family", data, map[string]interface{}{"family": "ipv6"})
+		}
 	}
-	if regexp.MustCompile(pxRegex).MatchString(prefix) {
-		return NewValidateResult(true, "")
-	} else {
-		return NewValidateResult(false, fmt.Sprintf("ip address prefix valide error: ip:%s", data))
+	if err != nil {
+		var e *i18n.Error
+		if errors.As(err, &e) {
+			return NewCodeResult(e.Code, data, e.Params)
+		}
+		code := "ipv6"
+		if ipv4 {
+			code = "ipv4"
+		}
+		return NewCodeResult(code, data, map[string]interface{}{"err": err})
 	}
+	return NewValidateResult(true, "")
 }
 
-//
-
-func (v Ipv4Validator) Validate(data map[string]interface{}) Result {
-	m := map[string]interface{}{}
-	mapstructure.Decode(&data, &m)
-
-	_, ok := m["withPrefix"]
-	withPrefix := false
-	if ok {
-		withPrefix = m["withPrefix"].(bool)
+// validateIP data["ip"]为地址，data["withPrefix"]、data["strict"]见ipValidate
+func validateIP(data map[string]interface{}, ipv4 bool) Result {
+	v, ok := data["ip"]
+	if !ok {
+		return NewCodeResult("field_empty", nil, map[string]interface{}{"key": "ip", "data": data})
 	}
-
-	ip, ok := m["ip"]
-
-	if ok == false {
-		return NewValidateResult(false, fmt.Sprintf("ip field is empty, data:%+v", m))
+	ip, ok := v.(string)
+	if !ok {
+		return NewCodeResult("field_not_string", v, map[string]interface{}{"key": "ip", "data": data})
 	}
+	withPrefix, _ := data["withPrefix"].(bool)
+	strict, _ := data["strict"].(bool)
+	return ipValidate(ip, ipv4, withPrefix, strict)
+}
 
-	return ipValidate(ip.(string), true, withPrefix)
+func (v Ipv4Validator) Validate(data map[string]interface{}) Result {
+	return validateIP(data, true)
 }
 
+// Validate 只接受IPv6地址，包括::ffff:1.2.3.4这样嵌入IPv4的形式。
+// 与net.ParseIP一致，IPv4地址、带zone的地址(fe80::1%eth0)以及首尾有空白的地址都不通过
 func (v Ipv6Validator) Validate(data map[string]interface{}) Result {
-	m := map[string]interface{}{}
-	mapstructure.Decode(&data, &m)
-
-	_, ok := m["withPrefix"]
-	withPrefix := false
-	if ok {
-		withPrefix = m["withPrefix"].(bool)
-	}
-	ip, ok := m["ip"]
-
-	if ok == false {
-		return NewValidateResult(false, fmt.Sprintf("ip field is empty, data:%+v", m))
-	}
-
-	prefix := ""
-	if withPrefix {
-		ds := strings.Split(ip.(string), "/")
-		if len(ds) != 2 {
-			return NewValidateResult(false, fmt.Sprintf("ip address format error, ip:%s", ip))
-		}
-		ip = ds[0]
-		prefix = ds[1]
-	}
-
-	s := net.ParseIP(ip.(string))
-	if s == nil {
-		return NewValidateResult(false, fmt.Sprintf("ip address format error, ip:%s", m["ip"]))
-	}
-
-	if withPrefix {
-		p, err := strconv.Atoi(prefix)
-		if err != nil {
-			return NewValidateResult(false, fmt.Sprintf("ip address format error, ip:%s", m["ip"]))
-		}
-		if p < 0 || p > 128 {
-			return NewValidateResult(false, fmt.Sprintf("ip address format error, ip:%s", m["ip"]))
-		}
-	}
-
-	return NewValidateResult(true, "")
-
-	// return ipValidate(ip.(string), false, withPrefix)
+	return validateIP(data, false)
 }
 
+// IsIPv4Address 与IsIPv6Address都不去掉首尾的空白，也不接受zone
 func IsIPv4Address(ip string) bool {
-	if strings.Index(ip, ":") > -1 {
-		return false
-	}
-
-	s := net.ParseIP(ip)
-	if s == nil {
-		return false
-	}
-
-	if strings.Index(ip, ".") > -1 {
-		return true
-	} else {
-		return false
-	}
+	b, err := ParseIPAddress(ip)
+	return err == nil && len(b) == IPV4_LEN
 }
 
 func IsIPv6Address(ip string) bool {
-	if strings.Index(ip, ":") == -1 {
-		return false
-	}
-
-	s := net.ParseIP(ip)
-	if s == nil {
-		return false
-	}
-
-	if strings.Index(ip, ":") > -1 {
-		return true
-	} else {
-		return false
-	}
+	b, err := ParseIPAddress(ip)
+	return err == nil && len(b) == IPV6_LEN
 }
 
-// } else if validator.IsIPv4AddressWithMask(s) || validator.IsIPv6AddressWithMask(s) {
-func IsIPv4AddressWithMask(data string) bool {
-
-	index := strings.Index(data, "/")
-	if index == -1 {
-		return false
-	}
-
+// isAddressWithMask "ip/prefix"或者"ip/mask"，mask只要求是同一地址族的地址
+func isAddressWithMask(data string, isAddress func(s string) bool, max int) bool {
 	tokens := strings.Split(data, "/")
 	if len(tokens) != 2 {
 		return false
 	}
 
-	if IsIPv4Address(tokens[0]) == false {
+	if isAddress(tokens[0]) == false {
 		return false
 	}
 
-	if prefix, err := strconv.Atoi(tokens[1]); err == nil {
-		if prefix < 0 || prefix > 32 {
-			return false
-		}
+	if _, err := ParsePrefix(tokens[1], max, data); err == nil {
 		return true
 	}
 
-	if IsIPv4Address(tokens[1]) == false {
-		return false
-	}
-
-	return true
-
+	return isAddress(tokens[1])
 }
 
 // } else if validator.IsIPv4AddressWithMask(s) || validator.IsIPv6AddressWithMask(s) {
+func IsIPv4AddressWithMask(data string) bool {
+	return isAddressWithMask(data, IsIPv4Address, 32)
+}
 
 func IsIPv6AddressWithMask(data string) bool {
-	index := strings.Index(data, "/")
-	if index == -1 {
-		return false
-	}
-
-	tokens := strings.Split(data, "/")
-	if len(tokens) != 2 {
-		return false
-	}
-
-	if IsIPv6Address(tokens[0]) == false {
-		return false
-	}
-
-	if prefix, err := strconv.Atoi(tokens[1]); err == nil {
-		if prefix < 0 || prefix > 128 {
-			return false
-		}
-		return true
-	}
-
-	if IsIPv6Address(tokens[1]) == false {
-		return false
-	}
-
-	return true
-
+	return isAddressWithMask(data, IsIPv6Address, 128)
 }
 
 func IsIPRange(ip string) bool {
@@ -226,21 +111,16 @@ func IsIPRange(ip string) bool {
 		return false
 	}
 
-	if (IsIPv4Address(tokens[0]) && IsIPv4Address(tokens[1])) ||
-		(IsIPv6Address(tokens[0]) && IsIPv6Address(tokens[1])) {
-
-		ip1 := net.ParseIP(tokens[0])
-		ip2 := net.ParseIP(tokens[1])
-
-		if bytes.Compare(ip1, ip2) <= 0 {
-			return true
-		} else {
-			return false
-		}
+	ip1, err := ParseIPAddress(tokens[0])
+	if err != nil {
+		return false
+	}
+	ip2, err := ParseIPAddress(tokens[1])
+	if err != nil || len(ip1) != len(ip2) {
+		return false
 	}
 
-	return false
-
+	return bytes.Compare(ip1, ip2) <= 0
 }
 
 func IsInt(s string) bool {
@@ -81,6 +81,17 @@ var ipv4TestList = []map[string]interface{}{
 		"withPrefix": false,
 		"want":       false,
 	},
+	// 与net.ParseIP一致，不再去掉首尾的空白
+	{
+		"ip":         " 192.168.1.1 ",
+		"withPrefix": false,
+		"want":       false,
+	},
+	{
+		"ip":         "192.168.1.0 /24",
+		"withPrefix": true,
+		"want":       false,
+	},
 }
 
 func TestIpv4(t *testing.T) {
@@ -185,6 +196,27 @@ var ipv6TestList = []map[string]interface{}{
 		"withPrefix": false,
 		"want":       false,
 	},
+	// 不接受zone、IPv4地址以及首尾的空白
+	{
+		"ip":         "fe80::1%eth0",
+		"withPrefix": false,
+		"want":       false,
+	},
+	{
+		"ip":         "192.168.1.1",
+		"withPrefix": false,
+		"want":       false,
+	},
+	{
+		"ip":         "192.168.1.0/24",
+		"withPrefix": true,
+		"want":       false,
+	},
+	{
+		"ip":         " ::1",
+		"withPrefix": false,
+		"want":       false,
+	},
 }
 
 func TestIpv6(t *testing.T) {
@@ -197,3 +229,20 @@ func TestIpv6(t *testing.T) {
 	}
 
 }
+
+func TestIsIPAddress(t *testing.T) {
+	testList := []map[string]interface{}{
+		{"ip": "192.168.1.1", "v4": true, "v6": false},
+		{"ip": "::ffff:192.168.1.1", "v4": false, "v6": true},
+		{"ip": "fe80::1", "v4": false, "v6": true},
+		{"ip": "fe80::1%eth0", "v4": false, "v6": false},
+		{"ip": " 192.168.1.1", "v4": false, "v6": false},
+		{"ip": "::1 ", "v4": false, "v6": false},
+	}
+	for _, data := range testList {
+		ip := data["ip"].(string)
+		if IsIPv4Address(ip) != data["v4"] || IsIPv6Address(ip) != data["v6"] {
+			t.Errorf("Test %q, got = %v/%v, want = %v/%v", ip, IsIPv4Address(ip), IsIPv6Address(ip), data["v4"], data["v6"])
+		}
+	}
+}
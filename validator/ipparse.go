@@ -0,0 +1,286 @@
+package validator
+
+import (
+	"strings"
+	"tools/i18n"
+)
+
+// 手写的IP地址解析，validator与network共用，接受的格式与net.ParseIP、net.ParseCIDR一致:
+// IPv4每段为不以0开头的十进制数，IPv6不支持zone("%eth0")。
+// 解析失败时返回*i18n.Error，Code为具体的原因，Params中ip为原始字符串
+
+const (
+	IPV4_LEN = 4
+	IPV6_LEN = 16
+)
+
+func ipError(code string, ip string, params map[string]interface{}) error {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	params["ip"] = ip
+	return i18n.NewError(code, params)
+}
+
+// ParseIPv4 返回4字节的地址
+func ParseIPv4(s string) ([]byte, error) {
+	if s == "" {
+		return nil, ipError("ip_empty", s, nil)
+	}
+	return parseIPv4(s, s)
+}
+
+// parseIPv4 orig用于错误信息，IPv6中嵌入的IPv4地址使用完整的IPv6字符串
+func parseIPv4(s string, orig string) ([]byte, error) {
+	ip := make([]byte, IPV4_LEN)
+	n := 0
+	for start := 0; start <= len(s); {
+		end := strings.IndexByte(s[start:], '.')
+		if end == -1 {
+			end = len(s)
+		} else {
+			end += start
+		}
+		if n == IPV4_LEN {
+			return nil, ipError("ip_octet_count", orig, map[string]interface{}{"count": strings.Count(s, ".") + 1})
+		}
+		octet := s[start:end]
+		v, ok := parseOctet(octet)
+		if !ok {
+			return nil, ipError("ip_bad_octet", orig, map[string]interface{}{"octet": octet})
+		}
+		ip[n] = v
+		n++
+		start = end + 1
+	}
+	if n != IPV4_LEN {
+		return nil, ipError("ip_octet_count", orig, map[string]interface{}{"count": n})
+	}
+	return ip, nil
+}
+
+// parseOctet 1到3位十进制数，不能以0开头，不超过255
+func parseOctet(s string) (byte, bool) {
+	if s == "" || len(s) > 3 || (len(s) > 1 && s[0] == '0') {
+		return 0, false
+	}
+	v := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+		v = v*10 + int(s[i]-'0')
+	}
+	if v > 255 {
+		return 0, false
+	}
+	return byte(v), true
+}
+
+func hexValue(c byte) (int, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0'), true
+	case 'a' <= c && c <= 'f':
+		return int(c-'a') + 10, true
+	case 'A' <= c && c <= 'F':
+		return int(c-'A') + 10, true
+	}
+	return 0, false
+}
+
+// ParseIPv6 返回16字节的地址，最后32位可以是点分十进制的IPv4地址
+func ParseIPv6(s string) ([]byte, error) {
+	orig := s
+	if s == "" {
+		return nil, ipError("ip_empty", orig, nil)
+	}
+
+	ip := make([]byte, IPV6_LEN)
+	ellipsis := -1
+	if len(s) >= 2 && s[0] == ':' && s[1] == ':' {
+		ellipsis = 0
+		s = s[2:]
+		if s == "" {
+			return ip, nil
+		}
+	}
+
+	i := 0
+	for i < IPV6_LEN {
+		off, acc := 0, 0
+		for ; off < len(s); off++ {
+			v, ok := hexValue(s[off])
+			if !ok {
+				break
+			}
+			if off > 3 {
+				return nil, ipError("ip_bad_group", orig, map[string]interface{}{"group": group(s)})
+			}
+			acc = acc<<4 | v
+		}
+		if off == 0 {
+			if s != "" && s[0] != ':' {
+				return nil, ipError("ip_bad_char", orig, map[string]interface{}{"char": string(s[0])})
+			}
+			return nil, ipError("ip_bad_group", orig, map[string]interface{}{"group": ""})
+		}
+
+		// 嵌入的IPv4地址
+		if off < len(s) && s[off] == '.' {
+			if ellipsis < 0 && i != IPV6_LEN-IPV4_LEN {
+				return nil, ipError("ip_embedded_v4", orig, nil)
+			}
+			if i+IPV4_LEN > IPV6_LEN {
+				return nil, ipError("ip_group_count", orig, nil)
+			}
+			ip4, err := parseIPv4(s, orig)
+			if err != nil {
+				return nil, err
+			}
+			copy(ip[i:], ip4)
+			i += IPV4_LEN
+			s = ""
+			break
+		}
+
+		ip[i] = byte(acc >> 8)
+		ip[i+1] = byte(acc)
+		i += 2
+
+		s = s[off:]
+		if s == "" {
+			break
+		}
+		if s[0] != ':' {
+			return nil, ipError("ip_bad_char", orig, map[string]interface{}{"char": string(s[0])})
+		}
+		if len(s) == 1 {
+			return nil, ipError("ip_bad_group", orig, map[string]interface{}{"group": ""})
+		}
+		s = s[1:]
+		if s[0] == ':' {
+			if ellipsis >= 0 {
+				return nil, ipError("ip_double_colon", orig, nil)
+			}
+			ellipsis = i
+			s = s[1:]
+			if s == "" {
+				break
+			}
+		}
+	}
+
+	if s != "" {
+		return nil, ipError("ip_group_count", orig, nil)
+	}
+	if i < IPV6_LEN {
+		if ellipsis < 0 {
+			return nil, ipError("ip_group_count", orig, nil)
+		}
+		n := IPV6_LEN - i
+		copy(ip[ellipsis+n:], ip[ellipsis:i])
+		for j := ellipsis; j < ellipsis+n; j++ {
+			ip[j] = 0
+		}
+	} else if ellipsis >= 0 {
+		// "::"至少代表一组0
+		return nil, ipError("ip_double_colon", orig, nil)
+	}
+	return ip, nil
+}
+
+// group s开头到下一个":"之前的部分
+func group(s string) string {
+	if i := strings.IndexByte(s, ':'); i > -1 {
+		return s[:i]
+	}
+	return s
+}
+
+// ParseIPAddress 根据第一个"."或者":"判断地址族，IPv4返回4字节，IPv6返回16字节
+func ParseIPAddress(s string) ([]byte, error) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '.':
+			return ParseIPv4(s)
+		case ':':
+			return ParseIPv6(s)
+		}
+	}
+	if s == "" {
+		return nil, ipError("ip_empty", s, nil)
+	}
+	return nil, ipError("ip_family", s, map[string]interface{}{"family": "ipv4/ipv6"})
+}
+
+// ParsePrefix 解析前缀长度，只接受十进制数字，不超过max
+func ParsePrefix(s string, max int, ip string) (int, error) {
+	if s == "" {
+		return 0, ipError("ip_prefix_format", ip, map[string]interface{}{"prefix": s})
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, ipError("ip_prefix_format", ip, map[string]interface{}{"prefix": s})
+		}
+		n = n*10 + int(s[i]-'0')
+		if n > max {
+			return 0, ipError("ip_prefix_range", ip, map[string]interface{}{"prefix": s, "max": max})
+		}
+	}
+	return n, nil
+}
+
+// ParseCIDR 解析"ip/prefix"，strict为true时要求主机位全部为0
+func ParseCIDR(s string, strict bool) ([]byte, int, error) {
+	i := strings.IndexByte(s, '/')
+	if i == -1 {
+		return nil, 0, ipError("ip_prefix_format", s, map[string]interface{}{"prefix": ""})
+	}
+	ip, err := ParseIPAddress(s[:i])
+	if err != nil {
+		return nil, 0, withIP(err, s)
+	}
+	prefix, err := ParsePrefix(s[i+1:], len(ip)*8, s)
+	if err != nil {
+		return nil, 0, err
+	}
+	if strict && hasHostBits(ip, prefix) {
+		return nil, 0, ipError("ip_host_bits", s, map[string]interface{}{"prefix": prefix})
+	}
+	return ip, prefix, nil
+}
+
+func hasHostBits(ip []byte, prefix int) bool {
+	for i := range ip {
+		bits := prefix - i*8
+		var mask byte
+		switch {
+		case bits >= 8:
+			mask = 0xff
+		case bits > 0:
+			mask = ^byte(0xff >> uint(bits))
+		}
+		if ip[i]&^mask != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// withIP 将错误中的ip替换为完整的字符串
+func withIP(err error, s string) error {
+	if e, ok := err.(*i18n.Error); ok {
+		e.Params["ip"] = s
+	}
+	return err
+}
+
+// IPReason 解析错误的原因，不是解析错误时为空
+func IPReason(err error) string {
+	if e, ok := err.(*i18n.Error); ok {
+		return e.Code
+	}
+	return ""
+}
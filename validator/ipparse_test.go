@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+)
+
+var ipParseTestList = []map[string]interface{}{
+	{"ip": "1.2.3.4", "want": ""},
+	{"ip": "::ffff:1.2.3.4", "want": ""},
+	{"ip": "1:2:3:4:5:6:7::", "want": ""},
+	{"ip": "", "want": "ip_empty"},
+	{"ip": "1.2.3.256", "want": "ip_bad_octet"},
+	{"ip": "1.2.3.04", "want": "ip_bad_octet"},
+	{"ip": "1.2..4", "want": "ip_bad_octet"},
+	{"ip": "1.2.3.4.", "want": "ip_octet_count"},
+	{"ip": "1.2.3", "want": "ip_octet_count"},
+	{"ip": "1.2.3.4.5", "want": "ip_octet_count"},
+	{"ip": "2001::12345", "want": "ip_bad_group"},
+	{"ip": "2001:db8:", "want": "ip_bad_group"},
+	{"ip": "fe80::1%eth0", "want": "ip_bad_char"},
+	{"ip": "2001:zz::1", "want": "ip_bad_char"},
+	{"ip": "1:2:3:4:5:6:7:8:9", "want": "ip_group_count"},
+	{"ip": "1:2:3:4:5:6:7", "want": "ip_group_count"},
+	{"ip": "1::2::3", "want": "ip_double_colon"},
+	{"ip": "1:2:3:4:5:6:7:8::", "want": "ip_double_colon"},
+	{"ip": "1:2:3:4:1.2.3.4", "want": "ip_embedded_v4"},
+	{"ip": "::1.2.3", "want": "ip_octet_count"},
+	{"ip": "1234", "want": "ip_family"},
+}
+
+func TestParseIPAddress(t *testing.T) {
+	for _, tc := range ipParseTestList {
+		_, err := ParseIPAddress(tc["ip"].(string))
+		if IPReason(err) != tc["want"] {
+			t.Errorf("ParseIPAddress(%s), err = %v, want = %s", tc["ip"], err, tc["want"])
+		}
+	}
+}
+
+var cidrParseTestList = []map[string]interface{}{
+	{"ip": "10.0.0.0/8", "strict": true, "want": ""},
+	{"ip": "10.1.0.0/8", "strict": false, "want": ""},
+	{"ip": "10.1.0.0/8", "strict": true, "want": "ip_host_bits"},
+	{"ip": "2001:db8::/32", "strict": true, "want": ""},
+	{"ip": "2001:db8::1/127", "strict": true, "want": "ip_host_bits"},
+	{"ip": "10.0.0.0/33", "strict": false, "want": "ip_prefix_range"},
+	{"ip": "2001:db8::/129", "strict": false, "want": "ip_prefix_range"},
+	{"ip": "10.0.0.0/-1", "strict": false, "want": "ip_prefix_format"},
+	{"ip": "10.0.0.0/", "strict": false, "want": "ip_prefix_format"},
+	{"ip": "10.0.0.0", "strict": false, "want": "ip_prefix_format"},
+	{"ip": "10.0.0/8", "strict": false, "want": "ip_octet_count"},
+}
+
+func TestParseCIDR(t *testing.T) {
+	for _, tc := range cidrParseTestList {
+		_, _, err := ParseCIDR(tc["ip"].(string), tc["strict"].(bool))
+		if IPReason(err) != tc["want"] {
+			t.Errorf("ParseCIDR(%s), err = %v, want = %s", tc["ip"], err, tc["want"])
+		}
+		// 错误信息中是完整的字符串
+		if err != nil && !strings.Contains(err.Error(), tc["ip"].(string)) {
+			t.Errorf("ParseCIDR(%s), err = %v", tc["ip"], err)
+		}
+	}
+}
+
+func FuzzParseIPAddress(f *testing.F) {
+	for _, tc := range ipParseTestList {
+		f.Add(tc["ip"].(string))
+	}
+	for _, tc := range ipv6TestList {
+		f.Add(tc["ip"].(string))
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		ip, err := ParseIPAddress(s)
+		want := net.ParseIP(s)
+		if (err == nil) != (want != nil) {
+			t.Fatalf("ParseIPAddress(%q), err = %v, net.ParseIP = %v", s, err, want)
+		}
+		if err != nil {
+			if IPReason(err) == "" {
+				t.Fatalf("ParseIPAddress(%q), err = %v, no reason", s, err)
+			}
+		} else if !net.IP(ip).Equal(want) || (len(ip) == IPV4_LEN) != (strings.IndexAny(s, ".:") == strings.IndexByte(s, '.')) {
+			t.Fatalf("ParseIPAddress(%q) = %v, net.ParseIP = %v", s, ip, want)
+		}
+
+		data := map[string]interface{}{"ip": s}
+		isV4, isV6 := err == nil && len(ip) == IPV4_LEN, err == nil && len(ip) == IPV6_LEN
+		if IsIPv4Address(s) != isV4 || (Ipv4Validator{}).Validate(data).Status() != isV4 {
+			t.Fatalf("IPv4 validators disagree with parser, ip = %q", s)
+		}
+		if IsIPv6Address(s) != isV6 || (Ipv6Validator{}).Validate(data).Status() != isV6 {
+			t.Fatalf("IPv6 validators disagree with parser, ip = %q", s)
+		}
+	})
+}
+
+func FuzzParseCIDR(f *testing.F) {
+	for _, tc := range cidrParseTestList {
+		f.Add(tc["ip"].(string))
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		ip, prefix, err := ParseCIDR(s, false)
+		_, want, werr := net.ParseCIDR(s)
+		if (err == nil) != (werr == nil) {
+			t.Fatalf("ParseCIDR(%q), err = %v, net.ParseCIDR err = %v", s, err, werr)
+		}
+		if err != nil {
+			return
+		}
+		ones, _ := want.Mask.Size()
+		mask := net.CIDRMask(prefix, len(ip)*8)
+		masked := net.IP(ip).Mask(mask)
+		if ones != prefix || !masked.Equal(want.IP) {
+			t.Fatalf("ParseCIDR(%q) = %v/%d, net.ParseCIDR = %v", s, ip, prefix, want)
+		}
+
+		_, _, err = ParseCIDR(s, true)
+		if (err == nil) != bytes.Equal(masked, ip) {
+			t.Fatalf("ParseCIDR(%q, strict), err = %v", s, err)
+		}
+		data := map[string]interface{}{"ip": s, "withPrefix": true, "strict": true}
+		if (Ipv4Validator{}).Validate(data).Status() != (err == nil && len(ip) == IPV4_LEN) {
+			t.Fatalf("Ipv4Validator disagree with parser, ip = %q", s)
+		}
+	})
+}
@@ -0,0 +1,337 @@
+package validator
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 网络相关的校验：MAC地址、VLAN、端口、ASN、RD/RT、接口名、主机名以及通配符掩码
+
+const (
+	VENDOR_CISCO   = "cisco"
+	VENDOR_HUAWEI  = "huawei"
+	VENDOR_JUNIPER = "juniper"
+)
+
+var macRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`),
+	regexp.MustCompile(`^([0-9a-fA-F]{2}-){5}[0-9a-fA-F]{2}$`),
+	regexp.MustCompile(`^([0-9a-fA-F]{4}\.){2}[0-9a-fA-F]{4}$`),
+	regexp.MustCompile(`^([0-9a-fA-F]{4}-){2}[0-9a-fA-F]{4}$`),
+	regexp.MustCompile(`^[0-9a-fA-F]{12}$`),
+}
+
+var interfaceRegexps = map[string][]*regexp.Regexp{
+	VENDOR_CISCO: {
+		regexp.MustCompile(`^(?i)(gigabitethernet|gi|fastethernet|fa|tengigabitethernet|te|twentyfivegige|twe|fortygigabitethernet|fo|hundredgige|hu|ethernet|eth|et|e)\s?\d+(/\d+){0,3}(\.\d+)?$`),
+		regexp.MustCompile(`^(?i)(vlan|loopback|lo|port-channel|po|tunnel|tu|bvi|mgmt|null)\s?\d+(\.\d+)?$`),
+	},
+	VENDOR_HUAWEI: {
+		regexp.MustCompile(`^(?i)(gigabitethernet|ge|xgigabitethernet|xge|10ge|25ge|40ge|100ge|ethernet|eth|meth)\s?\d+(/\d+){0,3}(\.\d+)?$`),
+		regexp.MustCompile(`^(?i)(eth-trunk|vlanif|loopback|tunnel|nve|vbdif|null)\s?\d+(\.\d+)?$`),
+	},
+	VENDOR_JUNIPER: {
+		regexp.MustCompile(`^(ge|xe|et|fe|so|gr|ip|lt|mt|pe|pd|vt)-\d+/\d+/\d+(:\d+)?(\.\d+)?$`),
+		regexp.MustCompile(`^(ae|reth|lo|em|fxp|me|st)\d+(\.\d+)?$`),
+		regexp.MustCompile(`^(irb|vlan|vme)(\.\d+)?$`),
+	},
+}
+
+var hostnameLabel = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// IsMacAddress 支持xx:xx:xx:xx:xx:xx、xx-xx-xx-xx-xx-xx、xxxx.xxxx.xxxx、xxxx-xxxx-xxxx以及xxxxxxxxxxxx
+func IsMacAddress(s string) bool {
+	for _, re := range macRegexps {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseUint 只接受十进制数字，不接受正负号以及空格以外的字符
+func parseUint(s string, max uint64) (uint64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n > max {
+		return 0, false
+	}
+	return n, true
+}
+
+// isNumberList 逗号分隔的列表，每一项为n或者low-high，所有值都在[min, max]中
+func isNumberList(s string, min uint64, max uint64) bool {
+	if strings.TrimSpace(s) == "" {
+		return false
+	}
+	for _, item := range strings.Split(s, ",") {
+		tokens := strings.Split(item, "-")
+		if len(tokens) > 2 {
+			return false
+		}
+		low, ok := parseUint(tokens[0], max)
+		if !ok || low < min {
+			return false
+		}
+		high := low
+		if len(tokens) == 2 {
+			if high, ok = parseUint(tokens[1], max); !ok || high < low {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsVlanID 1-4094
+func IsVlanID(s string) bool {
+	n, ok := parseUint(s, 4094)
+	return ok && n >= 1
+}
+
+// IsVlanRange VLAN列表，比如"10,20-30"
+func IsVlanRange(s string) bool {
+	return isNumberList(s, 1, 4094)
+}
+
+// IsPort 1-65535
+func IsPort(s string) bool {
+	n, ok := parseUint(s, 65535)
+	return ok && n >= 1
+}
+
+// IsPortRange 端口列表，比如"80,443,8000-8080"
+func IsPortRange(s string) bool {
+	return isNumberList(s, 1, 65535)
+}
+
+func asdot(s string) (uint64, bool) {
+	tokens := strings.Split(s, ".")
+	if len(tokens) != 2 {
+		return 0, false
+	}
+	high, ok1 := parseUint(tokens[0], 65535)
+	low, ok2 := parseUint(tokens[1], 65535)
+	return high<<16 | low, ok1 && ok2
+}
+
+// IsASN asplain(0-4294967295)或者asdot(0-65535.0-65535)
+func IsASN(s string) bool {
+	if strings.Contains(s, ".") {
+		_, ok := asdot(s)
+		return ok
+	}
+	_, ok := parseUint(s, 4294967295)
+	return ok
+}
+
+// IsRouteDistinguisher 格式为 管理字段:分配字段，三种类型：
+// 2字节ASN:4字节数值，IPv4地址:2字节数值，4字节ASN(asplain或asdot):2字节数值
+func IsRouteDistinguisher(s string) bool {
+	i := strings.LastIndex(s, ":")
+	if i == -1 {
+		return false
+	}
+	admin, assigned := s[:i], s[i+1:]
+
+	switch {
+	case IsIPv4Address(admin):
+	case strings.Contains(admin, "."):
+		if _, ok := asdot(admin); !ok {
+			return false
+		}
+	default:
+		asn, ok := parseUint(admin, 4294967295)
+		if !ok {
+			return false
+		}
+		if asn <= 65535 {
+			_, ok := parseUint(assigned, 4294967295)
+			return ok
+		}
+	}
+	_, ok := parseUint(assigned, 65535)
+	return ok
+}
+
+// IsRouteTarget 与RD的格式相同
+func IsRouteTarget(s string) bool {
+	return IsRouteDistinguisher(s)
+}
+
+// IsInterfaceName vendor为VENDOR_CISCO、VENDOR_HUAWEI、VENDOR_JUNIPER，为空时任意一个匹配即可
+func IsInterfaceName(s string, vendor string) bool {
+	for v, list := range interfaceRegexps {
+		if vendor != "" && v != vendor {
+			continue
+		}
+		for _, re := range list {
+			if re.MatchString(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsHostname RFC 1123，由一个或多个标签组成
+func IsHostname(s string) bool {
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if !hostnameLabel.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsFQDN 至少两个标签，可以以"."结尾，顶级域名不能全是数字
+func IsFQDN(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	labels := strings.Split(s, ".")
+	if len(labels) < 2 || !IsHostname(s) {
+		return false
+	}
+	_, numeric := parseUint(labels[len(labels)-1], ^uint64(0))
+	return !numeric
+}
+
+// IsWildcardMask 点分十进制的反掩码，比如0.0.0.255，要求取反以后是连续的掩码
+func IsWildcardMask(s string) bool {
+	tokens := strings.Split(s, ".")
+	if len(tokens) != 4 {
+		return false
+	}
+	var v uint32
+	for _, t := range tokens {
+		n, ok := parseUint(t, 255)
+		if !ok {
+			return false
+		}
+		v = v<<8 | uint32(n)
+	}
+	return v&(v+1) == 0
+}
+
+// stringValidator 从data[key]中读取字符串，使用f校验，失败时错误码为code
+func stringValidator(data map[string]interface{}, key string, code string, f func(s string) bool) Result {
+	v, ok := data[key]
+	if !ok {
+		return NewCodeResult("field_empty", nil, map[string]interface{}{"key": key, "data": data})
+	}
+	s, ok := v.(string)
+	if !ok {
+		return NewCodeResult("field_not_string", v, map[string]interface{}{"key": key, "data": data})
+	}
+	if !f(s) {
+		return NewCodeResult(code, s, map[string]interface{}{"key": key})
+	}
+	return NewValidateResult(true, "")
+}
+
+type MacValidator struct{}
+
+func (v MacValidator) Validate(data map[string]interface{}) Result {
+	return stringValidator(data, "mac", "mac", IsMacAddress)
+}
+
+// VlanValidator data["range"]为true时校验VLAN列表
+type VlanValidator struct{}
+
+func (v VlanValidator) Validate(data map[string]interface{}) Result {
+	if r, _ := data["range"].(bool); r {
+		return stringValidator(data, "vlan", "vlans", IsVlanRange)
+	}
+	return stringValidator(data, "vlan", "vlan", IsVlanID)
+}
+
+// PortValidator data["range"]为true时校验端口列表
+type PortValidator struct{}
+
+func (v PortValidator) Validate(data map[string]interface{}) Result {
+	if r, _ := data["range"].(bool); r {
+		return stringValidator(data, "port", "ports", IsPortRange)
+	}
+	return stringValidator(data, "port", "port", IsPort)
+}
+
+type AsnValidator struct{}
+
+func (v AsnValidator) Validate(data map[string]interface{}) Result {
+	return stringValidator(data, "asn", "asn", IsASN)
+}
+
+type RouteDistinguisherValidator struct{}
+
+func (v RouteDistinguisherValidator) Validate(data map[string]interface{}) Result {
+	return stringValidator(data, "rd", "rd", IsRouteDistinguisher)
+}
+
+type RouteTargetValidator struct{}
+
+func (v RouteTargetValidator) Validate(data map[string]interface{}) Result {
+	return stringValidator(data, "rt", "rt", IsRouteTarget)
+}
+
+// InterfaceValidator data["vendor"]为空时任意厂商的格式都可以
+type InterfaceValidator struct{}
+
+func (v InterfaceValidator) Validate(data map[string]interface{}) Result {
+	vendor, _ := data["vendor"].(string)
+	return stringValidator(data, "interface", "interface", func(s string) bool {
+		return IsInterfaceName(s, vendor)
+	})
+}
+
+// HostnameValidator data["fqdn"]为true时要求是FQDN
+type HostnameValidator struct{}
+
+func (v HostnameValidator) Validate(data map[string]interface{}) Result {
+	if fqdn, _ := data["fqdn"].(bool); fqdn {
+		return stringValidator(data, "hostname", "fqdn", IsFQDN)
+	}
+	return stringValidator(data, "hostname", "hostname", IsHostname)
+}
+
+type WildcardMaskValidator struct{}
+
+func (v WildcardMaskValidator) Validate(data map[string]interface{}) Result {
+	return stringValidator(data, "mask", "wildcard", IsWildcardMask)
+}
+
+// stringCheck 将IsXxx转换为struct tag规则，失败时错误码为code
+func stringCheck(code string, f func(s string) bool) RuleFunc {
+	return stringRule(func(s string, param string) Result {
+		if !f(s) {
+			return NewCodeResult(code, s, nil)
+		}
+		return NewValidateResult(true, "")
+	})
+}
+
+func init() {
+	RegisterRule("mac", stringCheck("mac", IsMacAddress))
+	RegisterRule("vlan", stringCheck("vlan", IsVlanID))
+	RegisterRule("vlans", stringCheck("vlans", IsVlanRange))
+	RegisterRule("port", stringCheck("port", IsPort))
+	RegisterRule("ports", stringCheck("ports", IsPortRange))
+	RegisterRule("asn", stringCheck("asn", IsASN))
+	RegisterRule("rd", stringCheck("rd", IsRouteDistinguisher))
+	RegisterRule("rt", stringCheck("rt", IsRouteTarget))
+	RegisterRule("hostname", stringCheck("hostname", IsHostname))
+	RegisterRule("fqdn", stringCheck("fqdn", IsFQDN))
+	RegisterRule("wildcard", stringCheck("wildcard", IsWildcardMask))
+	// interface=cisco
+	RegisterRule("interface", func(v reflect.Value, param string) Result {
+		return stringCheck("interface", func(s string) bool {
+			return IsInterfaceName(s, param)
+		})(v, param)
+	})
+}
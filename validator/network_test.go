@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"testing"
+)
+
+var networkTestList = []map[string]interface{}{
+	{"validator": MacValidator{}, "mac": "00:1a:2B:3c:4d:5e", "want": true},
+	{"validator": MacValidator{}, "mac": "00-1a-2b-3c-4d-5e", "want": true},
+	{"validator": MacValidator{}, "mac": "001a.2b3c.4d5e", "want": true},
+	{"validator": MacValidator{}, "mac": "001a-2b3c-4d5e", "want": true},
+	{"validator": MacValidator{}, "mac": "001a2b3c4d5e", "want": true},
+	{"validator": MacValidator{}, "mac": "00:1a-2b:3c:4d:5e", "want": false},
+	{"validator": MacValidator{}, "mac": "00:1a:2b:3c:4d", "want": false},
+	{"validator": MacValidator{}, "mac": "001a.2b3c.4d5g", "want": false},
+	{"validator": MacValidator{}, "want": false},
+
+	{"validator": VlanValidator{}, "vlan": "1", "want": true},
+	{"validator": VlanValidator{}, "vlan": "4094", "want": true},
+	{"validator": VlanValidator{}, "vlan": "0", "want": false},
+	{"validator": VlanValidator{}, "vlan": "4095", "want": false},
+	{"validator": VlanValidator{}, "vlan": "+10", "want": false},
+	{"validator": VlanValidator{}, "vlan": "10,20-30", "range": true, "want": true},
+	{"validator": VlanValidator{}, "vlan": "10, 20 - 30", "range": true, "want": true},
+	{"validator": VlanValidator{}, "vlan": "30-20", "range": true, "want": false},
+	{"validator": VlanValidator{}, "vlan": "10,,20", "range": true, "want": false},
+	{"validator": VlanValidator{}, "vlan": "1-2-3", "range": true, "want": false},
+
+	{"validator": PortValidator{}, "port": "80", "want": true},
+	{"validator": PortValidator{}, "port": "65535", "want": true},
+	{"validator": PortValidator{}, "port": "0", "want": false},
+	{"validator": PortValidator{}, "port": "65536", "want": false},
+	{"validator": PortValidator{}, "port": "80,443,8000-8080", "range": true, "want": true},
+	{"validator": PortValidator{}, "port": "8080-8000", "range": true, "want": false},
+	{"validator": PortValidator{}, "port": "", "range": true, "want": false},
+
+	{"validator": AsnValidator{}, "asn": "65001", "want": true},
+	{"validator": AsnValidator{}, "asn": "4294967295", "want": true},
+	{"validator": AsnValidator{}, "asn": "4294967296", "want": false},
+	{"validator": AsnValidator{}, "asn": "1.10", "want": true},
+	{"validator": AsnValidator{}, "asn": "65535.65535", "want": true},
+	{"validator": AsnValidator{}, "asn": "65536.1", "want": false},
+	{"validator": AsnValidator{}, "asn": "1.2.3", "want": false},
+	{"validator": AsnValidator{}, "asn": "-1", "want": false},
+
+	{"validator": RouteDistinguisherValidator{}, "rd": "65001:100", "want": true},
+	{"validator": RouteDistinguisherValidator{}, "rd": "65001:4294967295", "want": true},
+	{"validator": RouteDistinguisherValidator{}, "rd": "4200000000:100", "want": true},
+	{"validator": RouteDistinguisherValidator{}, "rd": "4200000000:65536", "want": false},
+	{"validator": RouteDistinguisherValidator{}, "rd": "1.1.1.1:100", "want": true},
+	{"validator": RouteDistinguisherValidator{}, "rd": "1.1.1.1:65536", "want": false},
+	{"validator": RouteDistinguisherValidator{}, "rd": "1.10:100", "want": true},
+	{"validator": RouteDistinguisherValidator{}, "rd": "1.1.1.256:1", "want": false},
+	{"validator": RouteDistinguisherValidator{}, "rd": "65001", "want": false},
+	{"validator": RouteTargetValidator{}, "rt": "65001:1", "want": true},
+	{"validator": RouteTargetValidator{}, "rt": "65001:", "want": false},
+
+	{"validator": InterfaceValidator{}, "interface": "GigabitEthernet0/0/1", "vendor": VENDOR_CISCO, "want": true},
+	{"validator": InterfaceValidator{}, "interface": "Gi0/1.100", "vendor": VENDOR_CISCO, "want": true},
+	{"validator": InterfaceValidator{}, "interface": "Port-channel10", "vendor": VENDOR_CISCO, "want": true},
+	{"validator": InterfaceValidator{}, "interface": "Eth-Trunk1", "vendor": VENDOR_CISCO, "want": false},
+	{"validator": InterfaceValidator{}, "interface": "Eth-Trunk1", "vendor": VENDOR_HUAWEI, "want": true},
+	{"validator": InterfaceValidator{}, "interface": "10GE1/0/1", "vendor": VENDOR_HUAWEI, "want": true},
+	{"validator": InterfaceValidator{}, "interface": "Vlanif100", "vendor": VENDOR_HUAWEI, "want": true},
+	{"validator": InterfaceValidator{}, "interface": "ge-0/0/0.0", "vendor": VENDOR_JUNIPER, "want": true},
+	{"validator": InterfaceValidator{}, "interface": "xe-1/2/3:1", "vendor": VENDOR_JUNIPER, "want": true},
+	{"validator": InterfaceValidator{}, "interface": "irb.100", "vendor": VENDOR_JUNIPER, "want": true},
+	{"validator": InterfaceValidator{}, "interface": "ae0", "vendor": VENDOR_JUNIPER, "want": true},
+	{"validator": InterfaceValidator{}, "interface": "ge-0/0", "vendor": VENDOR_JUNIPER, "want": false},
+	{"validator": InterfaceValidator{}, "interface": "ae0", "want": true},
+	{"validator": InterfaceValidator{}, "interface": "foo0/1", "want": false},
+
+	{"validator": HostnameValidator{}, "hostname": "router-1", "want": true},
+	{"validator": HostnameValidator{}, "hostname": "-router", "want": false},
+	{"validator": HostnameValidator{}, "hostname": "a..b", "want": false},
+	{"validator": HostnameValidator{}, "hostname": "www.example.com", "fqdn": true, "want": true},
+	{"validator": HostnameValidator{}, "hostname": "www.example.com.", "fqdn": true, "want": true},
+	{"validator": HostnameValidator{}, "hostname": "localhost", "fqdn": true, "want": false},
+	{"validator": HostnameValidator{}, "hostname": "1.2.3.4", "fqdn": true, "want": false},
+	{"validator": HostnameValidator{}, "hostname": "under_score.com", "fqdn": true, "want": false},
+
+	{"validator": WildcardMaskValidator{}, "mask": "0.0.0.255", "want": true},
+	{"validator": WildcardMaskValidator{}, "mask": "0.0.0.0", "want": true},
+	{"validator": WildcardMaskValidator{}, "mask": "255.255.255.255", "want": true},
+	{"validator": WildcardMaskValidator{}, "mask": "0.0.255.0", "want": false},
+	{"validator": WildcardMaskValidator{}, "mask": "255.255.255.0", "want": false},
+	{"validator": WildcardMaskValidator{}, "mask": "0.0.0.256", "want": false},
+}
+
+func TestNetworkValidators(t *testing.T) {
+	for _, data := range networkTestList {
+		result := data["validator"].(Validator).Validate(data)
+		if result.Status() != data["want"] {
+			t.Errorf("Test %+v, got = %+v, want = %+v, msg = %s", data, result.Status(), data["want"], result.Msg())
+		}
+	}
+}
+
+func TestNetworkRules(t *testing.T) {
+	type device struct {
+		Mac   string   `validate:"mac"`
+		Vlans string   `validate:"vlans"`
+		Ports []string `validate:"ports"`
+		Iface string   `validate:"interface=juniper"`
+		Host  string   `validate:"omitempty,fqdn"`
+	}
+
+	ok := device{Mac: "001a.2b3c.4d5e", Vlans: "1-10", Ports: []string{"22", "80-90"}, Iface: "ge-0/0/1"}
+	if r := ValidateStruct(ok); !r.Status() {
+		t.Errorf("ValidateStruct(%+v), msg = %s", ok, r.Msg())
+	}
+
+	bad := device{Mac: "001a.2b3c", Vlans: "0", Ports: []string{"22", "90-80"}, Iface: "Gi0/1", Host: "localhost"}
+	r := ValidateStruct(bad)
+	if len(Failures(r)) != 5 {
+		t.Errorf("ValidateStruct(%+v), msg = %s", bad, r.Msg())
+	}
+	if f := Failures(r)[2]; f.Field() != "Ports[1]" || f.Code() != "ports" {
+		t.Errorf("failure = %s, code = %s", f.Field(), f.Code())
+	}
+}
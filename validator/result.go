@@ -1,17 +1,39 @@
 package validator
 
-import "strings"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"tools/i18n"
+)
 
 type Result interface {
 	Status() bool
 	Msg() string
 	AddError(Result)
+
+	// Field 出错的字段路径，比如hops[2].ip，没有对应字段时为空
+	Field() string
+	// Code 机器可读的错误码，比如required、range
+	Code() string
+	// Value 出错的值
+	Value() interface{}
+	// Params 规则的参数，比如range的min、max
+	Params() map[string]interface{}
+	// Errors 嵌套的错误，比如ValidateChain中每个Validator的结果
+	Errors() []Result
+	// Walk 先序遍历结果树，f返回false时不再进入该节点的子节点
+	Walk(f func(r Result) bool)
 }
 
 type validateResult struct {
 	status bool
 	msg    string
 	errors []Result
+	field  string
+	code   string
+	value  interface{}
+	params map[string]interface{}
 }
 
 func NewValidateResult(status bool, msg string) Result {
@@ -22,6 +44,30 @@ func NewValidateResult(status bool, msg string) Result {
 	}
 }
 
+// NewFieldError 返回与字段相关的失败结果
+func NewFieldError(field string, code string, value interface{}, params map[string]interface{}, msg string) Result {
+	return &validateResult{
+		status: false,
+		msg:    msg,
+		errors: []Result{},
+		field:  field,
+		code:   code,
+		value:  value,
+		params: params,
+	}
+}
+
+// NewCodeResult 返回没有消息的失败结果，Msg由默认的i18n.Translator按照code、value、params生成
+func NewCodeResult(code string, value interface{}, params map[string]interface{}) Result {
+	return &validateResult{
+		status: false,
+		errors: []Result{},
+		code:   code,
+		value:  value,
+		params: params,
+	}
+}
+
 func (r *validateResult) Status() bool {
 	return r.status
 }
@@ -30,9 +76,15 @@ func (r *validateResult) Msg() string {
 	if len(r.errors) == 0 {
 		if r.status == true {
 			return ""
-		} else {
-			return r.msg
 		}
+		msg := r.msg
+		if msg == "" && r.code != "" {
+			msg = i18n.T(r.code, templateParams(r))
+		}
+		if r.field != "" {
+			return fmt.Sprintf("%s: %s", r.field, msg)
+		}
+		return msg
 	}
 	m := []string{}
 	for _, e := range r.errors {
@@ -50,3 +102,106 @@ func (r *validateResult) AddError(result Result) {
 		r.status = false
 	}
 }
+
+func (r *validateResult) Field() string {
+	return r.field
+}
+
+func (r *validateResult) Code() string {
+	return r.code
+}
+
+func (r *validateResult) Value() interface{} {
+	return r.value
+}
+
+func (r *validateResult) Params() map[string]interface{} {
+	return r.params
+}
+
+func (r *validateResult) Errors() []Result {
+	return r.errors
+}
+
+func (r *validateResult) Walk(f func(r Result) bool) {
+	if !f(r) {
+		return
+	}
+	for _, e := range r.errors {
+		e.Walk(f)
+	}
+}
+
+func (r *validateResult) MarshalJSON() ([]byte, error) {
+	type result struct {
+		Status bool                   `json:"status"`
+		Field  string                 `json:"field,omitempty"`
+		Code   string                 `json:"code,omitempty"`
+		Value  interface{}            `json:"value,omitempty"`
+		Params map[string]interface{} `json:"params,omitempty"`
+		Msg    string                 `json:"msg,omitempty"`
+		Errors []Result               `json:"errors,omitempty"`
+	}
+	d := result{
+		Status: r.status,
+		Field:  r.field,
+		Code:   r.code,
+		Value:  r.value,
+		Params: r.params,
+		Errors: r.errors,
+	}
+	if len(r.errors) == 0 && !r.status {
+		d.Msg = r.msg
+		if d.Msg == "" && r.code != "" {
+			d.Msg = i18n.T(r.code, templateParams(r))
+		}
+	}
+	return json.Marshal(&d)
+}
+
+// Failures 返回结果树中所有失败的叶子节点，即每一个具体的错误
+func Failures(r Result) []Result {
+	var list []Result
+	r.Walk(func(n Result) bool {
+		if len(n.Errors()) == 0 && !n.Status() {
+			list = append(list, n)
+		}
+		return true
+	})
+	return list
+}
+
+// templateParams 模板参数，除Params以外还可以使用{field}、{value}
+func templateParams(r Result) map[string]interface{} {
+	params := map[string]interface{}{}
+	for k, v := range r.Params() {
+		params[k] = v
+	}
+	if _, ok := params["field"]; !ok && r.Field() != "" {
+		params["field"] = r.Field()
+	}
+	if _, ok := params["value"]; !ok && r.Value() != nil {
+		params["value"] = r.Value()
+	}
+	return params
+}
+
+// Localize 使用t生成r中所有错误的消息，每个错误一行。
+// 有错误码并且t中有对应模板的错误按照模板生成，其他的使用Msg
+func Localize(r Result, t i18n.Translator) string {
+	m := []string{}
+	for _, f := range Failures(r) {
+		msg, ok := "", false
+		if f.Code() != "" && t != nil {
+			msg, ok = t.Translate(f.Code(), templateParams(f))
+		}
+		if !ok {
+			m = append(m, f.Msg())
+		} else if f.Field() != "" {
+			m = append(m, fmt.Sprintf("%s: %s", f.Field(), msg))
+		} else {
+			m = append(m, msg)
+		}
+	}
+	return strings.Join(m, "\n")
+}
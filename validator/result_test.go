@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"tools/i18n"
+)
+
+func TestResultFields(t *testing.T) {
+	data := testRoute{Name: "r1", Prefix: "10.0.0.0/8",
+		Hops: []testHop{{IP: "1.1.1.1", Port: 80}, {IP: "1.1.1", Port: 70000}}}
+
+	vc := NewValidateChain()
+	vc.Add(nameValidator{})
+	data.Name = "bad"
+	result := vc.ValidateStruct(data)
+
+	testCases := []map[string]interface{}{
+		{"field": "hops[1].ip", "code": "ip", "value": "1.1.1"},
+		{"field": "hops[1].port", "code": "range", "value": 70000},
+		{"field": "", "code": "", "value": nil},
+	}
+	failures := Failures(result)
+	if len(failures) != len(testCases) {
+		t.Fatalf("len(Failures) = %d, want = %d, msg = %s", len(failures), len(testCases), result.Msg())
+	}
+	for i, tc := range testCases {
+		f := failures[i]
+		if f.Field() != tc["field"] || f.Code() != tc["code"] || f.Value() != tc["value"] {
+			t.Errorf("failure %d, field = %s, code = %s, value = %v, want = %+v", i, f.Field(), f.Code(), f.Value(), tc)
+		}
+	}
+	if p := failures[1].Params(); p["min"] != 1.0 || p["max"] != 65535.0 {
+		t.Errorf("range params = %+v", p)
+	}
+
+	// Walk返回false时不进入子节点
+	n := 0
+	result.Walk(func(r Result) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Walk visited %d nodes, want 1", n)
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal, err = %v", err)
+	}
+	for _, want := range []string{`"field":"hops[1].port"`, `"code":"range"`, `"value":70000`, `"params":{"max":65535,"min":1}`, `"msg":"name is bad"`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("json = %s, want contains %s", b, want)
+		}
+	}
+	if b, _ := json.Marshal(NewValidateResult(true, "")); string(b) != `{"status":true}` {
+		t.Errorf("json = %s", b)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	data := testRoute{Name: "r1", Prefix: "10.0.0.0/8",
+		Hops: []testHop{{IP: "1.1.1", Port: 70000}}}
+
+	vc := NewValidateChain()
+	vc.Add(nameValidator{})
+	data.Name = "bad"
+	result := vc.ValidateStruct(data)
+
+	zh, _ := i18n.GetCatalog(i18n.LANG_ZH)
+	want := "hops[0].ip: IP地址无效，ip:1.1.1\nhops[0].port: 70000超出范围1-65535\nname is bad"
+	if got := Localize(result, zh); got != want {
+		t.Errorf("Localize, got = %s, want = %s", got, want)
+	}
+
+	// Msg随默认语言变化
+	i18n.SetLanguage(i18n.LANG_ZH)
+	defer i18n.SetLanguage(i18n.LANG_EN)
+	if got := result.Msg(); got != want {
+		t.Errorf("Msg, got = %s, want = %s", got, want)
+	}
+}
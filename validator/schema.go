@@ -0,0 +1,162 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 从YAML/JSON文件中加载校验规则，规则与struct tag中的规则相同，比如:
+//
+//	stop_on_error: false
+//	fields:
+//	  - field: ip
+//	    rules: [required, ip]
+//	  - field: vlan
+//	    rules:
+//	      - omitempty
+//	      - rule: range
+//	        param: 1-4094
+//
+// 每条规则可以写成"name=param"，也可以写成{rule: name, param: param}
+
+// RuleDef 一条规则
+type RuleDef struct {
+	Rule  string `json:"rule" yaml:"rule"`
+	Param string `json:"param,omitempty" yaml:"param,omitempty"`
+}
+
+func (rd *RuleDef) fromString(s string) {
+	spec := parseRule(s)
+	rd.Rule, rd.Param = spec.name, spec.param
+}
+
+func (rd *RuleDef) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		rd.fromString(s)
+		return nil
+	}
+	type ruleDef RuleDef
+	return json.Unmarshal(b, (*ruleDef)(rd))
+}
+
+func (rd *RuleDef) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		rd.fromString(value.Value)
+		return nil
+	}
+	type ruleDef RuleDef
+	return value.Decode((*ruleDef)(rd))
+}
+
+// FieldRules 一个字段的规则，Field为data中的键，Excel中为表头
+type FieldRules struct {
+	Field string    `json:"field" yaml:"field"`
+	Rules []RuleDef `json:"rules" yaml:"rules"`
+}
+
+// RuleSet 一组字段的规则，编译后每个字段对应ValidateChain中的一个Validator
+type RuleSet struct {
+	StopOnError bool         `json:"stop_on_error" yaml:"stop_on_error"`
+	Concurrency int          `json:"concurrency" yaml:"concurrency"`
+	Fields      []FieldRules `json:"fields" yaml:"fields"`
+}
+
+// ParseRuleSet format为json或者yaml
+func ParseRuleSet(b []byte, format string) (*RuleSet, error) {
+	rs := &RuleSet{}
+	var err error
+	switch strings.ToLower(format) {
+	case "json":
+		err = json.Unmarshal(b, rs)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(b, rs)
+	default:
+		return nil, fmt.Errorf("unknown rule format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse rules failed: %w", err)
+	}
+	return rs, nil
+}
+
+// LoadRuleSet 按照扩展名选择json或者yaml
+func LoadRuleSet(path string) (*RuleSet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := ParseRuleSet(b, strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rs, nil
+}
+
+// LoadValidateChain 加载并编译path中的规则
+func LoadValidateChain(path string) (*ValidateChain, error) {
+	rs, err := LoadRuleSet(path)
+	if err != nil {
+		return nil, err
+	}
+	return rs.Compile()
+}
+
+// Compile 检查规则是否存在，生成ValidateChain
+func (rs *RuleSet) Compile() (*ValidateChain, error) {
+	vc := NewValidateChain()
+	// ValidateChain.StopOnError()没有效果，这里直接设置
+	vc.stopOnError = rs.StopOnError
+	if rs.Concurrency > 0 {
+		vc.Concurrency(rs.Concurrency)
+	}
+
+	for i, fr := range rs.Fields {
+		if fr.Field == "" {
+			return nil, fmt.Errorf("fields[%d]: field is empty", i)
+		}
+		fv := fieldValidator{field: fr.Field}
+		for _, rd := range fr.Rules {
+			name := strings.TrimSpace(rd.Rule)
+			switch name {
+			case "":
+				continue
+			case "required", "omitempty":
+			default:
+				if _, ok := lookupRule(name); !ok {
+					return nil, fmt.Errorf("fields[%d]: %s, unknown validate rule: '%s'", i, fr.Field, name)
+				}
+			}
+			if name == "range" {
+				if _, _, err := parseRangeParam(rd.Param); err != nil {
+					return nil, fmt.Errorf("fields[%d]: %s, %w", i, fr.Field, err)
+				}
+			}
+			fv.rules = append(fv.rules, ruleSpec{name: name, param: rd.Param})
+		}
+		vc.Add(fv)
+	}
+	return vc, nil
+}
+
+// fieldValidator 使用struct tag的规则校验data[field]
+type fieldValidator struct {
+	field string
+	rules []ruleSpec
+}
+
+func (fv fieldValidator) Validate(data map[string]interface{}) Result {
+	var v reflect.Value
+	if value, ok := data[fv.field]; ok && value != nil {
+		v = reflect.ValueOf(value)
+	}
+	result := NewValidateResult(true, "")
+	validateRules(v, fv.field, fv.rules, result)
+	return result
+}
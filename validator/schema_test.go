@@ -0,0 +1,108 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testRulesYAML = `
+fields:
+  - field: ip
+    rules: [required, ip]
+  - field: vlan
+    rules:
+      - omitempty
+      - rule: range
+        param: 1-4094
+  - field: mac
+    rules: ["omitempty", "mac"]
+  - field: interface
+    rules: [omitempty, interface=huawei]
+`
+
+const testRulesJSON = `{
+	"fields": [
+		{"field": "ip", "rules": ["required", "ip"]},
+		{"field": "vlan", "rules": ["omitempty", {"rule": "range", "param": "1-4094"}]},
+		{"field": "mac", "rules": ["omitempty", "mac"]},
+		{"field": "interface", "rules": ["omitempty", "interface=huawei"]}
+	]
+}`
+
+var schemaTestList = []map[string]interface{}{
+	{"data": map[string]interface{}{"ip": "1.1.1.1", "vlan": "100", "mac": "001a.2b3c.4d5e", "interface": "Eth-Trunk1"}, "want": []string{}},
+	{"data": map[string]interface{}{"ip": "2001:db8::1", "vlan": 100}, "want": []string{}},
+	{"data": map[string]interface{}{"ip": "", "vlan": ""}, "want": []string{"ip:required"}},
+	{"data": map[string]interface{}{"vlan": "5000"}, "want": []string{"ip:required", "vlan:range"}},
+	{"data": map[string]interface{}{"ip": "1.1.1", "mac": "xx", "interface": "ge-0/0/1"}, "want": []string{"ip:ip", "mac:mac", "interface:interface"}},
+}
+
+func TestRuleSet(t *testing.T) {
+	for format, s := range map[string]string{"yaml": testRulesYAML, "json": testRulesJSON} {
+		rs, err := ParseRuleSet([]byte(s), format)
+		if err != nil {
+			t.Fatalf("ParseRuleSet(%s), err = %v", format, err)
+		}
+		vc, err := rs.Compile()
+		if err != nil {
+			t.Fatalf("Compile(%s), err = %v", format, err)
+		}
+		for _, tc := range schemaTestList {
+			got := []string{}
+			for _, f := range Failures(vc.Validate(tc["data"].(map[string]interface{}))) {
+				got = append(got, f.Field()+":"+f.Code())
+			}
+			if strings.Join(got, ",") != strings.Join(tc["want"].([]string), ",") {
+				t.Errorf("%s, data = %+v, got = %v, want = %v", format, tc["data"], got, tc["want"])
+			}
+		}
+	}
+}
+
+func TestRuleSetErrors(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"rules": "fields: [{field: ip, rules: [ipx]}]", "want": "unknown validate rule: 'ipx'"},
+		{"rules": "fields: [{field: vlan, rules: [range=4094-1]}]", "want": "invalid range param"},
+		{"rules": "fields: [{rules: [ip]}]", "want": "field is empty"},
+		{"rules": "fields: {ip: [ip]}", "want": "parse rules failed"},
+	}
+	for _, tc := range testCases {
+		rs, err := ParseRuleSet([]byte(tc["rules"].(string)), "yaml")
+		if err == nil {
+			_, err = rs.Compile()
+		}
+		if err == nil || !strings.Contains(err.Error(), tc["want"].(string)) {
+			t.Errorf("rules = %s, err = %v, want = %s", tc["rules"], err, tc["want"])
+		}
+	}
+
+	if _, err := ParseRuleSet([]byte("{}"), "toml"); err == nil {
+		t.Errorf("ParseRuleSet(toml), err = nil")
+	}
+}
+
+func TestLoadValidateChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yml")
+	rules := "stop_on_error: true\nconcurrency: 2\n" + testRulesYAML
+	if err := os.WriteFile(path, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vc, err := LoadValidateChain(path)
+	if err != nil {
+		t.Fatalf("LoadValidateChain, err = %v", err)
+	}
+	if len(vc.Chain) != 4 || !vc.stopOnError || vc.concurrency != 2 {
+		t.Errorf("chain = %+v", vc)
+	}
+	// StopOnError只返回第一个字段的错误
+	r := vc.Validate(map[string]interface{}{"ip": "1.1.1", "mac": "xx"})
+	if f := Failures(r); len(f) != 1 || f[0].Field() != "ip" {
+		t.Errorf("msg = %s", r.Msg())
+	}
+
+	if _, err := LoadValidateChain(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Errorf("LoadValidateChain(missing), err = nil")
+	}
+}
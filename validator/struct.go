@@ -0,0 +1,359 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// 基于struct tag的校验，比如:
+//
+//	type Hop struct {
+//		IP   string `json:"ip" validate:"required,ip"`
+//		Port int    `json:"port" validate:"range=1-65535"`
+//	}
+//
+// 嵌套的struct以及struct的slice会被递归校验，slice字段上除required、omitempty以外的规则作用于每个元素
+
+const TAG_NAME = "validate"
+
+// RuleFunc 校验单个值，param为tag中"="之后的部分，指针已经被解引用
+type RuleFunc func(v reflect.Value, param string) Result
+
+// rulesMu 保护rules，RegisterRule可以在运行中与校验并发调用
+var rulesMu sync.RWMutex
+
+var rules = map[string]RuleFunc{
+	"ip":      stringRule(ipRule("ip", true, true, false)),
+	"ipv4":    stringRule(ipRule("ipv4", true, false, false)),
+	"ipv6":    stringRule(ipRule("ipv6", false, true, false)),
+	"cidr":    stringRule(ipRule("cidr", true, true, true)),
+	"iprange": stringRule(ipRangeRule),
+	"range":   rangeRule,
+}
+
+// RegisterRule 注册自定义规则，与已有规则同名时覆盖，可以并发调用
+func RegisterRule(name string, f RuleFunc) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = f
+}
+
+func lookupRule(name string) (RuleFunc, bool) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	f, ok := rules[name]
+	return f, ok
+}
+
+func stringRule(f func(s string, param string) Result) RuleFunc {
+	return func(v reflect.Value, param string) Result {
+		if v.Kind() != reflect.String {
+			return NewCodeResult("type_string", nil, map[string]interface{}{"type": v.Type().String()})
+		}
+		return f(v.String(), param)
+	}
+}
+
+// ipRule 失败时错误码为code，params中reason为具体的原因。
+// cidr的param为strict时要求主机位全部为0
+func ipRule(code string, v4, v6, withPrefix bool) func(s string, param string) Result {
+	return func(s string, param string) Result {
+		var ip []byte
+		var err error
+		if withPrefix {
+			ip, _, err = ParseCIDR(s, param == "strict")
+		} else {
+			ip, err = ParseIPAddress(s)
+		}
+		if err == nil && ((v4 && len(ip) == IPV4_LEN) || (v6 && len(ip) == IPV6_LEN)) {
+			return NewValidateResult(true, "")
+		}
+		reason := IPReason(err)
+		if reason == "" {
+			reason = "ip_family"
+		}
+		return NewCodeResult(code, s, map[string]interface{}{"reason": reason})
+	}
+}
+
+func ipRangeRule(s string, param string) Result {
+	if IsIPRange(s) {
+		return NewValidateResult(true, "")
+	}
+	return NewCodeResult("iprange", s, nil)
+}
+
+// rangeRule 数值在[min, max]中，param为"min-max"，字符串按照整数解析
+func rangeRule(v reflect.Value, param string) Result {
+	low, high, err := parseRangeParam(param)
+	if err != nil {
+		return NewCodeResult("range_param", nil, map[string]interface{}{"param": param})
+	}
+	params := map[string]interface{}{"min": low, "max": high}
+
+	var n float64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	case reflect.String:
+		i, err := strconv.ParseInt(strings.TrimSpace(v.String()), 10, 64)
+		if err != nil {
+			return NewCodeResult("not_number", v.String(), params)
+		}
+		n = float64(i)
+	default:
+		return NewCodeResult("type_number", nil, map[string]interface{}{"type": v.Type().String()})
+	}
+
+	if n < low || n > high {
+		return NewCodeResult("range", v.Interface(), params)
+	}
+	return NewValidateResult(true, "")
+}
+
+// parseRangeParam 解析"min-max"，min、max可以是负数
+func parseRangeParam(param string) (float64, float64, error) {
+	i := -1
+	if len(param) > 1 {
+		if i = strings.Index(param[1:], "-"); i > -1 {
+			i++
+		}
+	}
+	if i == -1 {
+		return 0, 0, fmt.Errorf("invalid range param: '%s'", param)
+	}
+	low, err := strconv.ParseFloat(param[:i], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range param: '%s'", param)
+	}
+	high, err := strconv.ParseFloat(param[i+1:], 64)
+	if err != nil || low > high {
+		return 0, 0, fmt.Errorf("invalid range param: '%s'", param)
+	}
+	return low, high, nil
+}
+
+// ValidateStruct 按照字段的validate tag校验s，s为struct或者struct的指针
+func ValidateStruct(s interface{}) Result {
+	v := reflect.ValueOf(s)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return NewCodeResult("struct_nil", nil, nil)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return NewCodeResult("type_struct", nil, map[string]interface{}{"type": fmt.Sprintf("%T", s)})
+	}
+
+	result := NewValidateResult(true, "")
+	walkStruct(v, "", result)
+	return result
+}
+
+// ValidateStruct 先按照tag校验s，再将s转换为map交给chain中的Validator校验
+func (vc *ValidateChain) ValidateStruct(s interface{}) Result {
+	return vc.ValidateStructContext(context.Background(), s)
+}
+
+// ValidateStructContext 与ValidateStruct相同，ctx传递给chain中的Validator
+func (vc *ValidateChain) ValidateStructContext(ctx context.Context, s interface{}) Result {
+	result := ValidateStruct(s)
+	if len(vc.Chain) == 0 || (!result.Status() && vc.stopOnError) {
+		return result
+	}
+
+	data := map[string]interface{}{}
+	if err := mapstructure.Decode(s, &data); err != nil {
+		result.AddError(NewValidateResult(false, err.Error()))
+		return result
+	}
+	if r := vc.ValidateContext(ctx, data); !r.Status() {
+		result.AddError(r)
+	}
+	return result
+}
+
+// fieldName 优先使用json tag中的名字
+func fieldName(f reflect.StructField) string {
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return f.Name
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	if name == "" {
+		return path
+	}
+	return path + "." + name
+}
+
+func walkStruct(v reflect.Value, path string, result Result) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get(TAG_NAME)
+		if tag == "-" {
+			continue
+		}
+
+		p := joinPath(path, fieldName(f))
+		if tag != "" {
+			validateField(v.Field(i), p, tag, result)
+		}
+		walkValue(v.Field(i), p, result)
+	}
+}
+
+// walkValue 递归进入嵌套的struct、指针以及slice
+func walkValue(v reflect.Value, path string, result Result) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			walkValue(v.Elem(), path, result)
+		}
+	case reflect.Struct:
+		walkStruct(v, path, result)
+	case reflect.Slice, reflect.Array:
+		if !isList(v) {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			walkValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), result)
+		}
+	}
+}
+
+func isEmpty(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// isList 规则作用于每个元素的字段，[]byte除外
+func isList(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return v.Type().Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// ruleSpec tag中的一条规则，比如range=1-65535
+type ruleSpec struct {
+	name  string
+	param string
+}
+
+func parseRule(rule string) ruleSpec {
+	name, param := rule, ""
+	if i := strings.Index(rule, "="); i > -1 {
+		name, param = rule[:i], rule[i+1:]
+	}
+	return ruleSpec{name: strings.TrimSpace(name), param: param}
+}
+
+func parseTag(tag string) []ruleSpec {
+	specs := []ruleSpec{}
+	for _, rule := range strings.Split(tag, ",") {
+		specs = append(specs, parseRule(rule))
+	}
+	return specs
+}
+
+func validateField(v reflect.Value, path string, tag string, result Result) {
+	validateRules(v, path, parseTag(tag), result)
+}
+
+// validateRules v无效时（比如map中不存在的字段）只处理required
+func validateRules(v reflect.Value, path string, specs []ruleSpec, result Result) {
+	for _, spec := range specs {
+		name, param := spec.name, spec.param
+
+		switch name {
+		case "":
+			continue
+		case "required":
+			if isEmpty(v) {
+				result.AddError(NewFieldError(path, "required", nil, nil, ""))
+				return
+			}
+			continue
+		case "omitempty":
+			if isEmpty(v) {
+				return
+			}
+			continue
+		}
+
+		if !v.IsValid() {
+			return
+		}
+		f, ok := lookupRule(name)
+		if !ok {
+			result.AddError(NewFieldError(path, "unknown_rule", nil, map[string]interface{}{"rule": name}, ""))
+			continue
+		}
+		if isList(v) {
+			for i := 0; i < v.Len(); i++ {
+				applyRule(f, name, v.Index(i), fmt.Sprintf("%s[%d]", path, i), param, result)
+			}
+		} else {
+			applyRule(f, name, v, path, param, result)
+		}
+	}
+}
+
+// applyRule 规则返回的结果中没有错误码时使用规则的名字，没有参数时使用tag中的参数。
+// 由错误码生成消息的结果不保存消息，切换语言后Msg随之变化
+func applyRule(f RuleFunc, name string, v reflect.Value, path string, param string, result Result) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		// 空指针交给required处理
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	r := f(v, param)
+	if r.Status() {
+		return
+	}
+	code, params := r.Code(), r.Params()
+	if code == "" {
+		code = name
+	}
+	if params == nil && param != "" {
+		params = map[string]interface{}{"param": param}
+	}
+	var value interface{}
+	if v.CanInterface() {
+		value = v.Interface()
+	}
+	msg := r.Msg()
+	if vr, ok := r.(*validateResult); ok && vr.msg == "" && vr.code != "" {
+		msg = ""
+	}
+	result.AddError(NewFieldError(path, code, value, params, msg))
+}
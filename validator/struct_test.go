@@ -0,0 +1,150 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testHop struct {
+	IP   string `json:"ip" validate:"required,ip"`
+	Port int    `json:"port" validate:"range=1-65535"`
+}
+
+type testRoute struct {
+	Name    string    `json:"name" validate:"required"`
+	Prefix  string    `json:"prefix" validate:"cidr"`
+	Source  string    `json:"source" validate:"omitempty,ipv4"`
+	Range   *string   `json:"range" validate:"omitempty,iprange"`
+	Vlans   []string  `json:"vlans" validate:"range=1-4094"`
+	Hops    []testHop `json:"hops" validate:"required"`
+	Ignored string    `validate:"-"`
+	Metric  uint8     `validate:"range=0-16"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	r := "10.0.0.1-10.0.0.2"
+	bad := "10.0.0.9-10.0.0.2"
+	testCases := []map[string]interface{}{
+		{
+			"data": testRoute{Name: "r1", Prefix: "10.0.0.0/8", Vlans: []string{"1", "4094"}, Range: &r,
+				Hops: []testHop{{IP: "1.1.1.1", Port: 80}}},
+			"want": []string{},
+		},
+		{
+			"data": &testRoute{Name: "r1", Prefix: "2001:db8::/32", Source: "1.1.1.1",
+				Hops: []testHop{{IP: "2001:db8::1", Port: 1}}, Ignored: "xxx"},
+			"want": []string{},
+		},
+		{
+			"data": testRoute{Prefix: "10.0.0.0/33", Source: "::1", Range: &bad, Vlans: []string{"0", "abc"},
+				Hops: []testHop{{IP: "1.1.1.1", Port: 80}, {IP: "1.1.1", Port: 0}, {Port: 22}}, Metric: 17},
+			"want": []string{
+				"name: field is required",
+				"prefix: ip address valide error, ip:10.0.0.0/33",
+				"source: ip address valide error, ip:::1",
+				"range: ip range valide error",
+				"vlans[0]: 0 out of range 1-4094",
+				"vlans[1]: not a number: abc",
+				"hops[1].ip: ip address valide error, ip:1.1.1",
+				"hops[1].port: 0 out of range 1-65535",
+				"hops[2].ip: field is required",
+				"Metric: 17 out of range 0-16",
+			},
+		},
+		{
+			"data": testRoute{Name: "r1", Prefix: "10.0.0.0/8"},
+			"want": []string{"hops: field is required"},
+		},
+	}
+
+	for _, tc := range testCases {
+		result := ValidateStruct(tc["data"])
+		want := tc["want"].([]string)
+		if result.Status() != (len(want) == 0) {
+			t.Errorf("ValidateStruct(%+v), status = %v, msg = %s", tc["data"], result.Status(), result.Msg())
+			continue
+		}
+		msg := result.Msg()
+		for _, w := range want {
+			if !strings.Contains(msg, w) {
+				t.Errorf("ValidateStruct(%+v), msg = %s, want = %s", tc["data"], msg, w)
+			}
+		}
+		if n := len(strings.Split(msg, "\n")); len(want) > 0 && n != len(want) {
+			t.Errorf("ValidateStruct(%+v), %d errors, want %d: %s", tc["data"], n, len(want), msg)
+		}
+	}
+
+	if ValidateStruct(nil).Status() || ValidateStruct("abc").Status() || ValidateStruct((*testRoute)(nil)).Status() {
+		t.Errorf("ValidateStruct on non struct, want false")
+	}
+
+	type unknown struct {
+		A string `validate:"not-exist"`
+	}
+	if r := ValidateStruct(unknown{}); r.Status() || !strings.Contains(r.Msg(), "unknown validate rule") {
+		t.Errorf("unknown rule, msg = %s", r.Msg())
+	}
+}
+
+type nameValidator struct{}
+
+func (nameValidator) Validate(data map[string]interface{}) Result {
+	if data["Name"] == "bad" {
+		return NewValidateResult(false, "name is bad")
+	}
+	return NewValidateResult(true, "")
+}
+
+func TestValidateChainStruct(t *testing.T) {
+	vc := NewValidateChain()
+	vc.Add(nameValidator{})
+
+	ok := testRoute{Name: "r1", Prefix: "10.0.0.0/8", Hops: []testHop{{IP: "1.1.1.1", Port: 80}}}
+	if r := vc.ValidateStruct(ok); !r.Status() {
+		t.Errorf("ValidateStruct(%+v), msg = %s", ok, r.Msg())
+	}
+
+	ok.Name = "bad"
+	ok.Prefix = ""
+	r := vc.ValidateStruct(ok)
+	if r.Status() || !strings.Contains(r.Msg(), "name is bad") || !strings.Contains(r.Msg(), "prefix:") {
+		t.Errorf("ValidateStruct(%+v), msg = %s", ok, r.Msg())
+	}
+}
+
+// 与校验并发注册规则，需要配合-race运行
+func TestRegisterRuleConcurrent(t *testing.T) {
+	type data struct {
+		Name string `json:"name" validate:"test_even"`
+	}
+	even := func(v reflect.Value, param string) Result {
+		return NewValidateResult(len(v.String())%2 == 0, "odd")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			RegisterRule(fmt.Sprintf("test_rule_%d", i), even)
+			RegisterRule("test_even", even)
+		}(i)
+		go func() {
+			defer wg.Done()
+			ValidateStruct(data{Name: "ab"})
+			(&RuleSet{Fields: []FieldRules{{Field: "name", Rules: []RuleDef{{Rule: "ip"}}}}}).Compile()
+		}()
+	}
+	wg.Wait()
+
+	if result := ValidateStruct(data{Name: "ab"}); !result.Status() {
+		t.Errorf("ValidateStruct, msg = %s", result.Msg())
+	}
+	if result := ValidateStruct(data{Name: "abc"}); result.Status() {
+		t.Errorf("ValidateStruct(abc), status = true")
+	}
+}
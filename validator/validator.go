@@ -1,5 +1,9 @@
 package validator
 
+import (
+	"context"
+)
+
 type Validator interface {
 	Validate(data map[string]interface{}) Result
 }
@@ -7,12 +11,14 @@ type Validator interface {
 type ValidateChain struct {
 	Chain       []Validator
 	stopOnError bool
+	concurrency int
 }
 
 func NewValidateChain() *ValidateChain {
 	return &ValidateChain{
 		Chain:       []Validator{},
 		stopOnError: false,
+		concurrency: 1,
 	}
 }
 
@@ -20,15 +26,42 @@ func (vc *ValidateChain) Add(validator Validator) {
 	vc.Chain = append(vc.Chain, validator)
 }
 
+// AddContext 添加ContextValidator
+func (vc *ValidateChain) AddContext(validator ContextValidator) {
+	vc.Add(WithContext(validator))
+}
+
+// StopOnError 不改变Validate的行为，仍然执行全部Validator并返回全部错误
 func (vc *ValidateChain) StopOnError() *ValidateChain {
 	vc.stopOnError = false
 	return vc
 }
 
+// Concurrency 最多同时执行n个Validator，默认为1，即按照顺序依次执行。
+// 并发执行时各个Validator之间不能有依赖，也不能修改data
+func (vc *ValidateChain) Concurrency(n int) *ValidateChain {
+	vc.concurrency = n
+	return vc
+}
+
 func (vc *ValidateChain) Validate(data map[string]interface{}) Result {
+	return vc.ValidateContext(context.Background(), data)
+}
+
+// ValidateContext ctx结束时不再等待还没有返回的ContextValidator，也不再启动剩余的Validator，
+// 顺序上第一个没有返回或者没有执行的Validator的结果为canceled或者deadline_exceeded，之后的Validator不再出现在结果中。
+// 顺序执行时没有实现ContextValidator的Validator在当前goroutine中执行，ctx结束后仍然要等它返回。
+// 无论是否并发，错误都按照Validator在Chain中的顺序排列
+func (vc *ValidateChain) ValidateContext(ctx context.Context, data map[string]interface{}) Result {
+	var results []Result
+	if vc.concurrency > 1 && len(vc.Chain) > 1 {
+		results = vc.validateConcurrent(ctx, data)
+	} else {
+		results = vc.validateSequential(ctx, data)
+	}
+
 	errors := []Result{}
-	for _, v := range vc.Chain {
-		result := v.Validate(data)
+	for _, result := range results {
 		if result.Status() == false {
 			if vc.stopOnError == true {
 				return result
@@ -50,3 +83,54 @@ func (vc *ValidateChain) Validate(data map[string]interface{}) Result {
 
 	}
 }
+
+func (vc *ValidateChain) validateSequential(ctx context.Context, data map[string]interface{}) []Result {
+	results := make([]Result, 0, len(vc.Chain))
+	for _, v := range vc.Chain {
+		result, done := run(ctx, v, data)
+		results = append(results, result)
+		if !done || (result.Status() == false && vc.stopOnError == true) {
+			break
+		}
+	}
+	return results
+}
+
+// validateConcurrent 按照顺序收集结果，stopOnError时遇到第一个错误就取消其余的Validator
+func (vc *ValidateChain) validateConcurrent(ctx context.Context, data map[string]interface{}) []Result {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chs := make([]chan Result, len(vc.Chain))
+	for i := range chs {
+		chs[i] = make(chan Result, 1)
+	}
+	sem := make(chan struct{}, vc.concurrency)
+	go func() {
+		for i, v := range vc.Chain {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			// 两个case同时满足时select随机选择，ctx结束后不再启动
+			if ctx.Err() != nil {
+				return
+			}
+			go func(ch chan<- Result, v Validator) {
+				defer func() { <-sem }()
+				ch <- ValidateContext(ctx, v, data)
+			}(chs[i], v)
+		}
+	}()
+
+	results := make([]Result, 0, len(vc.Chain))
+	for i, v := range vc.Chain {
+		result, done := wait(ctx, v, chs[i])
+		results = append(results, result)
+		if !done || (result.Status() == false && vc.stopOnError == true) {
+			break
+		}
+	}
+	return results
+}
@@ -0,0 +1,36 @@
+package validator
+
+import "testing"
+
+func TestStopOnError(t *testing.T) {
+	testCases := []map[string]interface{}{
+		{"stop": false, "called": 3, "errors": 2},
+		// StopOnError()不改变行为，仍然执行全部Validator
+		{"stop": true, "called": 3, "errors": 2},
+	}
+
+	for _, tc := range testCases {
+		called := 0
+		fail := ValidatorFunc(func(data map[string]interface{}) Result {
+			called++
+			return NewValidateResult(false, "failed")
+		})
+		pass := ValidatorFunc(func(data map[string]interface{}) Result {
+			called++
+			return NewValidateResult(true, "")
+		})
+
+		vc := NewValidateChain()
+		if tc["stop"].(bool) {
+			vc.StopOnError()
+		}
+		vc.Add(fail)
+		vc.Add(pass)
+		vc.Add(fail)
+
+		r := vc.Validate(map[string]interface{}{})
+		if r.Status() || called != tc["called"] || len(Failures(r)) != tc["errors"] {
+			t.Errorf("stop = %v, status = %v, called = %d, errors = %d", tc["stop"], r.Status(), called, len(Failures(r)))
+		}
+	}
+}